@@ -2,77 +2,399 @@ package config
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/spf13/viper"
 )
 
+// DatabaseConfig holds the database connection settings. Driver picks the database:
+// mysql, postgres or sqlite; for sqlite DBName is the path of the database file (or
+// :memory:) and the other connection fields are ignored. SSLMode is only used by
+// postgres. With Migrate set, the pending migrations of the driver are applied at startup.
 type DatabaseConfig struct {
+	Driver   string `mapstructure:"driver"`
 	Host     string `mapstructure:"host"`
 	Port     int    `mapstructure:"port"`
 	Username string `mapstructure:"username"`
 	Password string `mapstructure:"password"`
 	DBName   string `mapstructure:"dbname"`
+	SSLMode  string `mapstructure:"sslmode"`
+	Migrate  bool   `mapstructure:"migrate"`
 }
 
 type Config struct {
-	Database DatabaseConfig `mapstructure:"database"`
-	RabbitMQ RabbitMQConfig `mapstructure:"rabbitmq"`
-	MinIO    MinIOConfig    `mapstructure:"minio"`
+	Database      DatabaseConfig     `mapstructure:"database"`
+	RabbitMQ      RabbitMQConfig     `mapstructure:"rabbitmq"`
+	MinIO         MinIOConfig        `mapstructure:"minio"`
+	Storage       StorageConfig      `mapstructure:"storage"`
+	Artifacts     ArtifactConfig     `mapstructure:"artifacts"`
+	Retention     RetentionConfig    `mapstructure:"retention"`
+	Notifications NotificationConfig `mapstructure:"notifications"`
+	Log           LogConfig          `mapstructure:"log"`
+	Tracing       TracingConfig      `mapstructure:"tracing"`
+	Health        HealthConfig       `mapstructure:"health"`
+	Outbox        OutboxConfig       `mapstructure:"outbox"`
+	Dispatch      DispatchConfig     `mapstructure:"dispatch"`
+	Runners       RunnerConfig       `mapstructure:"runners"`
+	Server        ServerConfig       `mapstructure:"server"`
+}
+
+// ServerConfig holds the HTTP server settings. On SIGTERM or SIGINT the server stops
+// accepting requests and waits up to ShutdownTimeout for the requests in progress and
+// the background workers to finish before closing its connections.
+type ServerConfig struct {
+	ShutdownTimeout time.Duration `mapstructure:"shutdown_timeout"`
+}
+
+// RunnerConfig holds the settings of the runner node pools of tbl_runner_nodes and of
+// the calls to them. Strategy picks the node of a new run: least_busy (fewest runs in
+// progress for its weight) or round_robin (weighted). A node that fails is skipped for
+// FailureCooldown. Each call to a node is cut off after RunTimeout for starting a run
+// and after RequestTimeout otherwise; failed GET calls are retried GetRetries times,
+// RetryBackoff apart and doubling. After BreakerThreshold failed calls in a row (0 to
+// disable) the runners of a project are not called for BreakerCooldown.
+type RunnerConfig struct {
+	Strategy         string        `mapstructure:"strategy"`
+	FailureCooldown  time.Duration `mapstructure:"failure_cooldown"`
+	RunTimeout       time.Duration `mapstructure:"run_timeout"`
+	RequestTimeout   time.Duration `mapstructure:"request_timeout"`
+	GetRetries       int           `mapstructure:"get_retries"`
+	RetryBackoff     time.Duration `mapstructure:"retry_backoff"`
+	BreakerThreshold int           `mapstructure:"breaker_threshold"`
+	BreakerCooldown  time.Duration `mapstructure:"breaker_cooldown"`
+}
+
+// DispatchConfig holds the settings of the dispatcher starting queued runs once their
+// runner has room. Every PollInterval it considers up to BatchSize queued runs, most
+// urgent priority first; MaxActivePerRequester (0 for no limit) caps the runs one
+// requester may have running on a project's runner at once. Consumers of the RabbitMQ
+// messages may start queued runs as well, through /automation/retry: every start
+// claims the run first, so a run is only started once.
+type DispatchConfig struct {
+	Enabled               bool          `mapstructure:"enabled"`
+	PollInterval          time.Duration `mapstructure:"poll_interval"`
+	BatchSize             int           `mapstructure:"batch_size"`
+	MaxActivePerRequester int           `mapstructure:"max_active_per_requester"`
+}
+
+// OutboxConfig holds the settings of the relay publishing queued-run messages from
+// tbl_outbox_messages. A failed publish is retried after BackoffBase, doubling up to
+// BackoffMax, for as long as it takes.
+type OutboxConfig struct {
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	BatchSize    int           `mapstructure:"batch_size"`
+	BackoffBase  time.Duration `mapstructure:"backoff_base"`
+	BackoffMax   time.Duration `mapstructure:"backoff_max"`
+}
+
+// HealthConfig holds the readiness check settings. CheckRunners adds a
+// non-critical reachability check of every project runner.
+type HealthConfig struct {
+	Timeout      time.Duration `mapstructure:"timeout"`
+	CheckRunners bool          `mapstructure:"check_runners"`
+}
+
+// TracingConfig holds the OpenTelemetry settings. Exporter is otlp, stdout or none;
+// Endpoint is the host:port of the OTLP/HTTP collector, defaulting to
+// OTEL_EXPORTER_OTLP_ENDPOINT or localhost:4318.
+type TracingConfig struct {
+	Exporter    string  `mapstructure:"exporter"`
+	Endpoint    string  `mapstructure:"endpoint"`
+	Insecure    bool    `mapstructure:"insecure"`
+	ServiceName string  `mapstructure:"service_name"`
+	SampleRatio float64 `mapstructure:"sample_ratio"`
+}
+
+// LogConfig holds the logging settings: Level is debug, info, warn or error and
+// Format is json or text.
+type LogConfig struct {
+	Level  string `mapstructure:"level"`
+	Format string `mapstructure:"format"`
+}
+
+// NotificationConfig holds the channels used to report finished runs.
+type NotificationConfig struct {
+	Email    EmailConfig   `mapstructure:"email"`
+	Webhooks WebhookConfig `mapstructure:"webhooks"`
+	Chat     ChatConfig    `mapstructure:"chat"`
+}
+
+// ChatConfig holds the settings of Slack and Microsoft Teams messages. The channels
+// themselves are configured per project in tbl_chat_notifications.
+type ChatConfig struct {
+	Enabled bool          `mapstructure:"enabled"`
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// WebhookConfig holds the delivery settings of outgoing webhooks. A failed delivery is
+// retried after BackoffBase, doubling up to BackoffMax, until MaxAttempts is reached.
+// Webhooks are only delivered to public addresses, and to the internal networks listed
+// as CIDRs in AllowedNetworks.
+type WebhookConfig struct {
+	Enabled         bool          `mapstructure:"enabled"`
+	PollInterval    time.Duration `mapstructure:"poll_interval"`
+	Timeout         time.Duration `mapstructure:"timeout"`
+	MaxAttempts     int           `mapstructure:"max_attempts"`
+	BackoffBase     time.Duration `mapstructure:"backoff_base"`
+	BackoffMax      time.Duration `mapstructure:"backoff_max"`
+	AllowedNetworks []string      `mapstructure:"allowed_networks"`
+}
+
+// EmailConfig holds the SMTP settings of run summary emails, sent to the email given
+// when the run was requested. TemplateDir optionally replaces the built-in templates.
+type EmailConfig struct {
+	Enabled     bool   `mapstructure:"enabled"`
+	Host        string `mapstructure:"host"`
+	Port        int    `mapstructure:"port"`
+	Username    string `mapstructure:"username"`
+	Password    string `mapstructure:"password"`
+	From        string `mapstructure:"from"`
+	TemplateDir string `mapstructure:"template_dir"`
+}
+
+// RetentionConfig holds the artifact janitor settings and the retention policy applied
+// to projects that have no policy of their own in tbl_retention_policies.
+type RetentionConfig struct {
+	Enabled          bool          `mapstructure:"enabled"`
+	Interval         time.Duration `mapstructure:"interval"`
+	KeepLastRuns     int           `mapstructure:"keep_last_runs"`
+	MaxAgeDays       int           `mapstructure:"max_age_days"`
+	FailedMaxAgeDays int           `mapstructure:"failed_max_age_days"`
+}
+
+// StorageConfig selects the artifact storage backend: "minio" (default) or "local".
+type StorageConfig struct {
+	Driver string             `mapstructure:"driver"`
+	Local  LocalStorageConfig `mapstructure:"local"`
+}
+
+// LocalStorageConfig holds the configuration of the local filesystem storage backend.
+// Signed URLs point at BaseURL, the externally reachable address of this service, and
+// are signed with SigningKey; the change-me placeholder of config.json is refused.
+type LocalStorageConfig struct {
+	Path       string        `mapstructure:"path"`
+	BaseURL    string        `mapstructure:"base_url"`
+	SigningKey string        `mapstructure:"signing_key"`
+	URLExpiry  time.Duration `mapstructure:"url_expiry"`
+}
+
+// ArtifactConfig holds the limits applied to uploaded run artifacts.
+// MaxSizes maps an artifact type (pdf, junit, html, screenshot, video, log) to its size limit in bytes.
+// Chunked uploads are staged in UploadDir until completed, or removed after UploadExpiry
+// by a sweep every UploadSweepInterval.
+type ArtifactConfig struct {
+	MaxSizes            map[string]int64 `mapstructure:"max_sizes"`
+	UploadDir           string           `mapstructure:"upload_dir"`
+	MaxChunkSize        int64            `mapstructure:"max_chunk_size"`
+	UploadExpiry        time.Duration    `mapstructure:"upload_expiry"`
+	UploadSweepInterval time.Duration    `mapstructure:"upload_sweep_interval"`
 }
 
 // MinIOConfig holds the MinIO specific configuration
 type MinIOConfig struct {
-	Endpoint   string `mapstructure:"endpoint"`
-	Username   string `mapstructure:"username"`
-	Password   string `mapstructure:"password"`
-	UseSSL     bool   `mapstructure:"usessl"`
-	BucketName string `mapstructure:"bucket"`
+	Endpoint   string        `mapstructure:"endpoint"`
+	Username   string        `mapstructure:"username"`
+	Password   string        `mapstructure:"password"`
+	UseSSL     bool          `mapstructure:"usessl"`
+	BucketName string        `mapstructure:"bucket"`
+	URLExpiry  time.Duration `mapstructure:"url_expiry"` // lifetime of pre-signed download URLs
 }
 
-// RabbitMQConfig holds the RabbitMQ specific configuration.
+// RabbitMQConfig holds the RabbitMQ specific configuration. PublishTimeout bounds how long a publish waits for the broker to confirm the message,
+// including the time spent reconnecting; at most BufferSize messages wait at once.
+// Runs are routed through a topic exchange to one durable queue per project, named QueuePrefix.<project>, of type QueueType.
+// Messages older than MessageTTL (0 keeps them forever) or delivered more than DeliveryLimit times (quorum queues only)
+// go to DeadLetterExchange and its DeadLetterQueue, which default to <exchange>.dlx and <exchange>.dead.
 type RabbitMQConfig struct {
-	Host         string `mapstructure:"host"`
-	Port         int    `mapstructure:"port"`
-	Username     string `mapstructure:"username"`
-	Password     string `mapstructure:"password"`
-	ExchangeName string `mapstructure:"exchange"`
+	Host               string        `mapstructure:"host"`
+	Port               int           `mapstructure:"port"`
+	Username           string        `mapstructure:"username"`
+	Password           string        `mapstructure:"password"`
+	ExchangeName       string        `mapstructure:"exchange"`
+	PublishTimeout     time.Duration `mapstructure:"publish_timeout"`
+	BufferSize         int           `mapstructure:"buffer_size"`
+	ReconnectDelay     time.Duration `mapstructure:"reconnect_delay"`
+	ReconnectMaxDelay  time.Duration `mapstructure:"reconnect_max_delay"`
+	QueuePrefix        string        `mapstructure:"queue_prefix"`
+	QueueType          string        `mapstructure:"queue_type"`
+	MessageTTL         time.Duration `mapstructure:"message_ttl"`
+	DeliveryLimit      int           `mapstructure:"delivery_limit"`
+	DeadLetterExchange string        `mapstructure:"dead_letter_exchange"`
+	DeadLetterQueue    string        `mapstructure:"dead_letter_queue"`
 }
 
 // AMQPURL builds the AMQP URL from the individual RabbitMQ configuration fields.
 func (r *RabbitMQConfig) AMQPURL() string {
-	fmt.Println("r", r)
 	return fmt.Sprintf("amqp://%s:%s@%s:%d/", r.Username, r.Password, r.Host, r.Port)
 }
 
 func LoadConfig() (*Config, error) {
+	viper.SetDefault("database.driver", "mysql")
+	viper.SetDefault("database.sslmode", "disable")
+	viper.SetDefault("database.migrate", false)
+	// Default artifact size limits, overridable from config.json.
+	viper.SetDefault("artifacts.max_sizes", map[string]int64{
+		"pdf":        20 << 20,
+		"junit":      10 << 20,
+		"html":       50 << 20,
+		"screenshot": 10 << 20,
+		"video":      200 << 20,
+		"log":        20 << 20,
+	})
+	viper.SetDefault("artifacts.upload_dir", "./data/uploads")
+	viper.SetDefault("artifacts.max_chunk_size", 8<<20)
+	viper.SetDefault("artifacts.upload_expiry", 24*time.Hour)
+	viper.SetDefault("artifacts.upload_sweep_interval", 10*time.Minute)
+	viper.SetDefault("minio.url_expiry", time.Hour)
+	viper.SetDefault("storage.driver", "minio")
+	viper.SetDefault("storage.local.path", "./data/artifacts")
+	viper.SetDefault("storage.local.base_url", "http://localhost:6000")
+	viper.SetDefault("storage.local.url_expiry", time.Hour)
+	viper.SetDefault("retention.enabled", false)
+	viper.SetDefault("retention.interval", time.Hour)
+	viper.SetDefault("notifications.email.enabled", false)
+	viper.SetDefault("notifications.email.host", "localhost")
+	viper.SetDefault("notifications.email.port", 1025)
+	viper.SetDefault("notifications.email.from", "test-runner@localhost")
+	viper.SetDefault("notifications.webhooks.enabled", true)
+	viper.SetDefault("notifications.webhooks.poll_interval", 5*time.Second)
+	viper.SetDefault("notifications.webhooks.timeout", 10*time.Second)
+	viper.SetDefault("notifications.webhooks.max_attempts", 8)
+	viper.SetDefault("notifications.webhooks.backoff_base", 30*time.Second)
+	viper.SetDefault("notifications.webhooks.backoff_max", time.Hour)
+	viper.SetDefault("notifications.webhooks.allowed_networks", []string{})
+	viper.SetDefault("notifications.chat.enabled", true)
+	viper.SetDefault("notifications.chat.timeout", 10*time.Second)
+	viper.SetDefault("rabbitmq.publish_timeout", 10*time.Second)
+	viper.SetDefault("rabbitmq.buffer_size", 1000)
+	viper.SetDefault("rabbitmq.reconnect_delay", time.Second)
+	viper.SetDefault("rabbitmq.reconnect_max_delay", 30*time.Second)
+	viper.SetDefault("rabbitmq.queue_prefix", "runs")
+	viper.SetDefault("rabbitmq.queue_type", "quorum")
+	viper.SetDefault("rabbitmq.message_ttl", time.Duration(0))
+	viper.SetDefault("rabbitmq.delivery_limit", 5)
+	viper.SetDefault("outbox.poll_interval", time.Second)
+	viper.SetDefault("outbox.batch_size", 100)
+	viper.SetDefault("outbox.backoff_base", time.Second)
+	viper.SetDefault("outbox.backoff_max", time.Minute)
+	viper.SetDefault("dispatch.enabled", true)
+	viper.SetDefault("dispatch.poll_interval", 5*time.Second)
+	viper.SetDefault("dispatch.batch_size", 200)
+	viper.SetDefault("dispatch.max_active_per_requester", 0)
+	viper.SetDefault("runners.strategy", "least_busy")
+	viper.SetDefault("runners.failure_cooldown", 30*time.Second)
+	viper.SetDefault("runners.run_timeout", 30*time.Second)
+	viper.SetDefault("runners.request_timeout", 10*time.Second)
+	viper.SetDefault("runners.get_retries", 2)
+	viper.SetDefault("runners.retry_backoff", 500*time.Millisecond)
+	viper.SetDefault("runners.breaker_threshold", 5)
+	viper.SetDefault("runners.breaker_cooldown", 30*time.Second)
+	viper.SetDefault("server.shutdown_timeout", 30*time.Second)
+	viper.SetDefault("log.level", "info")
+	viper.SetDefault("log.format", "json")
+	viper.SetDefault("tracing.exporter", "none")
+	viper.SetDefault("tracing.service_name", "service-test-runner")
+	viper.SetDefault("tracing.sample_ratio", 1.0)
+	viper.SetDefault("health.timeout", 2*time.Second)
+	viper.SetDefault("health.check_runners", false)
+
 	// Check if the .env file exists.
 	if _, err := os.Stat(".env"); err == nil {
-		fmt.Println("loading env")
 		// .env exists; load it.
 		if err := godotenv.Load(); err != nil {
-			log.Printf("Error loading .env file: %v", err)
+			slog.Warn("Error loading .env file", "error", err)
 		}
 		// Bind environment variables to our config keys.
 		viper.AutomaticEnv()
+		viper.BindEnv("database.driver", "DATABASE_DRIVER")
 		viper.BindEnv("database.host", "DATABASE_HOST")
 		viper.BindEnv("database.port", "DATABASE_PORT")
 		viper.BindEnv("database.username", "DATABASE_USERNAME")
 		viper.BindEnv("database.password", "DATABASE_PASSWORD")
 		viper.BindEnv("database.dbname", "DATABASE_DBNAME")
+		viper.BindEnv("database.sslmode", "DATABASE_SSLMODE")
+		viper.BindEnv("database.migrate", "DATABASE_MIGRATE")
 		viper.BindEnv("rabbitmq.host", "RABBITMQ_HOST")
 		viper.BindEnv("rabbitmq.port", "RABBITMQ_PORT")
 		viper.BindEnv("rabbitmq.username", "RABBITMQ_USERNAME")
 		viper.BindEnv("rabbitmq.password", "RABBITMQ_PASSWORD")
 		viper.BindEnv("rabbitmq.exchange", "RABBITMQ_EXCHANGE_NAME")
+		viper.BindEnv("rabbitmq.publish_timeout", "RABBITMQ_PUBLISH_TIMEOUT")
+		viper.BindEnv("rabbitmq.buffer_size", "RABBITMQ_BUFFER_SIZE")
+		viper.BindEnv("rabbitmq.reconnect_delay", "RABBITMQ_RECONNECT_DELAY")
+		viper.BindEnv("rabbitmq.reconnect_max_delay", "RABBITMQ_RECONNECT_MAX_DELAY")
+		viper.BindEnv("rabbitmq.queue_prefix", "RABBITMQ_QUEUE_PREFIX")
+		viper.BindEnv("rabbitmq.queue_type", "RABBITMQ_QUEUE_TYPE")
+		viper.BindEnv("rabbitmq.message_ttl", "RABBITMQ_MESSAGE_TTL")
+		viper.BindEnv("rabbitmq.delivery_limit", "RABBITMQ_DELIVERY_LIMIT")
+		viper.BindEnv("rabbitmq.dead_letter_exchange", "RABBITMQ_DEAD_LETTER_EXCHANGE")
+		viper.BindEnv("rabbitmq.dead_letter_queue", "RABBITMQ_DEAD_LETTER_QUEUE")
 		viper.BindEnv("minio.endpoint", "MINIO_ENDPOINT")
 		viper.BindEnv("minio.username", "MINIO_USERNAME")
 		viper.BindEnv("minio.password", "MINIO_PASSWORD")
 		viper.BindEnv("minio.usessl", "MINIO_USE_SSL")
 		viper.BindEnv("minio.bucket", "MINIO_BUCKET")
+		viper.BindEnv("minio.url_expiry", "MINIO_URL_EXPIRY")
+		viper.BindEnv("storage.driver", "STORAGE_DRIVER")
+		viper.BindEnv("storage.local.path", "STORAGE_LOCAL_PATH")
+		viper.BindEnv("storage.local.base_url", "STORAGE_LOCAL_BASE_URL")
+		viper.BindEnv("storage.local.signing_key", "STORAGE_LOCAL_SIGNING_KEY")
+		viper.BindEnv("storage.local.url_expiry", "STORAGE_LOCAL_URL_EXPIRY")
+		viper.BindEnv("artifacts.upload_dir", "ARTIFACTS_UPLOAD_DIR")
+		viper.BindEnv("artifacts.max_chunk_size", "ARTIFACTS_MAX_CHUNK_SIZE")
+		viper.BindEnv("artifacts.upload_expiry", "ARTIFACTS_UPLOAD_EXPIRY")
+		viper.BindEnv("artifacts.upload_sweep_interval", "ARTIFACTS_UPLOAD_SWEEP_INTERVAL")
+		viper.BindEnv("retention.enabled", "RETENTION_ENABLED")
+		viper.BindEnv("retention.interval", "RETENTION_INTERVAL")
+		viper.BindEnv("retention.keep_last_runs", "RETENTION_KEEP_LAST_RUNS")
+		viper.BindEnv("retention.max_age_days", "RETENTION_MAX_AGE_DAYS")
+		viper.BindEnv("retention.failed_max_age_days", "RETENTION_FAILED_MAX_AGE_DAYS")
+		viper.BindEnv("notifications.email.enabled", "SMTP_ENABLED")
+		viper.BindEnv("notifications.email.host", "SMTP_HOST")
+		viper.BindEnv("notifications.email.port", "SMTP_PORT")
+		viper.BindEnv("notifications.email.username", "SMTP_USERNAME")
+		viper.BindEnv("notifications.email.password", "SMTP_PASSWORD")
+		viper.BindEnv("notifications.email.from", "SMTP_FROM")
+		viper.BindEnv("notifications.email.template_dir", "SMTP_TEMPLATE_DIR")
+		viper.BindEnv("notifications.webhooks.enabled", "WEBHOOKS_ENABLED")
+		viper.BindEnv("notifications.webhooks.poll_interval", "WEBHOOKS_POLL_INTERVAL")
+		viper.BindEnv("notifications.webhooks.timeout", "WEBHOOKS_TIMEOUT")
+		viper.BindEnv("notifications.webhooks.max_attempts", "WEBHOOKS_MAX_ATTEMPTS")
+		viper.BindEnv("notifications.webhooks.backoff_base", "WEBHOOKS_BACKOFF_BASE")
+		viper.BindEnv("notifications.webhooks.backoff_max", "WEBHOOKS_BACKOFF_MAX")
+		viper.BindEnv("notifications.webhooks.allowed_networks", "WEBHOOKS_ALLOWED_NETWORKS")
+		viper.BindEnv("notifications.chat.enabled", "CHAT_NOTIFICATIONS_ENABLED")
+		viper.BindEnv("notifications.chat.timeout", "CHAT_NOTIFICATIONS_TIMEOUT")
+		viper.BindEnv("outbox.poll_interval", "OUTBOX_POLL_INTERVAL")
+		viper.BindEnv("outbox.batch_size", "OUTBOX_BATCH_SIZE")
+		viper.BindEnv("outbox.backoff_base", "OUTBOX_BACKOFF_BASE")
+		viper.BindEnv("outbox.backoff_max", "OUTBOX_BACKOFF_MAX")
+		viper.BindEnv("dispatch.enabled", "DISPATCH_ENABLED")
+		viper.BindEnv("dispatch.poll_interval", "DISPATCH_POLL_INTERVAL")
+		viper.BindEnv("dispatch.batch_size", "DISPATCH_BATCH_SIZE")
+		viper.BindEnv("dispatch.max_active_per_requester", "DISPATCH_MAX_ACTIVE_PER_REQUESTER")
+		viper.BindEnv("runners.strategy", "RUNNERS_STRATEGY")
+		viper.BindEnv("runners.failure_cooldown", "RUNNERS_FAILURE_COOLDOWN")
+		viper.BindEnv("runners.run_timeout", "RUNNERS_RUN_TIMEOUT")
+		viper.BindEnv("runners.request_timeout", "RUNNERS_REQUEST_TIMEOUT")
+		viper.BindEnv("runners.get_retries", "RUNNERS_GET_RETRIES")
+		viper.BindEnv("runners.retry_backoff", "RUNNERS_RETRY_BACKOFF")
+		viper.BindEnv("runners.breaker_threshold", "RUNNERS_BREAKER_THRESHOLD")
+		viper.BindEnv("runners.breaker_cooldown", "RUNNERS_BREAKER_COOLDOWN")
+		viper.BindEnv("server.shutdown_timeout", "SERVER_SHUTDOWN_TIMEOUT")
+		viper.BindEnv("log.level", "LOG_LEVEL")
+		viper.BindEnv("log.format", "LOG_FORMAT")
+		viper.BindEnv("tracing.exporter", "TRACING_EXPORTER")
+		viper.BindEnv("tracing.endpoint", "TRACING_ENDPOINT")
+		viper.BindEnv("tracing.insecure", "TRACING_INSECURE")
+		viper.BindEnv("tracing.service_name", "TRACING_SERVICE_NAME")
+		viper.BindEnv("tracing.sample_ratio", "TRACING_SAMPLE_RATIO")
+		viper.BindEnv("health.timeout", "HEALTH_TIMEOUT")
+		viper.BindEnv("health.check_runners", "HEALTH_CHECK_RUNNERS")
 		//Since environment variables are strings, we might need to convert port.
 		if portStr := os.Getenv("DATABASE_PORT"); portStr != "" {
 			if port, err := strconv.Atoi(portStr); err == nil {
@@ -85,16 +407,15 @@ func LoadConfig() (*Config, error) {
 		viper.SetConfigType("json")
 		viper.AddConfigPath(".")
 		if err := viper.ReadInConfig(); err != nil {
-			log.Printf("Error reading config.json: %v", err)
+			slog.Error("Error reading config.json", "error", err)
 			return nil, err
 		}
 	}
 
 	var cfg Config
 	if err := viper.Unmarshal(&cfg); err != nil {
-		log.Printf("Error unmarshaling config: %v", err)
+		slog.Error("Error unmarshaling config", "error", err)
 		return nil, err
 	}
-	fmt.Println(cfg)
 	return &cfg, nil
 }
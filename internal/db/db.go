@@ -1,31 +1,100 @@
 package db
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
 
 	"service-test-runner/internal/config"
 
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	gormtracing "gorm.io/plugin/opentelemetry/tracing"
 )
 
-var DB *gorm.DB
-
-// InitDB initializes the database connection using the provided configuration.
-func InitDB(cfg *config.Config) error {
-	dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true",
-		cfg.Database.Username,
-		cfg.Database.Password,
-		cfg.Database.Host,
-		cfg.Database.Port,
-		cfg.Database.DBName,
-	)
-	dbConn, err := gorm.Open(mysql.Open(dsn), &gorm.Config{})
+// Supported database drivers, see database.driver.
+const (
+	DriverMySQL    = "mysql"
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// Open connects to the database of the configured driver (mysql, postgres or sqlite).
+// The returned handle is shared by the repositories, which are given it when they are
+// created.
+func Open(cfg *config.Config) (*gorm.DB, error) {
+	dialector, err := dialector(cfg.Database)
+	if err != nil {
+		return nil, err
+	}
+	gdb, err := gorm.Open(dialector, &gorm.Config{})
+	if err != nil {
+		slog.Error("Error connecting to database", "driver", cfg.Database.Driver, "error", err)
+		return nil, err
+	}
+	if cfg.Database.Driver == DriverSQLite {
+		// SQLite allows one writer at a time, and each connection to :memory: opens a
+		// database of its own.
+		sqlDB, err := gdb.DB()
+		if err != nil {
+			return nil, err
+		}
+		sqlDB.SetMaxOpenConns(1)
+	}
+	// Trace queries run with a context; values are left out as they may hold secrets.
+	if err := gdb.Use(gormtracing.NewPlugin(gormtracing.WithoutMetrics(), gormtracing.WithoutQueryVariables())); err != nil {
+		return nil, err
+	}
+	return gdb, nil
+}
+
+// dialector returns the GORM dialector of the configured driver.
+func dialector(cfg config.DatabaseConfig) (gorm.Dialector, error) {
+	switch cfg.Driver {
+	case DriverMySQL:
+		dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true",
+			cfg.Username,
+			cfg.Password,
+			cfg.Host,
+			cfg.Port,
+			cfg.DBName,
+		)
+		return mysql.Open(dsn), nil
+	case DriverPostgres:
+		dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
+			cfg.Host,
+			cfg.Port,
+			cfg.Username,
+			cfg.Password,
+			cfg.DBName,
+			cfg.SSLMode,
+		)
+		return postgres.Open(dsn), nil
+	case DriverSQLite:
+		return sqlite.Open(cfg.DBName), nil
+	default:
+		return nil, fmt.Errorf("unknown database driver %q", cfg.Driver)
+	}
+}
+
+// Ping returns a health check that the database answers.
+func Ping(gdb *gorm.DB) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		sqlDB, err := gdb.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	}
+}
+
+// Close closes the database connections, once the queries in progress are done.
+func Close(gdb *gorm.DB) error {
+	sqlDB, err := gdb.DB()
 	if err != nil {
-		log.Printf("Error connecting to database: %v", err)
 		return err
 	}
-	DB = dbConn
-	return nil
+	return sqlDB.Close()
 }
@@ -0,0 +1,28 @@
+// Package dbtest provides databases for tests of the repositories.
+package dbtest
+
+import (
+	"context"
+	"testing"
+
+	"service-test-runner/internal/config"
+	"service-test-runner/internal/db"
+
+	"gorm.io/gorm"
+)
+
+// Open returns a migrated in-memory SQLite database, closed when the test ends. It
+// holds the two seeded projects of the first migration, web1 and mobile1.
+func Open(t testing.TB) *gorm.DB {
+	t.Helper()
+	cfg := &config.Config{Database: config.DatabaseConfig{Driver: db.DriverSQLite, DBName: ":memory:"}}
+	gdb, err := db.Open(cfg)
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close(gdb) })
+	if err := db.Migrate(context.Background(), gdb, db.DriverSQLite); err != nil {
+		t.Fatalf("migrate database: %v", err)
+	}
+	return gdb
+}
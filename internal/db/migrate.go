@@ -0,0 +1,137 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+
+	"service-test-runner/migrations"
+
+	"gorm.io/gorm"
+)
+
+// schemaMigration is the single row of schema_migrations, laid out as the migrate CLI
+// keeps it so that either can be used on the same database.
+type schemaMigration struct {
+	Version int64 `gorm:"primaryKey;autoIncrement:false"`
+	Dirty   bool  `gorm:"not null"`
+}
+
+func (schemaMigration) TableName() string { return "schema_migrations" }
+
+// nilVersion is the version of a database without any migration, as the migrate CLI
+// has it, so that migration 0000 is applied too.
+const nilVersion = -1
+
+// migration is one up migration of a driver, e.g. 0012_add_tbl_queue_automations_priority.up.sql.
+type migration struct {
+	version int64
+	name    string
+}
+
+// Migrate applies the up migrations of the driver (migrations/<driver>) newer than the
+// version recorded in schema_migrations. A migration that fails leaves the version
+// dirty, as the migrate CLI does, and has to be fixed by hand before trying again.
+func Migrate(ctx context.Context, gdb *gorm.DB, driver string) error {
+	gdb = gdb.WithContext(ctx)
+	if err := gdb.Exec("CREATE TABLE IF NOT EXISTS schema_migrations (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)").Error; err != nil {
+		return fmt.Errorf("create schema_migrations: %w", err)
+	}
+	var current schemaMigration
+	err := gdb.Take(&current).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		current.Version = nilVersion
+	} else if err != nil {
+		return fmt.Errorf("read schema_migrations: %w", err)
+	}
+	if current.Dirty {
+		return fmt.Errorf("database is dirty at migration %d, fix it and force the version", current.Version)
+	}
+
+	pending, err := pendingMigrations(driver, current.Version)
+	if err != nil {
+		return err
+	}
+	for _, m := range pending {
+		if err := setVersion(gdb, m.version, true); err != nil {
+			return err
+		}
+		script, err := fs.ReadFile(migrations.FS, driver+"/"+m.name)
+		if err != nil {
+			return err
+		}
+		for _, stmt := range statements(string(script)) {
+			if err := gdb.Exec(stmt).Error; err != nil {
+				return fmt.Errorf("migration %s: %w", m.name, err)
+			}
+		}
+		if err := setVersion(gdb, m.version, false); err != nil {
+			return err
+		}
+		slog.Info("Applied database migration", "migration", m.name)
+	}
+	return nil
+}
+
+// pendingMigrations lists the up migrations of the driver after version, oldest first.
+func pendingMigrations(driver string, version int64) ([]migration, error) {
+	entries, err := fs.ReadDir(migrations.FS, driver)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for database driver %q: %w", driver, err)
+	}
+	var pending []migration
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasSuffix(name, ".up.sql") {
+			continue
+		}
+		prefix, _, _ := strings.Cut(name, "_")
+		v, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: bad version: %w", name, err)
+		}
+		if v > version {
+			pending = append(pending, migration{version: v, name: name})
+		}
+	}
+	sort.Slice(pending, func(i, j int) bool { return pending[i].version < pending[j].version })
+	return pending, nil
+}
+
+// setVersion records version as the only row of schema_migrations.
+func setVersion(gdb *gorm.DB, version int64, dirty bool) error {
+	return gdb.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("1 = 1").Delete(&schemaMigration{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&schemaMigration{Version: version, Dirty: dirty}).Error
+	})
+}
+
+// statements splits a migration script into its statements, each ending with a ';' at
+// the end of a line. Comment lines are left out.
+func statements(script string) []string {
+	var stmts []string
+	var b strings.Builder
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			stmts = append(stmts, strings.TrimSpace(b.String()))
+			b.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(b.String()); rest != "" {
+		stmts = append(stmts, rest)
+	}
+	return stmts
+}
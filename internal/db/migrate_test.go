@@ -0,0 +1,159 @@
+package db
+
+import (
+	"context"
+	"io/fs"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"service-test-runner/internal/config"
+	"service-test-runner/migrations"
+
+	"gorm.io/gorm"
+)
+
+func openSQLite(t *testing.T) *gorm.DB {
+	t.Helper()
+	gdb, err := Open(&config.Config{Database: config.DatabaseConfig{Driver: DriverSQLite, DBName: ":memory:"}})
+	if err != nil {
+		t.Fatalf("open database: %v", err)
+	}
+	t.Cleanup(func() { Close(gdb) })
+	return gdb
+}
+
+func TestOpenUnknownDriver(t *testing.T) {
+	_, err := Open(&config.Config{Database: config.DatabaseConfig{Driver: "oracle"}})
+	if err == nil {
+		t.Fatal("expected an error for an unknown driver")
+	}
+}
+
+func TestMigrateAppliesEveryMigrationOnce(t *testing.T) {
+	ctx := context.Background()
+	gdb := openSQLite(t)
+
+	for i := 0; i < 2; i++ {
+		if err := Migrate(ctx, gdb, DriverSQLite); err != nil {
+			t.Fatalf("migrate (pass %d): %v", i+1, err)
+		}
+	}
+
+	latest, err := pendingMigrations(DriverSQLite, nilVersion)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var current schemaMigration
+	if err := gdb.Take(&current).Error; err != nil {
+		t.Fatal(err)
+	}
+	if want := latest[len(latest)-1].version; current.Version != want || current.Dirty {
+		t.Fatalf("schema_migrations = %+v, want version %d clean", current, want)
+	}
+	var projects int64
+	if err := gdb.Table("tbl_projects").Count(&projects).Error; err != nil {
+		t.Fatal(err)
+	}
+	if projects != 2 {
+		t.Fatalf("seeded projects = %d, want 2", projects)
+	}
+}
+
+func TestMigrateRefusesDirtyDatabase(t *testing.T) {
+	ctx := context.Background()
+	gdb := openSQLite(t)
+	if err := Migrate(ctx, gdb, DriverSQLite); err != nil {
+		t.Fatal(err)
+	}
+	if err := setVersion(gdb, 99, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := Migrate(ctx, gdb, DriverSQLite); err == nil {
+		t.Fatal("expected an error for a dirty database")
+	}
+}
+
+func TestDownMigrationsUndoUpMigrations(t *testing.T) {
+	ctx := context.Background()
+	gdb := openSQLite(t)
+	// A database from before the migrations already holds runs.
+	if err := gdb.Exec("CREATE TABLE tbl_queue_automations (id INTEGER PRIMARY KEY AUTOINCREMENT, reference_number VARCHAR(255) NOT NULL UNIQUE, testsuite VARCHAR(255) NOT NULL, step_name VARCHAR(255) NOT NULL DEFAULT '', checkpoint INT NOT NULL DEFAULT 0, total_steps INT NOT NULL DEFAULT 0, status INT NOT NULL, id_test VARCHAR(255) NULL, created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP, project VARCHAR(255) NOT NULL, report_file VARCHAR(1024) NULL)").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := gdb.Exec("INSERT INTO tbl_queue_automations (reference_number, testsuite, status, project) VALUES ('ref-1', 'suite1', 3, 'web1')").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := Migrate(ctx, gdb, DriverSQLite); err != nil {
+		t.Fatal(err)
+	}
+	names, err := fs.Glob(migrations.FS, DriverSQLite+"/*.down.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(names)))
+	for _, name := range names {
+		script, err := fs.ReadFile(migrations.FS, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, stmt := range statements(string(script)) {
+			if err := gdb.Exec(stmt).Error; err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+		}
+	}
+	var tables []string
+	err = gdb.Raw("SELECT name FROM sqlite_master WHERE type = 'table' AND name LIKE 'tbl_%'").Scan(&tables).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables) != 1 || tables[0] != "tbl_queue_automations" {
+		t.Fatalf("tables left after the down migrations: %s", strings.Join(tables, ", "))
+	}
+	var runs int64
+	if err := gdb.Table("tbl_queue_automations").Count(&runs).Error; err != nil {
+		t.Fatal(err)
+	}
+	if runs != 1 {
+		t.Fatalf("runs left after the down migrations = %d, want 1", runs)
+	}
+}
+
+func TestMigrationSetsMatch(t *testing.T) {
+	list := func(driver string) []string {
+		names, err := fs.Glob(migrations.FS, driver+"/*.sql")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, name := range names {
+			names[i] = strings.TrimPrefix(name, driver+"/")
+		}
+		return names
+	}
+	mysql := list(DriverMySQL)
+	for _, driver := range []string{DriverPostgres, DriverSQLite} {
+		if got := list(driver); !reflect.DeepEqual(got, mysql) {
+			t.Errorf("%s migrations = %v, want %v", driver, got, mysql)
+		}
+	}
+}
+
+func TestStatements(t *testing.T) {
+	script := `-- +migrate Up
+ALTER TABLE t
+  ADD COLUMN a INT;
+
+-- a comment
+UPDATE t SET a = 1
+  WHERE a IS NULL;
+`
+	want := []string{
+		"ALTER TABLE t\n  ADD COLUMN a INT;",
+		"UPDATE t SET a = 1\n  WHERE a IS NULL;",
+	}
+	if got := statements(script); !reflect.DeepEqual(got, want) {
+		t.Fatalf("statements = %q, want %q", got, want)
+	}
+}
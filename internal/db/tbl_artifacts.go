@@ -0,0 +1,18 @@
+package db
+
+import "time"
+
+// TblArtifact represents a row in the tbl_artifacts table.
+type TblArtifact struct {
+	ID              uint      `gorm:"primaryKey;autoIncrement"`
+	ReferenceNumber string    `gorm:"not null;index"`
+	IdTest          string    `gorm:"null"`
+	StepName        string    `gorm:"null"`
+	Name            string    `gorm:"not null"`
+	Type            string    `gorm:"not null"`
+	ContentType     string    `gorm:"not null"`
+	Size            int64     `gorm:"not null"`
+	Checksum        string    `gorm:"null"`
+	ObjectName      string    `gorm:"not null"`
+	CreatedAt       time.Time `gorm:"autoCreateTime"`
+}
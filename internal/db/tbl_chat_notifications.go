@@ -0,0 +1,16 @@
+package db
+
+import "time"
+
+// TblChatNotification represents a row in the tbl_chat_notifications table.
+// An empty Testsuite applies to every test suite of the project.
+type TblChatNotification struct {
+	ID         uint      `gorm:"primaryKey;autoIncrement"`
+	Project    string    `gorm:"not null;index"`
+	Testsuite  string    `gorm:"not null;default:''"`
+	Provider   string    `gorm:"not null"`
+	WebhookURL string    `gorm:"not null"`
+	Active     bool      `gorm:"not null;default:true"`
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime"`
+}
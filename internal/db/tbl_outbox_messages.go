@@ -0,0 +1,19 @@
+package db
+
+import "time"
+
+// TblOutboxMessage represents a row in the tbl_outbox_messages table: a RabbitMQ
+// message written together with the queue row it announces, waiting to be published.
+type TblOutboxMessage struct {
+	ID              uint64     `gorm:"primaryKey;autoIncrement"`
+	ReferenceNumber string     `gorm:"not null"`
+	Project         string     `gorm:"not null"`
+	Priority        string     `gorm:"not null"`
+	Payload         string     `gorm:"type:text;not null"`
+	Status          string     `gorm:"not null"`
+	Attempts        int        `gorm:"not null"`
+	Error           string     `gorm:"type:text"`
+	NextAttemptAt   time.Time  `gorm:"not null"`
+	SentAt          *time.Time `gorm:"null"`
+	CreatedAt       time.Time  `gorm:"autoCreateTime"`
+}
@@ -0,0 +1,14 @@
+package db
+
+import "time"
+
+// TblRetentionPolicy represents a row in the tbl_retention_policies table.
+// A zero limit means the corresponding rule is disabled.
+type TblRetentionPolicy struct {
+	ID               uint      `gorm:"primaryKey;autoIncrement"`
+	Project          string    `gorm:"unique;not null"`
+	KeepLastRuns     int       `gorm:"not null"`
+	MaxAgeDays       int       `gorm:"not null"`
+	FailedMaxAgeDays int       `gorm:"not null"`
+	UpdatedAt        time.Time `gorm:"autoUpdateTime"`
+}
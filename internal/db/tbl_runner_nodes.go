@@ -0,0 +1,14 @@
+package db
+
+import "time"
+
+// TblRunnerNode represents a row in the tbl_runner_nodes table: one runner endpoint
+// in the pool of a project. Weight sets its share of the runs relative to the others.
+type TblRunnerNode struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement"`
+	Project   string    `gorm:"not null"`
+	URL       string    `gorm:"not null"`
+	Weight    int       `gorm:"not null;default:1"`
+	Enabled   bool      `gorm:"not null;default:true"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
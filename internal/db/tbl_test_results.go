@@ -0,0 +1,30 @@
+package db
+
+import "time"
+
+// TblTestResult represents a row in the tbl_test_results table, one per test case.
+type TblTestResult struct {
+	ID              uint            `gorm:"primaryKey;autoIncrement"`
+	ReferenceNumber string          `gorm:"not null;index"`
+	Suite           string          `gorm:"not null"`
+	Name            string          `gorm:"not null"`
+	ClassName       string          `gorm:"null"`
+	Status          string          `gorm:"not null"`
+	Duration        float64         `gorm:"not null"`
+	FailureMessage  string          `gorm:"type:text;null"`
+	StackTrace      string          `gorm:"type:text;null"`
+	CreatedAt       time.Time       `gorm:"autoCreateTime"`
+	Steps           []TblStepResult `gorm:"foreignKey:TestResultID"`
+}
+
+// TblStepResult represents a row in the tbl_step_results table, one per BDD step of a scenario.
+type TblStepResult struct {
+	ID              uint    `gorm:"primaryKey;autoIncrement"`
+	TestResultID    uint    `gorm:"not null;index"`
+	ReferenceNumber string  `gorm:"not null;index"`
+	Keyword         string  `gorm:"null"`
+	Name            string  `gorm:"not null"`
+	Status          string  `gorm:"not null"`
+	Duration        float64 `gorm:"not null"`
+	ErrorMessage    string  `gorm:"type:text;null"`
+}
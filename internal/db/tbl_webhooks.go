@@ -0,0 +1,33 @@
+package db
+
+import "time"
+
+// TblWebhook represents a row in the tbl_webhooks table.
+// Events holds a comma-separated list of run event types; empty means all events.
+type TblWebhook struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement"`
+	Project   string    `gorm:"not null;index"`
+	URL       string    `gorm:"not null"`
+	Events    string    `gorm:"null"`
+	Secret    string    `gorm:"not null"`
+	Active    bool      `gorm:"not null;default:true"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// TblWebhookDelivery represents a row in the tbl_webhook_deliveries table.
+type TblWebhookDelivery struct {
+	ID              uint       `gorm:"primaryKey;autoIncrement"`
+	WebhookID       uint       `gorm:"not null;index"`
+	Event           string     `gorm:"not null"`
+	ReferenceNumber string     `gorm:"not null"`
+	Payload         string     `gorm:"type:text;not null"`
+	Status          string     `gorm:"not null"`
+	Attempts        int        `gorm:"not null"`
+	ResponseCode    int        `gorm:"not null"`
+	ResponseBody    string     `gorm:"type:text"`
+	Error           string     `gorm:"type:text"`
+	NextAttemptAt   *time.Time `gorm:"null"`
+	DeliveredAt     *time.Time `gorm:"null"`
+	CreatedAt       time.Time  `gorm:"autoCreateTime"`
+}
@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"service-test-runner/internal/infrastructure/report"
+	"service-test-runner/internal/infrastructure/storage"
+	"service-test-runner/internal/usecase"
+
+	"github.com/gorilla/mux"
+)
+
+// ListArtifactsHandler handles GET /automation/{reference_number}/artifacts.
+func (h *Handler) ListArtifactsHandler(w http.ResponseWriter, r *http.Request) {
+	referenceNumber := mux.Vars(r)["reference_number"]
+
+	automation, err := h.queueAutomationUsecase.GetByReferenceNumber(r.Context(), referenceNumber)
+	if err != nil || automation == nil {
+		respondJSON(w, http.StatusNotFound, StandardResponse{
+			Status:  "error",
+			Message: "Automation not found",
+			Data:    nil,
+		})
+		return
+	}
+
+	artifacts, err := h.artifactUsecase.ListByReferenceNumber(r.Context(), referenceNumber)
+	if err != nil {
+		respondJSON(w, http.StatusInternalServerError, StandardResponse{
+			Status:  "error",
+			Message: err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+	respondJSON(w, http.StatusOK, StandardResponse{
+		Status:  "success",
+		Message: "Artifacts",
+		Data:    map[string]interface{}{"artifacts": artifacts},
+	})
+}
+
+// DownloadObjectHandler handles GET /storage/{object_name}?expires=...&signature=...
+// It serves signed URLs of stores that do not serve objects themselves, such as the local filesystem store.
+func (h *Handler) DownloadObjectHandler(w http.ResponseWriter, r *http.Request) {
+	verifier, ok := h.store.(storage.SignedURLVerifier)
+	if !ok {
+		respondJSON(w, http.StatusNotFound, StandardResponse{
+			Status:  "error",
+			Message: "Not found",
+			Data:    nil,
+		})
+		return
+	}
+
+	objectName := mux.Vars(r)["object_name"]
+	expires, err := strconv.ParseInt(r.URL.Query().Get("expires"), 10, 64)
+	if err == nil {
+		err = verifier.VerifySignedURL(objectName, expires, r.URL.Query().Get("signature"))
+	}
+	if err != nil {
+		respondJSON(w, http.StatusForbidden, StandardResponse{
+			Status:  "error",
+			Message: "Invalid or expired download URL",
+			Data:    nil,
+		})
+		return
+	}
+
+	object, info, err := h.store.Get(r.Context(), objectName)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			statusCode = http.StatusNotFound
+		}
+		respondJSON(w, statusCode, StandardResponse{
+			Status:  "error",
+			Message: err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+	defer object.Close()
+
+	w.Header().Set("Content-Type", info.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(info.Size, 10))
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, object)
+}
+
+// respondArtifactError maps artifact and report upload failures to an HTTP response.
+func respondArtifactError(w http.ResponseWriter, err error) {
+	statusCode := http.StatusInternalServerError
+	if errors.Is(err, usecase.ErrUnsupportedArtifact) || errors.Is(err, usecase.ErrArtifactTooLarge) || errors.Is(err, report.ErrInvalidReport) {
+		statusCode = http.StatusBadRequest
+	}
+	respondJSON(w, statusCode, StandardResponse{
+		Status:  "error",
+		Message: err.Error(),
+		Data:    nil,
+	})
+}
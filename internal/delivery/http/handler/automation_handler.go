@@ -2,22 +2,33 @@ package handler
 
 import (
 	"encoding/json"
-	"fmt"
+	"errors"
 	"io"
 	"net/http"
-	"service-test-runner/internal/db"
+	"service-test-runner/internal/domain"
+	"service-test-runner/internal/infrastructure/logging"
+	"service-test-runner/internal/repository/selenium"
+	"service-test-runner/internal/usecase"
 	"service-test-runner/internal/utils"
 	"strconv"
 	"strings"
 )
 
+// maxFormValueSize bounds each text field of a streamed multipart form.
+const maxFormValueSize = 1 << 20
+
 // RunAutomationHandler handles POST /automation/run.
-// Expected payload: {"project": "web1", "testsuite_id": "login", "email": ""}
+// Expected payload: {"project": "web1", "testsuite_id": "login", "email": "", "priority": "normal"}
+// priority is critical, normal (the default) or low; it orders the queued runs.
+// browser_name, browser_version and platform_name optionally choose the browser; for
+// a project behind a Selenium Grid, a node must offer it.
 func (h *Handler) RunAutomationHandler(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		Project     string `json:"project"`
 		TestSuiteID string `json:"testsuite_id"`
 		Email       string `json:"email"`
+		Priority    string `json:"priority"`
+		domain.Browser
 	}
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		respondJSON(w, http.StatusBadRequest, StandardResponse{
@@ -35,13 +46,24 @@ func (h *Handler) RunAutomationHandler(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
+	if req.Priority == "" {
+		req.Priority = domain.RunPriorityNormal
+	}
+	if domain.RunPriorityRank(req.Priority) < 0 {
+		respondJSON(w, http.StatusBadRequest, StandardResponse{
+			Status:  "error",
+			Message: "priority must be one of " + strings.Join(domain.RunPriorities, ", "),
+			Data:    nil,
+		})
+		return
+	}
 
 	// Retrieve test suite details (to count the steps).
-	detailResp, err := h.testsuiteUsecase.GetDetail(req.Project, req.TestSuiteID)
+	detailResp, err := h.testsuiteUsecase.GetDetail(r.Context(), req.Project, req.TestSuiteID)
 	if err != nil {
-		respondJSON(w, http.StatusInternalServerError, StandardResponse{
+		respondJSON(w, runnerStatusCode(err), StandardResponse{
 			Status:  "error",
-			Message: "Project or test suite not found",
+			Message: "Project or test suite not found: " + err.Error(),
 			Data:    nil,
 		})
 		return
@@ -57,25 +79,30 @@ func (h *Handler) RunAutomationHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Trigger the automation run.
 	refnum := utils.GenerateRefNum()
-	runResp, err := h.automationUsecase.Run(req.Project, req.TestSuiteID, req.Email, refnum)
+	logging.Annotate(r.Context(), "reference_number", refnum, "project", req.Project)
+	runResp, err := h.automationUsecase.Run(r.Context(), req.Project, req.TestSuiteID, req.Email, refnum, req.Browser)
 	runResp.ReferenceNumber = refnum
 	runResp.TestSuiteID = req.TestSuiteID
 	if err != nil {
-		if err.Error() == "your request is queued" {
-			// insert into DB with status=1 (queued)
-			qa := &db.TblQueueAutomation{
+		if errors.Is(err, selenium.ErrRunnerBusy) {
+			// insert into DB as queued
+			qa := &domain.Run{
 				ReferenceNumber: refnum,
 				Testsuite:       req.TestSuiteID,
 				Checkpoint:      0,
 				TotalSteps:      lenSteps,
-				Status:          1, // queued
+				Status:          domain.RunStatusQueued,
 				StepName:        "queued",
 				IdTest:          runResp.RunningID,
 				Project:         req.Project,
+				Email:           req.Email,
+				Priority:        req.Priority,
+				BrowserName:     req.BrowserName,
+				BrowserVersion:  req.BrowserVersion,
+				PlatformName:    req.PlatformName,
 			}
-			db.CreateQueueAutomation(qa)
-			// For a queued request, handle DB creation and publish a RabbitMQ message.
-			if err := h.automationUsecase.HandleQueuedRequest(req.Project, req.TestSuiteID, lenSteps, refnum); err != nil {
+			// The RabbitMQ message is stored with the row and published by the outbox relay.
+			if err := h.queueAutomationUsecase.CreateQueued(r.Context(), qa); err != nil {
 				respondJSON(w, http.StatusInternalServerError, StandardResponse{
 					Status:  "error",
 					Message: err.Error(),
@@ -85,29 +112,46 @@ func (h *Handler) RunAutomationHandler(w http.ResponseWriter, r *http.Request) {
 			}
 			respondJSON(w, http.StatusAccepted, StandardResponse{
 				Status:  "success",
-				Message: "Request queued. A RabbitMQ message has been published.",
+				Message: "Request queued. A RabbitMQ message will be published.",
 				Data:    runResp,
 			})
 			return
 		}
-		respondJSON(w, http.StatusInternalServerError, StandardResponse{
+		statusCode := runnerStatusCode(err)
+		if errors.Is(err, usecase.ErrNoMatchingBrowser) {
+			statusCode = http.StatusBadRequest
+		}
+		respondJSON(w, statusCode, StandardResponse{
 			Status:  "error",
 			Message: err.Error(),
 			Data:    nil,
 		})
 		return
 	}
-	// If run was successful, create a DB record with status=2 (triggered).
-	qa := &db.TblQueueAutomation{
+	// If run was successful, create a DB record as triggered.
+	qa := &domain.Run{
 		ReferenceNumber: refnum,
 		Testsuite:       req.TestSuiteID,
 		Checkpoint:      0,
 		TotalSteps:      lenSteps,
-		Status:          2, // triggered
+		Status:          domain.RunStatusTriggered,
 		IdTest:          runResp.RunningID,
 		Project:         req.Project,
+		RunnerURL:       runResp.RunnerURL,
+		Email:           req.Email,
+		Priority:        req.Priority,
+		BrowserName:     req.BrowserName,
+		BrowserVersion:  req.BrowserVersion,
+		PlatformName:    req.PlatformName,
+	}
+	if err := h.queueAutomationUsecase.Create(r.Context(), qa); err != nil {
+		respondJSON(w, http.StatusInternalServerError, StandardResponse{
+			Status:  "error",
+			Message: err.Error(),
+			Data:    nil,
+		})
+		return
 	}
-	db.CreateQueueAutomation(qa)
 	respondJSON(w, http.StatusOK, StandardResponse{
 		Status:  "success",
 		Message: "Selenium test triggered",
@@ -121,40 +165,58 @@ func (h *Handler) RunAutomationHandler(w http.ResponseWriter, r *http.Request) {
 // - step_name: string
 // - status: int
 // - report_file: optional PDF file
+// - artifacts: optional, repeatable; PDF, JUnit XML, zipped HTML, screenshots, videos or logs
+// Files are streamed straight into storage, so reference_number, id_test and step_name
+// must precede them (400 otherwise); status may come anywhere.
 func (h *Handler) UpdateStatusHandler(w http.ResponseWriter, r *http.Request) {
-	// Parse multipart form with 10MB max memory
-	if err := r.ParseMultipartForm(10 << 20); err != nil && err != http.ErrNotMultipart {
-		respondJSON(w, http.StatusBadRequest, StandardResponse{
-			Status:  "error",
-			Message: "Failed to parse form data",
-			Data:    nil,
-		})
-		return
+	fields := r.URL.Query()
+	statusInt := 0
+	validated := false
+	var junitReports []domain.Artifact
+
+	// parseStatus reads the optional status field; it is read again once every part is
+	// read, as the status is only used then and may follow the files.
+	parseStatus := func() bool {
+		status := fields.Get("status")
+		if status == "" {
+			return true
+		}
+		var err error
+		statusInt, err = strconv.Atoi(status)
+		if err != nil {
+			respondJSON(w, http.StatusBadRequest, StandardResponse{
+				Status:  "error",
+				Message: "Invalid status value",
+				Data:    nil,
+			})
+			return false
+		}
+		return true
 	}
 
-	// Get form values
-	idTest := r.FormValue("id_test")
-	stepName := r.FormValue("step_name")
-	status := r.FormValue("status")
-	referenceNumber := r.FormValue("reference_number")
-	if referenceNumber == "" {
-		respondJSON(w, http.StatusBadRequest, StandardResponse{
-			Status:  "error",
-			Message: "reference_number is required",
-			Data:    nil,
-		})
-		return
-	}
-	if referenceNumber != "" {
-		// If reference number is provided, get the corresponding id_test
-		automation, err := h.queueAutomationUsecase.GetByReferenceNumber(referenceNumber)
+	// validate checks the text fields once, before the first file is stored or the status is updated.
+	validate := func() bool {
+		if validated {
+			return true
+		}
+		referenceNumber := fields.Get("reference_number")
+		if referenceNumber == "" {
+			respondJSON(w, http.StatusBadRequest, StandardResponse{
+				Status:  "error",
+				Message: "reference_number is required",
+				Data:    nil,
+			})
+			return false
+		}
+		logging.Annotate(r.Context(), "reference_number", referenceNumber)
+		automation, err := h.queueAutomationUsecase.GetByReferenceNumber(r.Context(), referenceNumber)
 		if err != nil {
 			respondJSON(w, http.StatusInternalServerError, StandardResponse{
 				Status:  "error",
 				Message: err.Error(),
 				Data:    nil,
 			})
-			return
+			return false
 		}
 		if automation == nil {
 			respondJSON(w, http.StatusNotFound, StandardResponse{
@@ -162,90 +224,160 @@ func (h *Handler) UpdateStatusHandler(w http.ResponseWriter, r *http.Request) {
 				Message: "Automation not found",
 				Data:    nil,
 			})
-			return
+			return false
 		}
-	}
-
-	if idTest == "" {
-		respondJSON(w, http.StatusBadRequest, StandardResponse{
-			Status:  "error",
-			Message: "id_test is required",
-			Data:    nil,
-		})
-		return
-	}
 
-	statusInt := 0
-	if status != "" {
-		var err error
-		statusInt, err = strconv.Atoi(status)
-		if err != nil {
+		if fields.Get("id_test") == "" {
 			respondJSON(w, http.StatusBadRequest, StandardResponse{
 				Status:  "error",
-				Message: "Invalid status value",
+				Message: "id_test is required",
 				Data:    nil,
 			})
-			return
+			return false
 		}
-	}
 
-	// Variables to store MinIO report URL
-	var reportFileURL string
-
-	// Handle file upload if present
-	if file, header, err := r.FormFile("report_file"); err == nil && file != nil {
-		defer file.Close()
+		if !parseStatus() {
+			return false
+		}
+		validated = true
+		return true
+	}
 
-		// Check if it's a PDF
-		if !strings.HasSuffix(strings.ToLower(header.Filename), ".pdf") {
+	reader, err := r.MultipartReader()
+	if err == http.ErrNotMultipart {
+		if err := r.ParseForm(); err != nil {
 			respondJSON(w, http.StatusBadRequest, StandardResponse{
 				Status:  "error",
-				Message: "Only PDF files are allowed",
+				Message: "Failed to parse form data",
 				Data:    nil,
 			})
 			return
 		}
+		fields = r.Form
+	} else if err != nil {
+		respondJSON(w, http.StatusBadRequest, StandardResponse{
+			Status:  "error",
+			Message: "Failed to parse form data",
+			Data:    nil,
+		})
+		return
+	} else {
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				respondJSON(w, http.StatusBadRequest, StandardResponse{
+					Status:  "error",
+					Message: "Failed to parse form data",
+					Data:    nil,
+				})
+				return
+			}
 
-		// Read file content
-		fileBytes, err := io.ReadAll(file)
-		if err != nil {
-			respondJSON(w, http.StatusInternalServerError, StandardResponse{
-				Status:  "error",
-				Message: "Failed to read file",
-				Data:    nil,
-			})
-			return
-		}
+			formName := part.FormName()
+			if part.FileName() == "" {
+				value, err := io.ReadAll(io.LimitReader(part, maxFormValueSize))
+				if err != nil {
+					respondJSON(w, http.StatusBadRequest, StandardResponse{
+						Status:  "error",
+						Message: "Failed to parse form data",
+						Data:    nil,
+					})
+					return
+				}
+				// The fields naming the stored files cannot change once a file is stored.
+				if validated && (formName == "reference_number" || formName == "id_test" || formName == "step_name") {
+					respondJSON(w, http.StatusBadRequest, StandardResponse{
+						Status:  "error",
+						Message: formName + " must precede the files",
+						Data:    nil,
+					})
+					return
+				}
+				fields.Add(formName, string(value))
+				continue
+			}
+			if formName != "report_file" && formName != "artifacts" {
+				continue
+			}
+			if !validate() {
+				return
+			}
 
-		// Generate a unique filename using id_test
-		objectName := fmt.Sprintf("reports/%s/report.pdf", idTest)
+			// The legacy single PDF report is kept as the run's report_file.
+			if formName == "report_file" && !strings.HasSuffix(strings.ToLower(part.FileName()), ".pdf") {
+				respondJSON(w, http.StatusBadRequest, StandardResponse{
+					Status:  "error",
+					Message: "Only PDF files are allowed",
+					Data:    nil,
+				})
+				return
+			}
 
-		// Upload to MinIO
-		if err := h.minioService.UploadPDF(objectName, fileBytes); err != nil {
-			respondJSON(w, http.StatusInternalServerError, StandardResponse{
-				Status:  "error",
-				Message: "Failed to upload report file",
-				Data:    nil,
-			})
-			return
-		}
+			upload := usecase.ArtifactUpload{
+				ReferenceNumber: fields.Get("reference_number"),
+				IdTest:          fields.Get("id_test"),
+				StepName:        fields.Get("step_name"),
+				Filename:        part.FileName(),
+				Reader:          part,
+				Size:            -1,
+			}
+			if formName == "report_file" {
+				upload.Type = domain.ArtifactTypePDF
+			}
+			artifact, err := h.artifactUsecase.Store(r.Context(), upload)
+			if err != nil {
+				respondArtifactError(w, err)
+				return
+			}
 
-		// Get the URL for the uploaded file
-		reportFileURL = h.minioService.GetFileURL(objectName)
+			if formName == "report_file" {
+				// Store the object name; check-status signs a fresh URL on every read.
+				if err := h.queueAutomationUsecase.UpdateReportFile(r.Context(), fields.Get("id_test"), artifact.ObjectName); err != nil {
+					respondJSON(w, http.StatusInternalServerError, StandardResponse{
+						Status:  "error",
+						Message: "Failed to update report file URL",
+						Data:    nil,
+					})
+					return
+				}
+			}
+			// JUnit reports are ingested once all fields have been read.
+			if artifact.Type == domain.ArtifactTypeJUnit {
+				junitReports = append(junitReports, artifact)
+			}
+		}
+	}
+	if !validate() || !parseStatus() {
+		return
+	}
 
-		// Update the report file URL in the database
-		if err := h.queueAutomationUsecase.UpdateReportFile(idTest, reportFileURL); err != nil {
-			respondJSON(w, http.StatusInternalServerError, StandardResponse{
+	// Get form values
+	idTest := fields.Get("id_test")
+	stepName := fields.Get("step_name")
+	referenceNumber := fields.Get("reference_number")
+
+	// Ingest the JUnit reports first, so the results are stored by the time the run is
+	// reported as finished; the status derived from them takes precedence.
+	if len(junitReports) > 0 {
+		results, err := h.ingestJUnit(r.Context(), referenceNumber, junitReports)
+		if err != nil {
+			respondJSON(w, http.StatusBadRequest, StandardResponse{
 				Status:  "error",
-				Message: "Failed to update report file URL",
+				Message: err.Error(),
 				Data:    nil,
 			})
 			return
 		}
+		if results.Tests > 0 {
+			statusInt = results.Status
+		}
 	}
 
 	// Call the use case to update the status
-	if err := h.queueAutomationUsecase.UpdateStatus(idTest, stepName, statusInt, referenceNumber); err != nil {
+	if err := h.queueAutomationUsecase.UpdateStatus(r.Context(), idTest, stepName, statusInt, referenceNumber); err != nil {
 		respondJSON(w, http.StatusInternalServerError, StandardResponse{
 			Status:  "error",
 			Message: err.Error(),
@@ -286,9 +418,10 @@ func (h *Handler) CheckStatusHandler(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
+	logging.Annotate(r.Context(), "reference_number", req.ReferenceNumber)
 
 	// Get automation status from use case
-	automation, err := h.queueAutomationUsecase.GetByReferenceNumber(req.ReferenceNumber)
+	automation, err := h.queueAutomationUsecase.GetByReferenceNumber(r.Context(), req.ReferenceNumber)
 	if err != nil {
 		respondJSON(w, http.StatusInternalServerError, StandardResponse{
 			Status:  "error",
@@ -310,6 +443,15 @@ func (h *Handler) CheckStatusHandler(w http.ResponseWriter, r *http.Request) {
 	if progress > 100 {
 		progress = 100
 	}
+	reportFileURL, err := h.artifactUsecase.ReportURL(r.Context(), automation.ReportFile)
+	if err != nil {
+		respondJSON(w, http.StatusInternalServerError, StandardResponse{
+			Status:  "error",
+			Message: err.Error(),
+			Data:    nil,
+		})
+		return
+	}
 	// Return the automation status
 	respondJSON(w, http.StatusOK, StandardResponse{
 		Status:  "success",
@@ -321,7 +463,7 @@ func (h *Handler) CheckStatusHandler(w http.ResponseWriter, r *http.Request) {
 			"step_name":   automation.StepName,
 			"total_steps": automation.TotalSteps,
 			"progress":    progress,
-			"report_file": automation.ReportFile,
+			"report_file": reportFileURL,
 		},
 	})
 }
@@ -349,9 +491,10 @@ func (h *Handler) RetryAutomationHandler(w http.ResponseWriter, r *http.Request)
 		})
 		return
 	}
+	logging.Annotate(r.Context(), "reference_number", req.ReferenceNumber)
 
 	// Get previous automation data
-	prevAutomation, err := h.queueAutomationUsecase.GetByReferenceNumber(req.ReferenceNumber)
+	prevAutomation, err := h.queueAutomationUsecase.GetByReferenceNumber(r.Context(), req.ReferenceNumber)
 	if err != nil {
 		respondJSON(w, http.StatusBadRequest, StandardResponse{
 			Status:  "error",
@@ -361,30 +504,45 @@ func (h *Handler) RetryAutomationHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	// A queued run is claimed first, so it is not also started by the dispatcher or
+	// another consumer of its message.
+	if prevAutomation.Status == domain.RunStatusQueued {
+		h.retryQueued(w, r, prevAutomation)
+		return
+	}
+
 	// Trigger the automation run
-	runResp, err := h.automationUsecase.Run(prevAutomation.Project, prevAutomation.Testsuite, "", req.ReferenceNumber)
+	runResp, err := h.automationUsecase.Run(r.Context(), prevAutomation.Project, prevAutomation.Testsuite, prevAutomation.Email, req.ReferenceNumber, domain.Browser{
+		BrowserName:    prevAutomation.BrowserName,
+		BrowserVersion: prevAutomation.BrowserVersion,
+		PlatformName:   prevAutomation.PlatformName,
+	})
 	runResp.ReferenceNumber = req.ReferenceNumber
 	runResp.TestSuiteID = prevAutomation.Testsuite
 
 	if err != nil {
-		if err.Error() == "your request is queued" {
-
-			if err := h.automationUsecase.HandleQueuedRequest(prevAutomation.Project, prevAutomation.Testsuite, prevAutomation.TotalSteps, runResp.ReferenceNumber); err != nil {
+		if errors.Is(err, selenium.ErrRunnerBusy) {
+			// Put the run back in the queue, so its next terminal status is reported again.
+			if err := h.queueAutomationUsecase.Requeue(r.Context(), req.ReferenceNumber); err != nil {
 				respondJSON(w, http.StatusInternalServerError, StandardResponse{
 					Status:  "error",
-					Message: err.Error(),
+					Message: "Failed to update automation status",
 					Data:    nil,
 				})
 				return
 			}
 			respondJSON(w, http.StatusAccepted, StandardResponse{
 				Status:  "success",
-				Message: "Request queued. A RabbitMQ message has been published.",
+				Message: "Request queued. A RabbitMQ message will be published.",
 				Data:    runResp,
 			})
 			return
 		}
-		respondJSON(w, http.StatusInternalServerError, StandardResponse{
+		statusCode := runnerStatusCode(err)
+		if errors.Is(err, usecase.ErrNoMatchingBrowser) {
+			statusCode = http.StatusBadRequest
+		}
+		respondJSON(w, statusCode, StandardResponse{
 			Status:  "error",
 			Message: err.Error(),
 			Data:    nil,
@@ -393,12 +551,13 @@ func (h *Handler) RetryAutomationHandler(w http.ResponseWriter, r *http.Request)
 	}
 
 	// If run was successful, update the existing record with status=2 (triggered)
-	qa := &db.TblQueueAutomation{
+	qa := &domain.Run{
 		ReferenceNumber: req.ReferenceNumber,
-		Status:          2, // triggered
+		Status:          domain.RunStatusTriggered,
 		IdTest:          runResp.RunningID,
+		RunnerURL:       runResp.RunnerURL,
 	}
-	if err := h.queueAutomationUsecase.UpdateStatusByReferenceNumber(qa); err != nil {
+	if err := h.queueAutomationUsecase.UpdateStatusByReferenceNumber(r.Context(), qa); err != nil {
 		respondJSON(w, http.StatusInternalServerError, StandardResponse{
 			Status:  "error",
 			Message: "Failed to update automation status",
@@ -413,3 +572,50 @@ func (h *Handler) RetryAutomationHandler(w http.ResponseWriter, r *http.Request)
 		Data:    runResp,
 	})
 }
+
+// retryQueued starts a queued run for RetryAutomationHandler.
+func (h *Handler) retryQueued(w http.ResponseWriter, r *http.Request, record *domain.Run) {
+	runResp, err := h.dispatchUsecase.StartQueued(r.Context(), record)
+	runResp.ReferenceNumber = record.ReferenceNumber
+	runResp.TestSuiteID = record.Testsuite
+
+	switch {
+	case err == nil:
+		respondJSON(w, http.StatusOK, StandardResponse{
+			Status:  "success",
+			Message: "Selenium test triggered",
+			Data:    runResp,
+		})
+	case errors.Is(err, usecase.ErrRunNotQueued):
+		respondJSON(w, http.StatusConflict, StandardResponse{
+			Status:  "error",
+			Message: "The run was already started",
+			Data:    nil,
+		})
+	case errors.Is(err, selenium.ErrRunnerBusy):
+		// Publish a new message, so the run is retried even without the dispatcher.
+		if err := h.queueAutomationUsecase.Requeue(r.Context(), record.ReferenceNumber); err != nil {
+			respondJSON(w, http.StatusInternalServerError, StandardResponse{
+				Status:  "error",
+				Message: "Failed to update automation status",
+				Data:    nil,
+			})
+			return
+		}
+		respondJSON(w, http.StatusAccepted, StandardResponse{
+			Status:  "success",
+			Message: "Request queued. A RabbitMQ message will be published.",
+			Data:    runResp,
+		})
+	default:
+		statusCode := runnerStatusCode(err)
+		if errors.Is(err, usecase.ErrNoMatchingBrowser) {
+			statusCode = http.StatusBadRequest
+		}
+		respondJSON(w, statusCode, StandardResponse{
+			Status:  "error",
+			Message: err.Error(),
+			Data:    nil,
+		})
+	}
+}
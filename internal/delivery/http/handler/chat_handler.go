@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"service-test-runner/internal/domain"
+	"service-test-runner/internal/usecase"
+
+	"github.com/gorilla/mux"
+)
+
+// ListChatNotificationsHandler handles GET /projects/{project}/chat-notifications.
+func (h *Handler) ListChatNotificationsHandler(w http.ResponseWriter, r *http.Request) {
+	chats, err := h.chatUsecase.List(r.Context(), mux.Vars(r)["project"])
+	if err != nil {
+		respondChatError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, StandardResponse{
+		Status:  "success",
+		Message: "Chat notifications",
+		Data:    map[string]interface{}{"chat_notifications": chats},
+	})
+}
+
+// CreateChatNotificationHandler handles POST /projects/{project}/chat-notifications.
+// Expected payload: {"provider": "slack", "webhook_url": "https://hooks.slack.com/services/...", "testsuite": "", "active": true}
+// provider is "slack" or "teams"; an empty testsuite applies to every suite of the project.
+func (h *Handler) CreateChatNotificationHandler(w http.ResponseWriter, r *http.Request) {
+	chat := domain.ChatNotification{Active: true}
+	if err := json.NewDecoder(r.Body).Decode(&chat); err != nil {
+		respondJSON(w, http.StatusBadRequest, StandardResponse{
+			Status:  "error",
+			Message: "Invalid request payload",
+			Data:    nil,
+		})
+		return
+	}
+	chat.Project = mux.Vars(r)["project"]
+
+	created, err := h.chatUsecase.Create(r.Context(), chat)
+	if err != nil {
+		respondChatError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, StandardResponse{
+		Status:  "success",
+		Message: "Chat notification created",
+		Data:    created,
+	})
+}
+
+// UpdateChatNotificationHandler handles PUT /chat-notifications/{chat_id}.
+// Expected payload: {"provider": "teams", "webhook_url": "https://...", "testsuite": "login", "active": true}
+func (h *Handler) UpdateChatNotificationHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := routeID(w, r, "chat_id")
+	if !ok {
+		return
+	}
+	chat := domain.ChatNotification{Active: true}
+	if err := json.NewDecoder(r.Body).Decode(&chat); err != nil {
+		respondJSON(w, http.StatusBadRequest, StandardResponse{
+			Status:  "error",
+			Message: "Invalid request payload",
+			Data:    nil,
+		})
+		return
+	}
+
+	updated, err := h.chatUsecase.Update(r.Context(), id, chat)
+	if err != nil {
+		respondChatError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, StandardResponse{
+		Status:  "success",
+		Message: "Chat notification updated",
+		Data:    updated,
+	})
+}
+
+// DeleteChatNotificationHandler handles DELETE /chat-notifications/{chat_id}.
+func (h *Handler) DeleteChatNotificationHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := routeID(w, r, "chat_id")
+	if !ok {
+		return
+	}
+	if err := h.chatUsecase.Delete(r.Context(), id); err != nil {
+		respondChatError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, StandardResponse{
+		Status:  "success",
+		Message: "Chat notification deleted",
+		Data:    nil,
+	})
+}
+
+// respondChatError maps chat notification failures to an HTTP response.
+func respondChatError(w http.ResponseWriter, err error) {
+	statusCode := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, usecase.ErrChatNotificationNotFound):
+		statusCode = http.StatusNotFound
+	case errors.Is(err, usecase.ErrInvalidChatNotification):
+		statusCode = http.StatusBadRequest
+	}
+	respondJSON(w, statusCode, StandardResponse{
+		Status:  "error",
+		Message: err.Error(),
+		Data:    nil,
+	})
+}
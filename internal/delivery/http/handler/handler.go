@@ -1,10 +1,13 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"service-test-runner/internal/infrastructure/storage"
+	"service-test-runner/internal/repository/selenium"
 	usecase "service-test-runner/internal/usecase"
 )
 
@@ -19,7 +22,17 @@ type Handler struct {
 	queueAutomationUsecase *usecase.QueueAutomationUseCase
 	testsuiteUsecase       *usecase.TestSuiteUsecase
 	projectUsecase         *usecase.ProjectUsecase
-	minioService           *storage.MinioService
+	artifactUsecase        *usecase.ArtifactUsecase
+	resultUsecase          *usecase.ResultUsecase
+	retentionUsecase       *usecase.RetentionUsecase
+	uploadUsecase          *usecase.UploadUsecase
+	webhookUsecase         *usecase.WebhookUsecase
+	chatUsecase            *usecase.ChatUsecase
+	healthUsecase          *usecase.HealthUsecase
+	capacityUsecase        *usecase.CapacityUsecase
+	gridUsecase            *usecase.GridUsecase
+	dispatchUsecase        *usecase.DispatchUsecase
+	store                  storage.ArtifactStore
 }
 
 func NewHandler(
@@ -27,14 +40,34 @@ func NewHandler(
 	queueAutomationUsecase *usecase.QueueAutomationUseCase,
 	testsuiteUsecase *usecase.TestSuiteUsecase,
 	projectUsecase *usecase.ProjectUsecase,
-	minioService *storage.MinioService,
+	artifactUsecase *usecase.ArtifactUsecase,
+	resultUsecase *usecase.ResultUsecase,
+	retentionUsecase *usecase.RetentionUsecase,
+	uploadUsecase *usecase.UploadUsecase,
+	webhookUsecase *usecase.WebhookUsecase,
+	chatUsecase *usecase.ChatUsecase,
+	healthUsecase *usecase.HealthUsecase,
+	capacityUsecase *usecase.CapacityUsecase,
+	gridUsecase *usecase.GridUsecase,
+	dispatchUsecase *usecase.DispatchUsecase,
+	store storage.ArtifactStore,
 ) *Handler {
 	return &Handler{
 		automationUsecase:      automationUsecase,
 		queueAutomationUsecase: queueAutomationUsecase,
 		testsuiteUsecase:       testsuiteUsecase,
 		projectUsecase:         projectUsecase,
-		minioService:           minioService,
+		artifactUsecase:        artifactUsecase,
+		resultUsecase:          resultUsecase,
+		retentionUsecase:       retentionUsecase,
+		uploadUsecase:          uploadUsecase,
+		webhookUsecase:         webhookUsecase,
+		chatUsecase:            chatUsecase,
+		healthUsecase:          healthUsecase,
+		capacityUsecase:        capacityUsecase,
+		gridUsecase:            gridUsecase,
+		dispatchUsecase:        dispatchUsecase,
+		store:                  store,
 	}
 }
 
@@ -43,3 +76,17 @@ func respondJSON(w http.ResponseWriter, statusCode int, payload StandardResponse
 	w.WriteHeader(statusCode)
 	json.NewEncoder(w).Encode(payload)
 }
+
+// runnerStatusCode maps an error of a runner call to the status of the response:
+// 503 while the runners of the project are cut off by their circuit breaker, 502 when
+// a runner answered with an unexpected status or did not answer in time, 500 otherwise.
+func runnerStatusCode(err error) int {
+	var runnerErr *selenium.RunnerError
+	switch {
+	case errors.Is(err, selenium.ErrCircuitOpen):
+		return http.StatusServiceUnavailable
+	case errors.As(err, &runnerErr), errors.Is(err, context.DeadlineExceeded):
+		return http.StatusBadGateway
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"net/http"
+
+	"service-test-runner/internal/domain"
+)
+
+// LivenessHandler handles GET /healthz. It only reports that the process is serving requests.
+func (h *Handler) LivenessHandler(w http.ResponseWriter, r *http.Request) {
+	respondJSON(w, http.StatusOK, StandardResponse{
+		Status:  "success",
+		Message: "Alive",
+		Data:    h.healthUsecase.Live(),
+	})
+}
+
+// ReadinessHandler handles GET /readyz. It checks every dependency and answers 503
+// when a critical one (database, RabbitMQ, artifact storage) is down.
+func (h *Handler) ReadinessHandler(w http.ResponseWriter, r *http.Request) {
+	report := h.healthUsecase.Ready(r.Context())
+	if report.Status == domain.HealthStatusDown {
+		respondJSON(w, http.StatusServiceUnavailable, StandardResponse{
+			Status:  "error",
+			Message: "Not ready",
+			Data:    report,
+		})
+		return
+	}
+	respondJSON(w, http.StatusOK, StandardResponse{
+		Status:  "success",
+		Message: "Ready",
+		Data:    report,
+	})
+}
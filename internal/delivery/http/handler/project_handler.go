@@ -1,7 +1,12 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+
+	"service-test-runner/internal/repository/grid"
+
+	"github.com/gorilla/mux"
 )
 
 // ProjectHandler handles GET /projects.
@@ -22,3 +27,46 @@ func (h *Handler) ProjectHandler(w http.ResponseWriter, r *http.Request) {
 		Data:    runResp,
 	})
 }
+
+// ProjectBrowsersHandler handles GET /projects/{project}/browsers.
+// It returns the Selenium Grid status of the project: its nodes, free and total slots,
+// and the browsers, versions and platforms they offer.
+func (h *Handler) ProjectBrowsersHandler(w http.ResponseWriter, r *http.Request) {
+	status, err := h.gridUsecase.Status(r.Context(), mux.Vars(r)["project"])
+	if err != nil {
+		statusCode := http.StatusBadGateway
+		if errors.Is(err, grid.ErrNoGrid) {
+			statusCode = http.StatusNotFound
+		}
+		respondJSON(w, statusCode, StandardResponse{
+			Status:  "error",
+			Message: err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+	respondJSON(w, http.StatusOK, StandardResponse{
+		Status:  "success",
+		Message: "Selenium Grid status",
+		Data:    status,
+	})
+}
+
+// ProjectSlotsHandler handles GET /projects/slots.
+// It returns, for every project, the runner capacity and the runs active, starting and queued.
+func (h *Handler) ProjectSlotsHandler(w http.ResponseWriter, r *http.Request) {
+	usage, err := h.capacityUsecase.Usage(r.Context())
+	if err != nil {
+		respondJSON(w, http.StatusInternalServerError, StandardResponse{
+			Status:  "error",
+			Message: err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+	respondJSON(w, http.StatusOK, StandardResponse{
+		Status:  "success",
+		Message: "Project slot usage",
+		Data:    usage,
+	})
+}
@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"service-test-runner/internal/domain"
+	"service-test-runner/internal/usecase"
+
+	"github.com/gorilla/mux"
+)
+
+// UploadJUnitHandler handles POST /automation/{reference_number}/junit.
+// Expected payload: multipart form with one or more JUnit/xUnit XML files in "file".
+func (h *Handler) UploadJUnitHandler(w http.ResponseWriter, r *http.Request) {
+	referenceNumber := mux.Vars(r)["reference_number"]
+
+	automation, err := h.queueAutomationUsecase.GetByReferenceNumber(r.Context(), referenceNumber)
+	if err != nil || automation == nil {
+		respondJSON(w, http.StatusNotFound, StandardResponse{
+			Status:  "error",
+			Message: "Automation not found",
+			Data:    nil,
+		})
+		return
+	}
+
+	reader, err := r.MultipartReader()
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, StandardResponse{
+			Status:  "error",
+			Message: "Failed to parse form data",
+			Data:    nil,
+		})
+		return
+	}
+
+	// Keep the raw reports as artifacts, then parse them back from storage.
+	var reports []domain.Artifact
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			respondJSON(w, http.StatusBadRequest, StandardResponse{
+				Status:  "error",
+				Message: "Failed to parse form data",
+				Data:    nil,
+			})
+			return
+		}
+		if part.FormName() != "file" || part.FileName() == "" {
+			continue
+		}
+
+		artifact, err := h.artifactUsecase.Store(r.Context(), usecase.ArtifactUpload{
+			ReferenceNumber: referenceNumber,
+			IdTest:          automation.IdTest,
+			Filename:        part.FileName(),
+			Reader:          part,
+			Size:            -1,
+			Type:            domain.ArtifactTypeJUnit,
+		})
+		if errors.Is(err, usecase.ErrUnsupportedArtifact) {
+			respondJSON(w, http.StatusBadRequest, StandardResponse{
+				Status:  "error",
+				Message: "Only JUnit XML files are allowed",
+				Data:    nil,
+			})
+			return
+		}
+		if err != nil {
+			respondArtifactError(w, err)
+			return
+		}
+		reports = append(reports, artifact)
+	}
+	if len(reports) == 0 {
+		respondJSON(w, http.StatusBadRequest, StandardResponse{
+			Status:  "error",
+			Message: "file is required",
+			Data:    nil,
+		})
+		return
+	}
+
+	results, err := h.ingestJUnit(r.Context(), referenceNumber, reports)
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, StandardResponse{
+			Status:  "error",
+			Message: err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+	respondJSON(w, http.StatusOK, StandardResponse{
+		Status:  "success",
+		Message: "Test results ingested",
+		Data:    results,
+	})
+}
+
+// maxCucumberReportSize bounds the size of an uploaded Cucumber JSON report, embeddings included.
+const maxCucumberReportSize = 100 << 20
+
+// UploadCucumberHandler handles POST /automation/{reference_number}/cucumber.
+// Expected payload: a Cucumber JSON report, either as the raw request body or as
+// a multipart form file in "file".
+func (h *Handler) UploadCucumberHandler(w http.ResponseWriter, r *http.Request) {
+	referenceNumber := mux.Vars(r)["reference_number"]
+
+	automation, err := h.queueAutomationUsecase.GetByReferenceNumber(r.Context(), referenceNumber)
+	if err != nil || automation == nil {
+		respondJSON(w, http.StatusNotFound, StandardResponse{
+			Status:  "error",
+			Message: "Automation not found",
+			Data:    nil,
+		})
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxCucumberReportSize)
+	var body io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		part, err := nextFilePart(r, "file")
+		if err != nil {
+			respondJSON(w, http.StatusBadRequest, StandardResponse{
+				Status:  "error",
+				Message: "file is required",
+				Data:    nil,
+			})
+			return
+		}
+		body = part
+	}
+
+	results, err := h.resultUsecase.IngestCucumber(r.Context(), referenceNumber, body)
+	if err != nil {
+		respondArtifactError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, StandardResponse{
+		Status:  "success",
+		Message: "Test results ingested",
+		Data:    results,
+	})
+}
+
+// GetResultsHandler handles GET /automation/{reference_number}/results.
+func (h *Handler) GetResultsHandler(w http.ResponseWriter, r *http.Request) {
+	referenceNumber := mux.Vars(r)["reference_number"]
+
+	automation, err := h.queueAutomationUsecase.GetByReferenceNumber(r.Context(), referenceNumber)
+	if err != nil || automation == nil {
+		respondJSON(w, http.StatusNotFound, StandardResponse{
+			Status:  "error",
+			Message: "Automation not found",
+			Data:    nil,
+		})
+		return
+	}
+
+	results, err := h.resultUsecase.GetResults(r.Context(), referenceNumber)
+	if err != nil {
+		respondJSON(w, http.StatusInternalServerError, StandardResponse{
+			Status:  "error",
+			Message: err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+	respondJSON(w, http.StatusOK, StandardResponse{
+		Status:  "success",
+		Message: "Test results",
+		Data:    results,
+	})
+}
+
+// ingestJUnit reads the stored JUnit artifacts back and hands them to the result use case together.
+func (h *Handler) ingestJUnit(ctx context.Context, referenceNumber string, reports []domain.Artifact) (domain.RunResults, error) {
+	readers := make([]io.Reader, 0, len(reports))
+	for _, artifact := range reports {
+		object, err := h.artifactUsecase.Open(ctx, artifact)
+		if err != nil {
+			return domain.RunResults{}, err
+		}
+		defer object.Close()
+		readers = append(readers, object)
+	}
+	return h.resultUsecase.IngestJUnit(ctx, referenceNumber, readers...)
+}
+
+// nextFilePart streams the multipart request up to the first file in the given form field.
+func nextFilePart(r *http.Request, formName string) (*multipart.Part, error) {
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			return nil, err
+		}
+		if part.FormName() == formName && part.FileName() != "" {
+			return part, nil
+		}
+	}
+}
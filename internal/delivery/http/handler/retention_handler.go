@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"service-test-runner/internal/domain"
+
+	"github.com/gorilla/mux"
+)
+
+// RetentionDryRunHandler handles GET /retention/dry-run?project=web1.
+// It lists the runs and storage objects the janitor would remove, without removing them.
+// Without a project query parameter every project is evaluated.
+func (h *Handler) RetentionDryRunHandler(w http.ResponseWriter, r *http.Request) {
+	candidates, err := h.retentionUsecase.Plan(r.Context(), r.URL.Query().Get("project"))
+	if err != nil {
+		respondJSON(w, http.StatusInternalServerError, StandardResponse{
+			Status:  "error",
+			Message: err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+	respondJSON(w, http.StatusOK, StandardResponse{
+		Status:  "success",
+		Message: "Retention dry run",
+		Data:    map[string]interface{}{"runs": candidates},
+	})
+}
+
+// GetRetentionPolicyHandler handles GET /projects/{project}/retention.
+func (h *Handler) GetRetentionPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	policy, err := h.retentionUsecase.GetPolicy(r.Context(), mux.Vars(r)["project"])
+	if err != nil {
+		respondJSON(w, http.StatusInternalServerError, StandardResponse{
+			Status:  "error",
+			Message: err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+	respondJSON(w, http.StatusOK, StandardResponse{
+		Status:  "success",
+		Message: "Retention policy",
+		Data:    policy,
+	})
+}
+
+// UpdateRetentionPolicyHandler handles PUT /projects/{project}/retention.
+// Expected payload: {"keep_last_runs": 20, "max_age_days": 30, "failed_max_age_days": 90}
+func (h *Handler) UpdateRetentionPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	var policy domain.RetentionPolicy
+	if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+		respondJSON(w, http.StatusBadRequest, StandardResponse{
+			Status:  "error",
+			Message: "Invalid request payload",
+			Data:    nil,
+		})
+		return
+	}
+	policy.Project = mux.Vars(r)["project"]
+
+	if err := h.retentionUsecase.SavePolicy(r.Context(), policy); err != nil {
+		respondJSON(w, http.StatusBadRequest, StandardResponse{
+			Status:  "error",
+			Message: err.Error(),
+			Data:    nil,
+		})
+		return
+	}
+	respondJSON(w, http.StatusOK, StandardResponse{
+		Status:  "success",
+		Message: "Retention policy updated",
+		Data:    policy,
+	})
+}
+
+// PinAutomationHandler handles POST /automation/{reference_number}/pin.
+// Expected payload: {"pinned": true}
+func (h *Handler) PinAutomationHandler(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Pinned bool `json:"pinned"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, StandardResponse{
+			Status:  "error",
+			Message: "Invalid request payload",
+			Data:    nil,
+		})
+		return
+	}
+
+	referenceNumber := mux.Vars(r)["reference_number"]
+	if err := h.retentionUsecase.SetPinned(r.Context(), referenceNumber, req.Pinned); err != nil {
+		respondJSON(w, http.StatusNotFound, StandardResponse{
+			Status:  "error",
+			Message: "Automation not found",
+			Data:    nil,
+		})
+		return
+	}
+	respondJSON(w, http.StatusOK, StandardResponse{
+		Status:  "success",
+		Message: "Pin updated",
+		Data:    map[string]interface{}{"reference_number": referenceNumber, "pinned": req.Pinned},
+	})
+}
@@ -16,9 +16,9 @@ func (h *Handler) GetTestSuitesHandler(w http.ResponseWriter, r *http.Request) {
 		})
 		return
 	}
-	suites, err := h.testsuiteUsecase.GetAll(project)
+	suites, err := h.testsuiteUsecase.GetAll(r.Context(), project)
 	if err != nil {
-		respondJSON(w, http.StatusInternalServerError, StandardResponse{
+		respondJSON(w, runnerStatusCode(err), StandardResponse{
 			Status:  "error",
 			Message: err.Error(),
 			Data:    nil,
@@ -55,9 +55,9 @@ func (h *Handler) GetTestSuiteDetailHandler(w http.ResponseWriter, r *http.Reque
 		})
 		return
 	}
-	detail, err := h.testsuiteUsecase.GetDetail(req.Project, req.TestSuiteName)
+	detail, err := h.testsuiteUsecase.GetDetail(r.Context(), req.Project, req.TestSuiteName)
 	if err != nil {
-		respondJSON(w, http.StatusInternalServerError, StandardResponse{
+		respondJSON(w, runnerStatusCode(err), StandardResponse{
 			Status:  "error",
 			Message: err.Error(),
 			Data:    nil,
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"service-test-runner/internal/domain"
+	"service-test-runner/internal/infrastructure/logging"
+	"service-test-runner/internal/usecase"
+
+	"github.com/gorilla/mux"
+)
+
+// CreateUploadHandler handles POST /uploads.
+// Expected payload: {"reference_number": "20250315214839", "id_test": "", "step_name": "", "filename": "run.mp4", "size": 734003200}
+// size is optional; when given, completing the upload checks it.
+func (h *Handler) CreateUploadHandler(w http.ResponseWriter, r *http.Request) {
+	var req domain.UploadSession
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondJSON(w, http.StatusBadRequest, StandardResponse{
+			Status:  "error",
+			Message: "Invalid request payload",
+			Data:    nil,
+		})
+		return
+	}
+	logging.Annotate(r.Context(), "reference_number", req.ReferenceNumber)
+
+	automation, err := h.queueAutomationUsecase.GetByReferenceNumber(r.Context(), req.ReferenceNumber)
+	if err != nil || automation == nil {
+		respondJSON(w, http.StatusNotFound, StandardResponse{
+			Status:  "error",
+			Message: "Automation not found",
+			Data:    nil,
+		})
+		return
+	}
+
+	session, err := h.uploadUsecase.Create(r.Context(), req)
+	if err != nil {
+		respondUploadError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, StandardResponse{
+		Status:  "success",
+		Message: "Upload created",
+		Data:    session,
+	})
+}
+
+// GetUploadHandler handles GET /uploads/{upload_id}. It reports the chunks received
+// so far, so an interrupted client can resume with the missing ones.
+func (h *Handler) GetUploadHandler(w http.ResponseWriter, r *http.Request) {
+	session, err := h.uploadUsecase.Get(mux.Vars(r)["upload_id"])
+	if err != nil {
+		respondUploadError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, StandardResponse{
+		Status:  "success",
+		Message: "Upload",
+		Data:    session,
+	})
+}
+
+// UploadChunkHandler handles PUT /uploads/{upload_id}/chunks/{index}.
+// Expected payload: the raw bytes of the chunk.
+func (h *Handler) UploadChunkHandler(w http.ResponseWriter, r *http.Request) {
+	index, err := strconv.Atoi(mux.Vars(r)["index"])
+	if err != nil {
+		respondJSON(w, http.StatusBadRequest, StandardResponse{
+			Status:  "error",
+			Message: "Invalid chunk index",
+			Data:    nil,
+		})
+		return
+	}
+
+	session, err := h.uploadUsecase.PutChunk(mux.Vars(r)["upload_id"], index, r.Body)
+	if err != nil {
+		respondUploadError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, StandardResponse{
+		Status:  "success",
+		Message: "Chunk received",
+		Data:    session,
+	})
+}
+
+// CompleteUploadHandler handles POST /uploads/{upload_id}/complete.
+func (h *Handler) CompleteUploadHandler(w http.ResponseWriter, r *http.Request) {
+	artifact, err := h.uploadUsecase.Complete(r.Context(), mux.Vars(r)["upload_id"])
+	if err != nil {
+		respondUploadError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, StandardResponse{
+		Status:  "success",
+		Message: "Upload completed",
+		Data:    artifact,
+	})
+}
+
+// AbortUploadHandler handles DELETE /uploads/{upload_id}.
+func (h *Handler) AbortUploadHandler(w http.ResponseWriter, r *http.Request) {
+	if err := h.uploadUsecase.Abort(mux.Vars(r)["upload_id"]); err != nil {
+		respondUploadError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, StandardResponse{
+		Status:  "success",
+		Message: "Upload aborted",
+		Data:    nil,
+	})
+}
+
+// respondUploadError maps chunked upload failures to an HTTP response.
+func respondUploadError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, usecase.ErrUploadNotFound):
+		respondJSON(w, http.StatusNotFound, StandardResponse{
+			Status:  "error",
+			Message: err.Error(),
+			Data:    nil,
+		})
+	case errors.Is(err, usecase.ErrInvalidUpload):
+		respondJSON(w, http.StatusBadRequest, StandardResponse{
+			Status:  "error",
+			Message: err.Error(),
+			Data:    nil,
+		})
+	default:
+		respondArtifactError(w, err)
+	}
+}
@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"service-test-runner/internal/domain"
+	"service-test-runner/internal/usecase"
+
+	"github.com/gorilla/mux"
+)
+
+// defaultDeliveryLimit is the number of deliveries listed when no limit is given.
+const defaultDeliveryLimit = 50
+
+// ListWebhooksHandler handles GET /projects/{project}/webhooks.
+func (h *Handler) ListWebhooksHandler(w http.ResponseWriter, r *http.Request) {
+	webhooks, err := h.webhookUsecase.List(r.Context(), mux.Vars(r)["project"])
+	if err != nil {
+		respondWebhookError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, StandardResponse{
+		Status:  "success",
+		Message: "Webhooks",
+		Data:    map[string]interface{}{"webhooks": webhooks},
+	})
+}
+
+// CreateWebhookHandler handles POST /projects/{project}/webhooks.
+// Expected payload: {"url": "https://ci.example.com/hooks/runs", "events": ["run.finished"], "secret": "", "active": true}
+// An empty event list subscribes to every event, and a secret is generated when none is given.
+func (h *Handler) CreateWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	webhook := domain.Webhook{Active: true}
+	if err := json.NewDecoder(r.Body).Decode(&webhook); err != nil {
+		respondJSON(w, http.StatusBadRequest, StandardResponse{
+			Status:  "error",
+			Message: "Invalid request payload",
+			Data:    nil,
+		})
+		return
+	}
+	webhook.Project = mux.Vars(r)["project"]
+
+	created, err := h.webhookUsecase.Create(r.Context(), webhook)
+	if err != nil {
+		respondWebhookError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusCreated, StandardResponse{
+		Status:  "success",
+		Message: "Webhook created",
+		Data:    created,
+	})
+}
+
+// GetWebhookHandler handles GET /webhooks/{webhook_id}.
+func (h *Handler) GetWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := routeID(w, r, "webhook_id")
+	if !ok {
+		return
+	}
+	webhook, err := h.webhookUsecase.Get(r.Context(), id)
+	if err != nil {
+		respondWebhookError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, StandardResponse{
+		Status:  "success",
+		Message: "Webhook",
+		Data:    webhook,
+	})
+}
+
+// UpdateWebhookHandler handles PUT /webhooks/{webhook_id}.
+// Expected payload: {"url": "https://ci.example.com/hooks/runs", "events": ["run.started", "run.finished"], "active": false}
+// The secret is only replaced when one is given.
+func (h *Handler) UpdateWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := routeID(w, r, "webhook_id")
+	if !ok {
+		return
+	}
+	webhook := domain.Webhook{Active: true}
+	if err := json.NewDecoder(r.Body).Decode(&webhook); err != nil {
+		respondJSON(w, http.StatusBadRequest, StandardResponse{
+			Status:  "error",
+			Message: "Invalid request payload",
+			Data:    nil,
+		})
+		return
+	}
+
+	updated, err := h.webhookUsecase.Update(r.Context(), id, webhook)
+	if err != nil {
+		respondWebhookError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, StandardResponse{
+		Status:  "success",
+		Message: "Webhook updated",
+		Data:    updated,
+	})
+}
+
+// DeleteWebhookHandler handles DELETE /webhooks/{webhook_id}.
+func (h *Handler) DeleteWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := routeID(w, r, "webhook_id")
+	if !ok {
+		return
+	}
+	if err := h.webhookUsecase.Delete(r.Context(), id); err != nil {
+		respondWebhookError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, StandardResponse{
+		Status:  "success",
+		Message: "Webhook deleted",
+		Data:    nil,
+	})
+}
+
+// ListWebhookDeliveriesHandler handles GET /webhooks/{webhook_id}/deliveries?limit=50.
+func (h *Handler) ListWebhookDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := routeID(w, r, "webhook_id")
+	if !ok {
+		return
+	}
+	limit := defaultDeliveryLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			respondJSON(w, http.StatusBadRequest, StandardResponse{
+				Status:  "error",
+				Message: "Invalid limit value",
+				Data:    nil,
+			})
+			return
+		}
+		limit = parsed
+	}
+
+	deliveries, err := h.webhookUsecase.Deliveries(r.Context(), id, limit)
+	if err != nil {
+		respondWebhookError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusOK, StandardResponse{
+		Status:  "success",
+		Message: "Webhook deliveries",
+		Data:    map[string]interface{}{"deliveries": deliveries},
+	})
+}
+
+// RedeliverWebhookHandler handles POST /webhooks/deliveries/{delivery_id}/redeliver.
+func (h *Handler) RedeliverWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	id, ok := routeID(w, r, "delivery_id")
+	if !ok {
+		return
+	}
+	delivery, err := h.webhookUsecase.Redeliver(r.Context(), id)
+	if err != nil {
+		respondWebhookError(w, err)
+		return
+	}
+	respondJSON(w, http.StatusAccepted, StandardResponse{
+		Status:  "success",
+		Message: "Redelivery queued",
+		Data:    delivery,
+	})
+}
+
+// routeID parses a numeric route variable, responding with 404 when it is not a valid ID.
+func routeID(w http.ResponseWriter, r *http.Request, name string) (uint, bool) {
+	id, err := strconv.ParseUint(mux.Vars(r)[name], 10, 64)
+	if err != nil {
+		respondJSON(w, http.StatusNotFound, StandardResponse{
+			Status:  "error",
+			Message: "Not found",
+			Data:    nil,
+		})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// respondWebhookError maps webhook failures to an HTTP response.
+func respondWebhookError(w http.ResponseWriter, err error) {
+	statusCode := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, usecase.ErrWebhookNotFound):
+		statusCode = http.StatusNotFound
+	case errors.Is(err, usecase.ErrInvalidWebhook):
+		statusCode = http.StatusBadRequest
+	}
+	respondJSON(w, statusCode, StandardResponse{
+		Status:  "error",
+		Message: err.Error(),
+		Data:    nil,
+	})
+}
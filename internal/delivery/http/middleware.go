@@ -0,0 +1,79 @@
+package http
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"service-test-runner/internal/infrastructure/logging"
+	"service-test-runner/internal/infrastructure/metrics"
+
+	"github.com/felixge/httpsnoop"
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// metricsMiddleware records the latency of every matched route, labelled with its path template.
+func metricsMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		m := httpsnoop.CaptureMetrics(next, w, r)
+
+		metrics.ObserveHTTPRequest(r.Method, routeTemplate(r), m.Code, start)
+	})
+}
+
+// probeRoutes are polled by orchestrators; they are only logged at debug level unless they fail.
+var probeRoutes = map[string]bool{"/healthz": true, "/readyz": true}
+
+// requestIDHeader carries the correlation ID of a request, taken from the caller or generated.
+const requestIDHeader = "X-Request-ID"
+
+// requestLogMiddleware tags every request with a request ID, echoed in the response,
+// and logs one line per request, with the trace ID when the request is traced. Handlers add the reference number of the run they
+// touch with logging.Annotate.
+func requestLogMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(requestIDHeader)
+		if requestID == "" || len(requestID) > 128 {
+			requestID = logging.NewRequestID()
+		}
+		w.Header().Set(requestIDHeader, requestID)
+
+		logger := slog.Default().With("request_id", requestID)
+		if span := trace.SpanContextFromContext(r.Context()); span.IsValid() {
+			logger = logger.With("trace_id", span.TraceID().String())
+		}
+		ctx := logging.WithLogger(r.Context(), logger)
+		if referenceNumber := mux.Vars(r)["reference_number"]; referenceNumber != "" {
+			logging.Annotate(ctx, "reference_number", referenceNumber)
+		}
+		r = r.WithContext(ctx)
+		m := httpsnoop.CaptureMetrics(next, w, r)
+
+		route := routeTemplate(r)
+		level := slog.LevelInfo
+		switch {
+		case m.Code >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case probeRoutes[route]:
+			level = slog.LevelDebug
+		}
+		logging.FromContext(ctx).Log(ctx, level, "HTTP request",
+			"method", r.Method,
+			"route", route,
+			"status", m.Code,
+			"duration_ms", m.Duration.Milliseconds(),
+			"bytes", m.Written)
+	})
+}
+
+// routeTemplate returns the path template of the matched route.
+func routeTemplate(r *http.Request) string {
+	if current := mux.CurrentRoute(r); current != nil {
+		if template, err := current.GetPathTemplate(); err == nil {
+			return template
+		}
+	}
+	return "unknown"
+}
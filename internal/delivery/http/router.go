@@ -3,9 +3,11 @@ package http
 import (
 	"net/http"
 	"service-test-runner/internal/delivery/http/handler"
+	"service-test-runner/internal/infrastructure/metrics"
 
 	"github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
 )
 
 func RegisterRoutes(r *mux.Router, h *handler.Handler) {
@@ -21,24 +23,58 @@ func RegisterRoutes(r *mux.Router, h *handler.Handler) {
 			"Authorization",
 			"X-CSRF-Token",
 			"X-Requested-With",
+			requestIDHeader,
 		}),
-		handlers.ExposedHeaders([]string{"Content-Length"}),
+		handlers.ExposedHeaders([]string{"Content-Length", requestIDHeader}),
 		handlers.MaxAge(3600),
 	)
 
 	// Apply CORS middleware to all routes
 	r.Use(corsMiddleware)
+	r.Use(otelmux.Middleware("service-test-runner"))
+	r.Use(requestLogMiddleware)
+	r.Use(metricsMiddleware)
 
 	// Add global OPTIONS handler for preflight requests
 	r.Methods("OPTIONS").HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	})
 
+	r.Handle("/metrics", metrics.Handler()).Methods("GET")
+	r.HandleFunc("/healthz", h.LivenessHandler).Methods("GET")
+	r.HandleFunc("/readyz", h.ReadinessHandler).Methods("GET")
 	r.HandleFunc("/automation/run", h.RunAutomationHandler).Methods("POST")
 	r.HandleFunc("/automation/retry", h.RetryAutomationHandler).Methods("POST")
 	r.HandleFunc("/automation/update-status", h.UpdateStatusHandler).Methods("POST")
 	r.HandleFunc("/automation/check-status", h.CheckStatusHandler).Methods("POST")
+	r.HandleFunc("/automation/{reference_number}/artifacts", h.ListArtifactsHandler).Methods("GET")
+	r.HandleFunc("/automation/{reference_number}/junit", h.UploadJUnitHandler).Methods("POST")
+	r.HandleFunc("/automation/{reference_number}/cucumber", h.UploadCucumberHandler).Methods("POST")
+	r.HandleFunc("/automation/{reference_number}/results", h.GetResultsHandler).Methods("GET")
+	r.HandleFunc("/automation/{reference_number}/pin", h.PinAutomationHandler).Methods("POST")
+	r.HandleFunc("/retention/dry-run", h.RetentionDryRunHandler).Methods("GET")
+	r.HandleFunc("/uploads", h.CreateUploadHandler).Methods("POST")
+	r.HandleFunc("/uploads/{upload_id}", h.GetUploadHandler).Methods("GET")
+	r.HandleFunc("/uploads/{upload_id}", h.AbortUploadHandler).Methods("DELETE")
+	r.HandleFunc("/uploads/{upload_id}/chunks/{index}", h.UploadChunkHandler).Methods("PUT")
+	r.HandleFunc("/uploads/{upload_id}/complete", h.CompleteUploadHandler).Methods("POST")
+	r.HandleFunc("/storage/{object_name:.+}", h.DownloadObjectHandler).Methods("GET")
 	r.HandleFunc("/testsuites", h.GetTestSuitesHandler).Methods("GET")
 	r.HandleFunc("/testsuite/detail", h.GetTestSuiteDetailHandler).Methods("POST")
 	r.HandleFunc("/projects", h.ProjectHandler).Methods("GET")
+	r.HandleFunc("/projects/slots", h.ProjectSlotsHandler).Methods("GET")
+	r.HandleFunc("/projects/{project}/browsers", h.ProjectBrowsersHandler).Methods("GET")
+	r.HandleFunc("/projects/{project}/retention", h.GetRetentionPolicyHandler).Methods("GET")
+	r.HandleFunc("/projects/{project}/retention", h.UpdateRetentionPolicyHandler).Methods("PUT")
+	r.HandleFunc("/projects/{project}/webhooks", h.ListWebhooksHandler).Methods("GET")
+	r.HandleFunc("/projects/{project}/webhooks", h.CreateWebhookHandler).Methods("POST")
+	r.HandleFunc("/projects/{project}/chat-notifications", h.ListChatNotificationsHandler).Methods("GET")
+	r.HandleFunc("/projects/{project}/chat-notifications", h.CreateChatNotificationHandler).Methods("POST")
+	r.HandleFunc("/chat-notifications/{chat_id:[0-9]+}", h.UpdateChatNotificationHandler).Methods("PUT")
+	r.HandleFunc("/chat-notifications/{chat_id:[0-9]+}", h.DeleteChatNotificationHandler).Methods("DELETE")
+	r.HandleFunc("/webhooks/{webhook_id:[0-9]+}", h.GetWebhookHandler).Methods("GET")
+	r.HandleFunc("/webhooks/{webhook_id:[0-9]+}", h.UpdateWebhookHandler).Methods("PUT")
+	r.HandleFunc("/webhooks/{webhook_id:[0-9]+}", h.DeleteWebhookHandler).Methods("DELETE")
+	r.HandleFunc("/webhooks/{webhook_id:[0-9]+}/deliveries", h.ListWebhookDeliveriesHandler).Methods("GET")
+	r.HandleFunc("/webhooks/deliveries/{delivery_id:[0-9]+}/redeliver", h.RedeliverWebhookHandler).Methods("POST")
 }
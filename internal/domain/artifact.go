@@ -0,0 +1,29 @@
+package domain
+
+import "time"
+
+// Artifact types recognised by the artifact subsystem.
+const (
+	ArtifactTypePDF        = "pdf"
+	ArtifactTypeJUnit      = "junit"
+	ArtifactTypeHTML       = "html"
+	ArtifactTypeScreenshot = "screenshot"
+	ArtifactTypeVideo      = "video"
+	ArtifactTypeLog        = "log"
+)
+
+// Artifact represents a stored file produced by a run or one of its steps.
+type Artifact struct {
+	ID              uint      `json:"id"`
+	ReferenceNumber string    `json:"reference_number"`
+	IdTest          string    `json:"id_test"`
+	StepName        string    `json:"step_name"`
+	Name            string    `json:"name"`
+	Type            string    `json:"type"`
+	ContentType     string    `json:"content_type"`
+	Size            int64     `json:"size"`
+	Checksum        string    `json:"checksum"`
+	ObjectName      string    `json:"-"`
+	URL             string    `json:"url"`
+	CreatedAt       time.Time `json:"created_at"`
+}
@@ -1,5 +1,7 @@
 package domain
 
+import "time"
+
 // AutomationService defines the contract for running automation.
 type AutomationService interface {
 	RunAutomation(project, testsuiteID, email string) (RunResponse, error)
@@ -10,6 +12,7 @@ type RunResponse struct {
 	RunningID       string `json:"running_id"`
 	TestSuiteID     string `json:"testsuite_id"`
 	ReferenceNumber string `json:"reference_number"`
+	RunnerURL       string `json:"runner_url,omitempty"` // the runner node the run was sent to
 }
 
 // QueuedRequest represents the payload for a queued automation request.
@@ -18,3 +21,128 @@ type QueuedRequest struct {
 	TestSuiteID string `json:"testsuite_id"`
 	Email       string `json:"email"`
 }
+
+// Run statuses stored in tbl_queue_automations.status. The service sets queued and
+// triggered; runners report passed or failed through /automation/updatestatus when a run
+// ends, and any other code while it is in progress. A JUnit report sent with the
+// update sets passed or failed from its results instead.
+const (
+	RunStatusQueued    = 1
+	RunStatusTriggered = 2
+	RunStatusPassed    = 3
+	RunStatusFailed    = 4
+)
+
+// Run priorities stored in tbl_queue_automations.priority, most urgent first. The
+// priority of a queued run is also the last segment of its routing key.
+const (
+	RunPriorityCritical = "critical"
+	RunPriorityNormal   = "normal"
+	RunPriorityLow      = "low"
+)
+
+// RunPriorities lists the run priorities, most urgent first.
+var RunPriorities = []string{RunPriorityCritical, RunPriorityNormal, RunPriorityLow}
+
+// RunPriorityRank returns the position of a priority in RunPriorities, or -1 if it is unknown.
+func RunPriorityRank(priority string) int {
+	for i, p := range RunPriorities {
+		if p == priority {
+			return i
+		}
+	}
+	return -1
+}
+
+// Outbox message statuses stored in tbl_outbox_messages.status.
+const (
+	OutboxMessagePending = "pending"
+	OutboxMessageSent    = "sent"
+)
+
+// Run is a test suite run, from the moment it is queued or sent to its runner until
+// it reaches a terminal status. IdTest is the run ID returned by the runner.
+type Run struct {
+	ID              uint
+	ReferenceNumber string
+	Testsuite       string
+	StepName        string
+	Checkpoint      int
+	TotalSteps      int
+	Status          int
+	Priority        string
+	IdTest          string
+	CreatedAt       time.Time
+	Project         string
+	RunnerURL       string
+	BrowserName     string
+	BrowserVersion  string
+	PlatformName    string
+	ReportFile      string
+	Email           string
+	Pinned          bool
+	ArchivedAt      *time.Time
+}
+
+// RequesterRunCount is the number of runs a requester, identified by email, has on a project.
+type RequesterRunCount struct {
+	Project string
+	Email   string
+	Count   int64
+}
+
+// OutboxMessage is a RabbitMQ message stored together with the run it announces,
+// waiting to be published.
+type OutboxMessage struct {
+	ID              uint64
+	ReferenceNumber string
+	Project         string
+	Priority        string
+	Payload         string
+	Status          string
+	Attempts        int
+	Error           string
+	NextAttemptAt   time.Time
+	SentAt          *time.Time
+	CreatedAt       time.Time
+}
+
+// FinalRunStatuses lists the terminal run statuses.
+var FinalRunStatuses = []int{RunStatusPassed, RunStatusFailed}
+
+// IsFinalStatus reports whether a run status is terminal.
+func IsFinalStatus(status int) bool {
+	for _, final := range FinalRunStatuses {
+		if status == final {
+			return true
+		}
+	}
+	return false
+}
+
+// Run event types, emitted as a run moves through its lifecycle.
+const (
+	RunEventQueued   = "run.queued"
+	RunEventStarted  = "run.started"
+	RunEventProgress = "run.progress"
+	RunEventFinished = "run.finished"
+)
+
+// RunEventTypes lists every run event type.
+var RunEventTypes = []string{RunEventQueued, RunEventStarted, RunEventProgress, RunEventFinished}
+
+// RunEvent is emitted when a run is queued, starts, reports a step or reaches a terminal status.
+type RunEvent struct {
+	Type            string    `json:"type"`
+	ReferenceNumber string    `json:"reference_number"`
+	Project         string    `json:"project"`
+	Testsuite       string    `json:"testsuite_id"`
+	IdTest          string    `json:"id_test"`
+	Status          int       `json:"status"`
+	StepName        string    `json:"step_name"`
+	Checkpoint      int       `json:"checkpoint"`
+	TotalSteps      int       `json:"total_steps"`
+	Email           string    `json:"-"`
+	StartedAt       time.Time `json:"started_at"`
+	OccurredAt      time.Time `json:"occurred_at"`
+}
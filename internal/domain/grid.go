@@ -0,0 +1,39 @@
+package domain
+
+// Browser is the browser a run asks for, in W3C capability terms. Empty fields match
+// any browser; BrowserVersion matches versions it is a prefix of ("120" matches "120.0.6099.109").
+type Browser struct {
+	BrowserName    string `json:"browser_name,omitempty"`
+	BrowserVersion string `json:"browser_version,omitempty"`
+	PlatformName   string `json:"platform_name,omitempty"`
+}
+
+// GridBrowser is a browser offered by the nodes of a Selenium Grid, with its slots.
+type GridBrowser struct {
+	Browser
+	Slots     int `json:"slots"`
+	FreeSlots int `json:"free_slots"`
+}
+
+// GridNode is a node of a Selenium Grid as reported by the hub's /status.
+type GridNode struct {
+	ID           string        `json:"id"`
+	URI          string        `json:"uri"`
+	Availability string        `json:"availability"`
+	MaxSessions  int           `json:"max_sessions"`
+	Slots        int           `json:"slots"`
+	FreeSlots    int           `json:"free_slots"`
+	Browsers     []GridBrowser `json:"browsers"`
+}
+
+// GridStatus is the capacity of the Selenium Grid of a project. Only nodes that are
+// UP count towards the slots and browsers.
+type GridStatus struct {
+	Project   string        `json:"project"`
+	Ready     bool          `json:"ready"`
+	Message   string        `json:"message"`
+	Slots     int           `json:"slots"`
+	FreeSlots int           `json:"free_slots"`
+	Browsers  []GridBrowser `json:"browsers"`
+	Nodes     []GridNode    `json:"nodes"`
+}
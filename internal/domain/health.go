@@ -0,0 +1,25 @@
+package domain
+
+// Health statuses of a dependency check and of a whole report.
+const (
+	HealthStatusOK       = "ok"
+	HealthStatusDegraded = "degraded"
+	HealthStatusDown     = "down"
+)
+
+// HealthCheckResult is the outcome of checking one dependency. Non-critical
+// dependencies, such as the runners, only degrade the service when they are down.
+type HealthCheckResult struct {
+	Name       string  `json:"name"`
+	Status     string  `json:"status"`
+	Critical   bool    `json:"critical"`
+	DurationMs float64 `json:"duration_ms"`
+	Error      string  `json:"error,omitempty"`
+}
+
+// HealthReport is the state of the service and, for readiness, of its dependencies.
+type HealthReport struct {
+	Status        string              `json:"status"`
+	UptimeSeconds int64               `json:"uptime_seconds"`
+	Checks        []HealthCheckResult `json:"checks,omitempty"`
+}
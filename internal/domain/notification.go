@@ -0,0 +1,45 @@
+package domain
+
+import "time"
+
+// RunSummary is the content of a run notification.
+type RunSummary struct {
+	ReferenceNumber string
+	Project         string
+	Testsuite       string
+	Verdict         string // PASSED or FAILED
+	Recovered       bool   // passed after the previous run of the suite failed
+	Duration        time.Duration
+	Tests           int
+	Passed          int
+	Failed          int
+	Skipped         int
+	FailedSteps     []FailedStep
+	ReportURL       string
+	FinishedAt      time.Time
+}
+
+// FailedStep is a failed test case or step listed in a run notification.
+type FailedStep struct {
+	Name    string
+	Message string
+}
+
+// Chat notification providers.
+const (
+	ChatProviderSlack = "slack"
+	ChatProviderTeams = "teams"
+)
+
+// ChatNotification posts a message to a Slack or Microsoft Teams incoming webhook when
+// a run of the project fails or recovers. An empty Testsuite applies to every suite of
+// the project that has no chat notification of its own.
+type ChatNotification struct {
+	ID         uint      `json:"id"`
+	Project    string    `json:"project"`
+	Testsuite  string    `json:"testsuite"`
+	Provider   string    `json:"provider"`
+	WebhookURL string    `json:"webhook_url"`
+	Active     bool      `json:"active"`
+	CreatedAt  time.Time `json:"created_at"`
+}
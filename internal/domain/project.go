@@ -1,5 +1,25 @@
 package domain
 
+// Project is a project whose test suites run on its own runner. MaxConcurrentRuns is
+// the number of runs the runner accepts at once, 0 for no limit; GridURL is the
+// Selenium Grid 4 hub the runner drives, if any.
+type Project struct {
+	ID                uint
+	Name              string
+	URL               string
+	GridURL           string
+	MaxConcurrentRuns int
+}
+
+// RunnerNode is one runner endpoint in the pool of a project. Weight sets its share of
+// the runs relative to the other nodes.
+type RunnerNode struct {
+	ID      uint
+	Project string
+	URL     string
+	Weight  int
+}
+
 // ProjectResponse represents an individual project's information.
 type ProjectResponse struct {
 	Name string `json:"name"`
@@ -8,3 +28,14 @@ type ProjectResponse struct {
 
 // ShowProjectResponse is a slice of ProjectResponse.
 type ShowProjectResponse []ProjectResponse
+
+// ProjectSlots is the slot usage of a project runner. MaxConcurrentRuns is 0 and
+// Available nil when the runner has no limit; Starting counts the runs being sent to it.
+type ProjectSlots struct {
+	Project           string `json:"project"`
+	MaxConcurrentRuns int    `json:"max_concurrent_runs"`
+	Active            int64  `json:"active"`
+	Starting          int    `json:"starting"`
+	Queued            int64  `json:"queued"`
+	Available         *int64 `json:"available"`
+}
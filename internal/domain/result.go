@@ -0,0 +1,53 @@
+package domain
+
+// Test case statuses reported by ingested test reports.
+const (
+	TestStatusPassed  = "passed"
+	TestStatusFailed  = "failed"
+	TestStatusError   = "error"
+	TestStatusSkipped = "skipped"
+)
+
+// TestCaseResult represents the outcome of a single test case.
+type TestCaseResult struct {
+	Name           string       `json:"name"`
+	ClassName      string       `json:"classname"`
+	Status         string       `json:"status"`
+	Duration       float64      `json:"duration"`
+	FailureMessage string       `json:"failure_message,omitempty"`
+	StackTrace     string       `json:"stack_trace,omitempty"`
+	Steps          []StepResult `json:"steps,omitempty"`
+}
+
+// StepResult represents the outcome of a single BDD step within a scenario.
+type StepResult struct {
+	Keyword      string  `json:"keyword"`
+	Name         string  `json:"name"`
+	Status       string  `json:"status"`
+	Duration     float64 `json:"duration"`
+	ErrorMessage string  `json:"error_message,omitempty"`
+}
+
+// TestSuiteResult groups the test cases of one suite with its counts.
+type TestSuiteResult struct {
+	Name     string           `json:"name"`
+	Tests    int              `json:"tests"`
+	Failures int              `json:"failures"`
+	Errors   int              `json:"errors"`
+	Skipped  int              `json:"skipped"`
+	Duration float64          `json:"duration"`
+	Cases    []TestCaseResult `json:"cases"`
+}
+
+// RunResults is the structured result of a run, aggregated over all its suites.
+type RunResults struct {
+	ReferenceNumber string            `json:"reference_number"`
+	Status          int               `json:"status"`
+	Tests           int               `json:"tests"`
+	Passed          int               `json:"passed"`
+	Failures        int               `json:"failures"`
+	Errors          int               `json:"errors"`
+	Skipped         int               `json:"skipped"`
+	Duration        float64           `json:"duration"`
+	Suites          []TestSuiteResult `json:"suites"`
+}
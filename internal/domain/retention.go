@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// RetentionPolicy defines how long the runs of a project are kept. A zero value
+// disables the corresponding rule; pinned runs are never removed.
+type RetentionPolicy struct {
+	Project          string `json:"project"`
+	KeepLastRuns     int    `json:"keep_last_runs"`      // runs kept per test suite
+	MaxAgeDays       int    `json:"max_age_days"`        // age after which runs are removed
+	FailedMaxAgeDays int    `json:"failed_max_age_days"` // age after which failed runs are removed
+}
+
+// RetentionCandidate is a run selected for archival by a retention policy.
+type RetentionCandidate struct {
+	ReferenceNumber string    `json:"reference_number"`
+	Project         string    `json:"project"`
+	Testsuite       string    `json:"testsuite"`
+	Status          int       `json:"status"`
+	CreatedAt       time.Time `json:"created_at"`
+	Reason          string    `json:"reason"`
+	Objects         []string  `json:"objects"`
+}
@@ -0,0 +1,18 @@
+package domain
+
+import "time"
+
+// UploadSession is a chunked, resumable artifact upload in progress. Chunks are
+// numbered from 0 and may arrive in any order; a client resumes an interrupted
+// upload by sending the chunks missing from ReceivedChunks.
+type UploadSession struct {
+	UploadID        string    `json:"upload_id"`
+	ReferenceNumber string    `json:"reference_number"`
+	IdTest          string    `json:"id_test"`
+	StepName        string    `json:"step_name"`
+	Filename        string    `json:"filename"`
+	Size            int64     `json:"size"` // declared total size, -1 when unknown
+	ReceivedChunks  []int     `json:"received_chunks"`
+	ReceivedBytes   int64     `json:"received_bytes"`
+	CreatedAt       time.Time `json:"created_at"`
+}
@@ -0,0 +1,40 @@
+package domain
+
+import "time"
+
+// Webhook delivery statuses.
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// Webhook is a subscription of a URL to the run events of a project. Payloads are
+// signed with the secret; an empty event list subscribes to every run event.
+type Webhook struct {
+	ID        uint      `json:"id"`
+	Project   string    `json:"project"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret,omitempty"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookDelivery is one attempt sequence to deliver a run event to a webhook.
+// Payload is the JSON body posted to the webhook.
+type WebhookDelivery struct {
+	ID              uint       `json:"id"`
+	WebhookID       uint       `json:"webhook_id"`
+	Event           string     `json:"event"`
+	ReferenceNumber string     `json:"reference_number"`
+	Payload         string     `json:"-"`
+	Status          string     `json:"status"`
+	Attempts        int        `json:"attempts"`
+	ResponseCode    int        `json:"response_code"`
+	ResponseBody    string     `json:"response_body,omitempty"`
+	Error           string     `json:"error,omitempty"`
+	NextAttemptAt   *time.Time `json:"next_attempt_at,omitempty"`
+	DeliveredAt     *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
@@ -0,0 +1,37 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestRunContextLogsReferenceNumberOnce(t *testing.T) {
+	var out bytes.Buffer
+	logger := slog.New(uniqueHandler{Handler: slog.NewTextHandler(&out, nil)})
+
+	ctx := WithLogger(context.Background(), logger.With("request_id", "req-1"))
+	Annotate(ctx, "reference_number", "ref-1")
+	runCtx := With(ctx, "reference_number", "ref-1", "project", "web1")
+	FromContext(runCtx).With("reference_number", "ref-1").Info("Started run", "reference_number", "ref-1")
+
+	line := out.String()
+	if n := strings.Count(line, "reference_number="); n != 1 {
+		t.Fatalf("reference_number logged %d times: %s", n, line)
+	}
+	for _, attr := range []string{"request_id=req-1", "reference_number=ref-1", "project=web1"} {
+		if !strings.Contains(line, attr) {
+			t.Fatalf("line lacks %s: %s", attr, line)
+		}
+	}
+
+	// Annotating the run context leaves the request's own lines alone.
+	Annotate(runCtx, "runner_url", "http://runner")
+	out.Reset()
+	FromContext(ctx).Info("Request done")
+	if strings.Contains(out.String(), "runner_url") || strings.Contains(out.String(), "project") {
+		t.Fatalf("run attributes reached the request logger: %s", out.String())
+	}
+}
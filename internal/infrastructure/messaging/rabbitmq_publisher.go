@@ -1,65 +1,474 @@
 package messaging
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"service-test-runner/internal/config"
+	"service-test-runner/internal/infrastructure/logging"
+	"service-test-runner/internal/infrastructure/metrics"
+	"service-test-runner/internal/infrastructure/tracing"
+
 	"github.com/streadway/amqp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	// ErrPublishBufferFull is returned when too many messages are already waiting for the broker.
+	ErrPublishBufferFull = errors.New("publish buffer full")
+	// ErrPublishRejected is returned when the broker negatively acknowledges a message.
+	ErrPublishRejected = errors.New("message rejected by the broker")
+	// ErrPublisherClosed is returned for messages published after Close.
+	ErrPublisherClosed = errors.New("publisher closed")
 )
 
-// ConnectToRabbitMQ connects to the RabbitMQ server using the provided URL and returns the connection and channel.
-func ConnectToRabbitMQ(amqpURL string) (*amqp.Connection, *amqp.Channel, error) {
-	conn, err := amqp.Dial(amqpURL)
+// Publisher defines the interface for a message publisher.
+type Publisher interface {
+	// Publish sends a message about a run of project, routed by its priority.
+	Publish(ctx context.Context, project, priority string, message []byte) error
+}
+
+// pendingMessage is a message waiting in the buffer for the broker to confirm it.
+type pendingMessage struct {
+	ctx        context.Context
+	project    string
+	routingKey string
+	publishing amqp.Publishing
+	result     chan error
+}
+
+// RabbitMQPublisher publishes messages to a topic exchange with publisher confirms,
+// routed as run.<project>.<priority> to one durable queue per project. It reconnects
+// with exponential backoff whenever the connection or channel is lost; meanwhile
+// messages wait in a bounded buffer until they are confirmed or their publish
+// timeout expires.
+type RabbitMQPublisher struct {
+	url            string
+	exchangeName   string
+	queuePrefix    string
+	queueType      string
+	messageTTL     time.Duration
+	deliveryLimit  int
+	deadLetterExch string
+	deadLetterQ    string
+	publishTimeout time.Duration
+	reconnectDelay time.Duration
+	maxDelay       time.Duration
+	pending        chan pendingMessage
+	done           chan struct{}
+	closeOnce      sync.Once
+	running        sync.WaitGroup
+
+	mu       sync.Mutex
+	conn     *amqp.Connection
+	channel  *amqp.Channel
+	confirms chan amqp.Confirmation
+	nextTag  uint64
+	ready    chan struct{} // closed while connected
+	connErr  error
+	projects map[string]bool // projects whose queue is declared, again on every reconnect
+}
+
+// NewRabbitMQPublisher connects to RabbitMQ, declares the exchanges and starts publishing.
+// Only the first connection attempt is reported; later ones are retried in the background.
+func NewRabbitMQPublisher(cfg config.RabbitMQConfig) (*RabbitMQPublisher, error) {
+	p := &RabbitMQPublisher{
+		url:            cfg.AMQPURL(),
+		exchangeName:   cfg.ExchangeName,
+		queuePrefix:    cfg.QueuePrefix,
+		queueType:      cfg.QueueType,
+		messageTTL:     cfg.MessageTTL,
+		deliveryLimit:  cfg.DeliveryLimit,
+		deadLetterExch: cfg.DeadLetterExchange,
+		deadLetterQ:    cfg.DeadLetterQueue,
+		publishTimeout: cfg.PublishTimeout,
+		reconnectDelay: cfg.ReconnectDelay,
+		maxDelay:       cfg.ReconnectMaxDelay,
+		pending:        make(chan pendingMessage, cfg.BufferSize),
+		done:           make(chan struct{}),
+		ready:          make(chan struct{}),
+		projects:       make(map[string]bool),
+	}
+	if p.deadLetterExch == "" {
+		p.deadLetterExch = p.exchangeName + ".dlx"
+	}
+	if p.deadLetterQ == "" {
+		p.deadLetterQ = p.exchangeName + ".dead"
+	}
+	closed, err := p.connect()
+	if err != nil {
+		return nil, err
+	}
+	p.running.Add(2)
+	go func() {
+		defer p.running.Done()
+		p.supervise(closed)
+	}()
+	go func() {
+		defer p.running.Done()
+		p.run()
+	}()
+	return p, nil
+}
+
+// Publish sends the message to the exchange, carrying the trace context of ctx in its
+// headers, and returns once the broker has confirmed it. The queue of a project is
+// declared before its first message. A message whose publish timeout expires while
+// the broker is unreachable is dropped from the buffer.
+func (p *RabbitMQPublisher) Publish(ctx context.Context, project, priority string, message []byte) (err error) {
+	routingKey := RunRoutingKey(project, priority)
+	logger := logging.FromContext(ctx).With("project", project, "routing_key", routingKey)
+	ctx, span := tracing.Start(ctx, p.exchangeName+" publish", trace.SpanKindProducer,
+		attribute.String("messaging.system", "rabbitmq"),
+		attribute.String("messaging.destination.name", p.exchangeName),
+		attribute.String("messaging.rabbitmq.destination.routing_key", routingKey),
+		attribute.Int("messaging.message.body.size", len(message)))
+	defer func() {
+		metrics.ObservePublish(err)
+		tracing.End(span, err)
+		if err != nil {
+			logger.Warn("Error publishing message", "error", err)
+			return
+		}
+		logger.Debug("Published message")
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, p.publishTimeout)
+	defer cancel()
+	headers := amqp.Table{}
+	otel.GetTextMapPropagator().Inject(ctx, headerCarrier(headers))
+	m := pendingMessage{
+		ctx:        ctx,
+		project:    project,
+		routingKey: routingKey,
+		publishing: amqp.Publishing{
+			ContentType:  "application/json",
+			DeliveryMode: amqp.Persistent,
+			Headers:      headers,
+			Body:         message,
+		},
+		result: make(chan error, 1),
+	}
+
+	select {
+	case p.pending <- m:
+	case <-p.done:
+		return ErrPublisherClosed
+	default:
+		return ErrPublishBufferFull
+	}
+	select {
+	case err := <-m.result:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("message not confirmed: %w", ctx.Err())
+	case <-p.done:
+		return ErrPublisherClosed
+	}
+}
+
+// DeclareProjectQueues declares the queues of projects, now if the publisher is
+// connected and again after every reconnect.
+func (p *RabbitMQPublisher) DeclareProjectQueues(projects ...string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, project := range projects {
+		if p.connErr == nil {
+			if err := p.declareProjectQueue(p.channel, project); err != nil {
+				return err
+			}
+		}
+		p.projects[project] = true
+	}
+	return nil
+}
+
+// Ping reports whether the publisher is connected to the broker.
+func (p *RabbitMQPublisher) Ping(_ context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.connErr
+}
+
+// Close stops publishing and closes the connection, once the message being published,
+// if any, has been confirmed or has timed out.
+func (p *RabbitMQPublisher) Close() error {
+	p.closeOnce.Do(func() { close(p.done) })
+	p.running.Wait()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.conn == nil {
+		return nil
+	}
+	return p.conn.Close()
+}
+
+// connect dials the broker and prepares a confirming channel on the exchange. The
+// returned channel receives an error when the connection or the channel closes.
+func (p *RabbitMQPublisher) connect() (<-chan *amqp.Error, error) {
+	conn, err := amqp.Dial(p.url)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 	ch, err := conn.Channel()
 	if err != nil {
 		conn.Close()
-		return nil, nil, err
+		return nil, err
 	}
-	return conn, ch, nil
+	if err := p.declareTopology(ch); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := ch.Confirm(false); err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to enable publisher confirms: %v", err)
+	}
+
+	// Either close is enough to reconnect; both notifications are buffered so the
+	// library never blocks on the one that is not read.
+	closed := make(chan *amqp.Error, 1)
+	connClosed := conn.NotifyClose(make(chan *amqp.Error, 1))
+	chanClosed := ch.NotifyClose(make(chan *amqp.Error, 1))
+	go func() {
+		select {
+		case err := <-connClosed:
+			closed <- err
+		case err := <-chanClosed:
+			closed <- err
+		}
+	}()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.conn, p.channel = conn, ch
+	p.confirms = ch.NotifyPublish(make(chan amqp.Confirmation, 1))
+	p.nextTag = 1
+	p.connErr = nil
+	close(p.ready)
+	return closed, nil
 }
 
-// Publisher defines the interface for a message publisher.
-type Publisher interface {
-	Publish(message []byte) error
+// declareTopology declares the run exchange, the dead letter exchange with its queue,
+// and the queues of the projects known so far.
+func (p *RabbitMQPublisher) declareTopology(ch *amqp.Channel) error {
+	for _, name := range []string{p.exchangeName, p.deadLetterExch} {
+		if err := ch.ExchangeDeclare(name, amqp.ExchangeTopic, true, false, false, false, nil); err != nil {
+			return fmt.Errorf("failed to declare exchange %s: %v", name, err)
+		}
+	}
+	if _, err := ch.QueueDeclare(p.deadLetterQ, true, false, false, false, amqp.Table{"x-queue-type": p.queueType}); err != nil {
+		return fmt.Errorf("failed to declare queue %s: %v", p.deadLetterQ, err)
+	}
+	if err := ch.QueueBind(p.deadLetterQ, "#", p.deadLetterExch, false, nil); err != nil {
+		return fmt.Errorf("failed to bind queue %s: %v", p.deadLetterQ, err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for project := range p.projects {
+		if err := p.declareProjectQueue(ch, project); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-// RabbitMQPublisher is a concrete implementation that publishes messages to a specific exchange.
-type RabbitMQPublisher struct {
-	Channel      *amqp.Channel
-	ExchangeName string
-}
-
-// NewRabbitMQPublisher creates a new RabbitMQPublisher for an exchange.
-func NewRabbitMQPublisher(channel *amqp.Channel, exchangeName string) *RabbitMQPublisher {
-	err := channel.ExchangeDeclare(
-		exchangeName,
-		"fanout", // or "direct"/"topic" as needed
-		true,     // durable
-		false,    // auto-deleted
-		false,    // internal
-		false,    // no-wait
-		nil,      // arguments
-	)
+// declareProjectQueue declares the durable queue of a project and binds it to every
+// priority of the project's runs. Expired messages, and messages delivered more than
+// the delivery limit, are dead-lettered.
+func (p *RabbitMQPublisher) declareProjectQueue(ch *amqp.Channel, project string) error {
+	name := p.queuePrefix + "." + routingSegment(project)
+	args := amqp.Table{
+		"x-queue-type":           p.queueType,
+		"x-dead-letter-exchange": p.deadLetterExch,
+	}
+	if p.messageTTL > 0 {
+		args["x-message-ttl"] = p.messageTTL.Milliseconds()
+	}
+	if p.queueType == "quorum" && p.deliveryLimit > 0 {
+		args["x-delivery-limit"] = int64(p.deliveryLimit)
+	}
+	if _, err := ch.QueueDeclare(name, true, false, false, false, args); err != nil {
+		return fmt.Errorf("failed to declare queue %s: %v", name, err)
+	}
+	if err := ch.QueueBind(name, RunRoutingKey(project, "*"), p.exchangeName, false, nil); err != nil {
+		return fmt.Errorf("failed to bind queue %s: %v", name, err)
+	}
+	return nil
+}
+
+// supervise reconnects, with exponential backoff, every time the connection is lost.
+func (p *RabbitMQPublisher) supervise(closed <-chan *amqp.Error) {
+	for {
+		select {
+		case amqpErr := <-closed:
+			var err error
+			if amqpErr != nil {
+				err = amqpErr
+			}
+			p.disconnected(nil, err)
+		case <-p.done:
+			return
+		}
+
+		delay := p.reconnectDelay
+		for {
+			select {
+			case <-time.After(delay):
+			case <-p.done:
+				return
+			}
+			var err error
+			if closed, err = p.connect(); err == nil {
+				slog.Info("Reconnected to RabbitMQ", "exchange", p.exchangeName)
+				break
+			}
+			slog.Warn("Error reconnecting to RabbitMQ", "retry_in", delay.String(), "error", err)
+			if delay *= 2; delay > p.maxDelay {
+				delay = p.maxDelay
+			}
+		}
+	}
+}
+
+// disconnected marks the publisher as down until the next successful connect. A failure
+// on a channel other than the current one is stale and ignored.
+func (p *RabbitMQPublisher) disconnected(ch *amqp.Channel, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.connErr != nil || (ch != nil && ch != p.channel) {
+		return
+	}
+	p.connErr = errors.New("connection to RabbitMQ lost")
 	if err != nil {
-		panic(err) // handle error appropriately in production
+		p.connErr = fmt.Errorf("connection to RabbitMQ lost: %v", err)
 	}
+	p.ready = make(chan struct{})
+	if p.conn != nil {
+		p.conn.Close()
+	}
+	slog.Warn("Lost the connection to RabbitMQ", "error", p.connErr)
+}
 
-	return &RabbitMQPublisher{
-		Channel:      channel,
-		ExchangeName: exchangeName,
+// run publishes the buffered messages one at a time, so that each confirmation
+// belongs to the message published just before it.
+func (p *RabbitMQPublisher) run() {
+	for {
+		select {
+		case m := <-p.pending:
+			m.result <- p.deliver(m)
+		case <-p.done:
+			return
+		}
 	}
 }
 
-// Publish sends the message to the exchange.
-func (p *RabbitMQPublisher) Publish(message []byte) error {
-	return p.Channel.Publish(
-		p.ExchangeName, // exchange
-		"",             // routing key (not used for fanout)
-		false,
-		false,
-		amqp.Publishing{
-			ContentType: "application/json",
-			Body:        message,
-		},
-	)
+// deliver publishes a message and waits for its confirmation, waiting for the
+// connection to come back and publishing again when it is lost on the way.
+// A message may therefore reach the broker twice, but is never lost unreported.
+func (p *RabbitMQPublisher) deliver(m pendingMessage) error {
+	for {
+		if err := m.ctx.Err(); err != nil {
+			return err
+		}
+		p.mu.Lock()
+		if p.connErr == nil && !p.projects[m.project] {
+			if err := p.declareProjectQueue(p.channel, m.project); err != nil {
+				ch := p.channel
+				p.mu.Unlock()
+				logging.FromContext(m.ctx).Warn("Error declaring project queue", "project", m.project, "error", err)
+				p.disconnected(ch, err)
+				continue
+			}
+			p.projects[m.project] = true
+		}
+		ch, confirms, ready, connErr, tag := p.channel, p.confirms, p.ready, p.connErr, p.nextTag
+		if connErr == nil {
+			p.nextTag++
+		}
+		p.mu.Unlock()
+		if connErr != nil {
+			select {
+			case <-ready:
+				continue
+			case <-m.ctx.Done():
+				return fmt.Errorf("%v: %w", connErr, m.ctx.Err())
+			case <-p.done:
+				return ErrPublisherClosed
+			}
+		}
+
+		if err := ch.Publish(p.exchangeName, m.routingKey, false, false, m.publishing); err != nil {
+			p.disconnected(ch, err)
+			continue
+		}
+		confirmed, err := p.awaitConfirm(m.ctx, confirms, tag)
+		if !confirmed {
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		return nil
+	}
+}
+
+// awaitConfirm waits for the confirmation of delivery tag, skipping those of messages
+// abandoned earlier. It reports false without an error when the channel closed first.
+func (p *RabbitMQPublisher) awaitConfirm(ctx context.Context, confirms <-chan amqp.Confirmation, tag uint64) (bool, error) {
+	for {
+		select {
+		case confirm, ok := <-confirms:
+			if !ok {
+				return false, nil
+			}
+			if confirm.DeliveryTag < tag {
+				continue
+			}
+			if !confirm.Ack {
+				return false, ErrPublishRejected
+			}
+			return true, nil
+		case <-ctx.Done():
+			return false, fmt.Errorf("message not confirmed: %w", ctx.Err())
+		}
+	}
+}
+
+// RunRoutingKey returns the routing key of a run: run.<project>.<priority>, with the
+// characters that are special to topic exchanges replaced in the project name.
+func RunRoutingKey(project, priority string) string {
+	return "run." + routingSegment(project) + "." + priority
+}
+
+// routingSegment makes a name usable as a single routing key or queue name segment.
+func routingSegment(name string) string {
+	return strings.NewReplacer(".", "_", "*", "_", "#", "_", " ", "_").Replace(name)
+}
+
+// headerCarrier lets the trace context propagator read and write AMQP message headers.
+type headerCarrier amqp.Table
+
+func (c headerCarrier) Get(key string) string {
+	value, _ := c[key].(string)
+	return value
+}
+
+func (c headerCarrier) Set(key, value string) {
+	c[key] = value
+}
+
+func (c headerCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
 }
@@ -0,0 +1,188 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"service-test-runner/internal/domain"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "test_runner"
+
+var (
+	runsQueued = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "runs_queued_total",
+		Help:      "Runs queued because the runner was busy.",
+	}, []string{"project", "testsuite"})
+
+	runsTriggered = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "runs_triggered_total",
+		Help:      "Runs started on a runner.",
+	}, []string{"project", "testsuite"})
+
+	runsCompleted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "runs_completed_total",
+		Help:      "Runs that reached a terminal status.",
+	}, []string{"project", "testsuite", "status"})
+
+	runDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "run_duration_seconds",
+		Help:      "Time from the creation of a run to its terminal status.",
+		Buckets:   []float64{30, 60, 120, 300, 600, 1200, 1800, 3600, 7200},
+	}, []string{"project", "testsuite", "status"})
+
+	runnerRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "runner_request_duration_seconds",
+		Help:      "Latency of HTTP calls to the Selenium runners.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"project", "operation"})
+
+	runnerRequestErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "runner_request_errors_total",
+		Help:      "HTTP calls to the Selenium runners that failed or returned an unexpected status.",
+	}, []string{"project", "operation"})
+
+	runnerCircuitOpen = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "runner_circuit_open",
+		Help:      "Whether the circuit breaker of a project's runners is open (1) or closed (0).",
+	}, []string{"project"})
+
+	rabbitMQPublishes = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rabbitmq_publishes_total",
+		Help:      "Messages published to RabbitMQ.",
+	})
+
+	rabbitMQPublishFailures = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rabbitmq_publish_failures_total",
+		Help:      "Messages that could not be published to RabbitMQ.",
+	})
+
+	storageUploadBytes = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "storage_upload_bytes",
+		Help:      "Size of the objects uploaded to artifact storage.",
+		Buckets:   prometheus.ExponentialBuckets(1<<10, 4, 10), // 1 KiB to 256 MiB
+	}, []string{"driver"})
+
+	storageUploadDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "storage_upload_duration_seconds",
+		Help:      "Duration of the uploads to artifact storage.",
+		Buckets:   prometheus.ExponentialBuckets(0.01, 3, 10),
+	}, []string{"driver", "result"})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of the HTTP API, by route template.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "code"})
+)
+
+// Handler returns the HTTP handler serving the metrics in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ObserveRunEvent is a run listener counting queued, triggered and completed runs.
+func ObserveRunEvent(event domain.RunEvent) {
+	switch event.Type {
+	case domain.RunEventQueued:
+		runsQueued.WithLabelValues(event.Project, event.Testsuite).Inc()
+	case domain.RunEventStarted:
+		runsTriggered.WithLabelValues(event.Project, event.Testsuite).Inc()
+	case domain.RunEventFinished:
+		status := "failed"
+		if event.Status == domain.RunStatusPassed {
+			status = "passed"
+		}
+		runsCompleted.WithLabelValues(event.Project, event.Testsuite, status).Inc()
+		runDuration.WithLabelValues(event.Project, event.Testsuite, status).Observe(event.OccurredAt.Sub(event.StartedAt).Seconds())
+	}
+}
+
+// RegisterQueueDepth exposes the number of queued runs, read from count on every scrape.
+func RegisterQueueDepth(count func() (int64, error)) {
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "queue_depth",
+		Help:      "Runs waiting in the queue for a free runner.",
+	}, func() float64 {
+		depth, err := count()
+		if err != nil {
+			return -1
+		}
+		return float64(depth)
+	})
+}
+
+// RegisterOutboxDepth exposes the number of queued-run messages not yet confirmed by RabbitMQ.
+func RegisterOutboxDepth(count func() (int64, error)) {
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "outbox_pending",
+		Help:      "Queued-run messages waiting in the outbox to be published to RabbitMQ.",
+	}, func() float64 {
+		pending, err := count()
+		if err != nil {
+			return -1
+		}
+		return float64(pending)
+	})
+}
+
+// ObserveRunnerRequest records the latency of a runner call and counts it as an error
+// when it failed or did not return 200 OK. A 429 response queues the run and is not an error.
+func ObserveRunnerRequest(project, operation string, start time.Time, statusCode int, err error) {
+	runnerRequestDuration.WithLabelValues(project, operation).Observe(time.Since(start).Seconds())
+	if err != nil || (statusCode != http.StatusOK && statusCode != http.StatusTooManyRequests) {
+		runnerRequestErrors.WithLabelValues(project, operation).Inc()
+	}
+}
+
+// SetRunnerCircuitOpen records the state of the circuit breaker of a project's runners.
+func SetRunnerCircuitOpen(project string, open bool) {
+	value := 0.0
+	if open {
+		value = 1
+	}
+	runnerCircuitOpen.WithLabelValues(project).Set(value)
+}
+
+// ObservePublish counts a RabbitMQ publish and its failure.
+func ObservePublish(err error) {
+	rabbitMQPublishes.Inc()
+	if err != nil {
+		rabbitMQPublishFailures.Inc()
+	}
+}
+
+// ObserveUpload records the size and duration of an upload to artifact storage.
+func ObserveUpload(driver string, size int64, start time.Time, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	} else {
+		storageUploadBytes.WithLabelValues(driver).Observe(float64(size))
+	}
+	storageUploadDuration.WithLabelValues(driver, result).Observe(time.Since(start).Seconds())
+}
+
+// ObserveHTTPRequest records the latency of an API request.
+func ObserveHTTPRequest(method, route string, statusCode int, start time.Time) {
+	httpRequestDuration.WithLabelValues(method, route, strconv.Itoa(statusCode)).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,229 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"service-test-runner/internal/domain"
+)
+
+// maxChatFailures bounds the failed scenarios listed in a chat message.
+const maxChatFailures = 10
+
+// ChatSender defines the interface for posting run summaries to chat webhooks.
+type ChatSender interface {
+	Post(ctx context.Context, provider, webhookURL string, summary domain.RunSummary) error
+}
+
+// ChatPoster posts messages to Slack and Microsoft Teams incoming webhooks.
+type ChatPoster struct {
+	client *http.Client
+}
+
+// NewChatPoster creates a new ChatPoster whose requests time out after timeout.
+func NewChatPoster(timeout time.Duration) *ChatPoster {
+	return &ChatPoster{client: &http.Client{Timeout: timeout}}
+}
+
+// Post formats a run summary for the provider and posts it to the incoming webhook URL.
+func (p *ChatPoster) Post(ctx context.Context, provider, webhookURL string, summary domain.RunSummary) error {
+	var message map[string]interface{}
+	switch provider {
+	case domain.ChatProviderSlack:
+		message = SlackMessage(summary)
+	case domain.ChatProviderTeams:
+		message = TeamsMessage(summary)
+	default:
+		return fmt.Errorf("unknown chat provider %q", provider)
+	}
+	body, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s webhook returned %d: %s", provider, resp.StatusCode, strings.TrimSpace(string(detail)))
+	}
+	return nil
+}
+
+// SlackMessage formats a run summary as a Slack Block Kit message.
+func SlackMessage(summary domain.RunSummary) map[string]interface{} {
+	blocks := []interface{}{
+		map[string]interface{}{
+			"type": "header",
+			// Slack rejects a header longer than 150 characters.
+			"text": map[string]interface{}{"type": "plain_text", "text": truncate(chatTitle(summary, true), 150)},
+		},
+		map[string]interface{}{
+			"type": "section",
+			"fields": []interface{}{
+				slackField("Project", summary.Project),
+				slackField("Test suite", summary.Testsuite),
+				slackField("Verdict", chatVerdict(summary)),
+				slackField("Duration", summary.Duration.Round(time.Second).String()),
+			},
+		},
+	}
+	if failures := chatFailures(summary); len(failures) > 0 {
+		lines := []string{"*Failed scenarios*"}
+		for _, failure := range failures {
+			lines = append(lines, "• "+slackEscape(failure))
+		}
+		blocks = append(blocks, map[string]interface{}{
+			"type": "section",
+			"text": map[string]interface{}{"type": "mrkdwn", "text": truncate(strings.Join(lines, "\n"), 3000)},
+		})
+	}
+	if summary.ReportURL != "" {
+		blocks = append(blocks, map[string]interface{}{
+			"type": "actions",
+			"elements": []interface{}{
+				map[string]interface{}{
+					"type": "button",
+					"text": map[string]interface{}{"type": "plain_text", "text": "Open report"},
+					"url":  summary.ReportURL,
+				},
+			},
+		})
+	}
+	blocks = append(blocks, map[string]interface{}{
+		"type": "context",
+		"elements": []interface{}{
+			map[string]interface{}{"type": "mrkdwn", "text": "Reference number " + slackEscape(summary.ReferenceNumber)},
+		},
+	})
+
+	return map[string]interface{}{
+		"text":   chatTitle(summary, false),
+		"blocks": blocks,
+	}
+}
+
+// TeamsMessage formats a run summary as a Microsoft Teams message carrying an Adaptive Card.
+func TeamsMessage(summary domain.RunSummary) map[string]interface{} {
+	color := "Good"
+	if summary.Verdict == "FAILED" {
+		color = "Attention"
+	}
+	body := []interface{}{
+		map[string]interface{}{
+			"type":   "TextBlock",
+			"size":   "Large",
+			"weight": "Bolder",
+			"color":  color,
+			"text":   chatTitle(summary, false),
+			"wrap":   true,
+		},
+		map[string]interface{}{
+			"type": "FactSet",
+			"facts": []interface{}{
+				map[string]interface{}{"title": "Project", "value": summary.Project},
+				map[string]interface{}{"title": "Test suite", "value": summary.Testsuite},
+				map[string]interface{}{"title": "Verdict", "value": chatVerdict(summary)},
+				map[string]interface{}{"title": "Duration", "value": summary.Duration.Round(time.Second).String()},
+				map[string]interface{}{"title": "Reference number", "value": summary.ReferenceNumber},
+			},
+		},
+	}
+	if failures := chatFailures(summary); len(failures) > 0 {
+		lines := make([]string, 0, len(failures))
+		for _, failure := range failures {
+			lines = append(lines, "- "+failure)
+		}
+		body = append(body,
+			map[string]interface{}{"type": "TextBlock", "weight": "Bolder", "text": "Failed scenarios"},
+			map[string]interface{}{"type": "TextBlock", "text": strings.Join(lines, "\n"), "wrap": true},
+		)
+	}
+	card := map[string]interface{}{
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"type":    "AdaptiveCard",
+		"version": "1.4",
+		"body":    body,
+	}
+	if summary.ReportURL != "" {
+		card["actions"] = []interface{}{
+			map[string]interface{}{"type": "Action.OpenUrl", "title": "Open report", "url": summary.ReportURL},
+		}
+	}
+
+	return map[string]interface{}{
+		"type": "message",
+		"attachments": []interface{}{
+			map[string]interface{}{
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"content":     card,
+			},
+		},
+	}
+}
+
+func chatVerdict(summary domain.RunSummary) string {
+	if summary.Recovered {
+		return "RECOVERED"
+	}
+	return summary.Verdict
+}
+
+func chatTitle(summary domain.RunSummary, emoji bool) string {
+	title := fmt.Sprintf("%s: %s / %s", chatVerdict(summary), summary.Project, summary.Testsuite)
+	if !emoji {
+		return title
+	}
+	if summary.Verdict == "FAILED" {
+		return ":x: " + title
+	}
+	return ":white_check_mark: " + title
+}
+
+// chatFailures lists the failed scenarios of a summary, noting how many were left out.
+func chatFailures(summary domain.RunSummary) []string {
+	total := len(summary.FailedSteps)
+	if summary.Failed > total {
+		total = summary.Failed
+	}
+	var failures []string
+	for i, step := range summary.FailedSteps {
+		if i == maxChatFailures {
+			failures = append(failures, fmt.Sprintf("... and %d more", total-maxChatFailures))
+			break
+		}
+		failures = append(failures, step.Name)
+	}
+	return failures
+}
+
+// slackEscape escapes the characters Slack treats as control sequences in mrkdwn text.
+func slackEscape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+func slackField(title, value string) map[string]interface{} {
+	return map[string]interface{}{"type": "mrkdwn", "text": "*" + title + "*\n" + slackEscape(value)}
+}
+
+func truncate(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max-3]) + "..."
+}
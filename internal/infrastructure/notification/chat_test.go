@@ -0,0 +1,27 @@
+package notification
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"service-test-runner/internal/domain"
+)
+
+func TestSlackMessageTruncatesHeader(t *testing.T) {
+	message := SlackMessage(domain.RunSummary{
+		ReferenceNumber: "ref-1",
+		Project:         "web1",
+		Testsuite:       strings.Repeat("checkout-", 30),
+		Verdict:         "FAILED",
+	})
+
+	header := message["blocks"].([]interface{})[0].(map[string]interface{})
+	text := header["text"].(map[string]interface{})["text"].(string)
+	if n := utf8.RuneCountInString(text); n > 150 {
+		t.Fatalf("header is %d characters long, Slack allows 150: %q", n, text)
+	}
+	if !strings.HasPrefix(text, ":x: FAILED") || !strings.HasSuffix(text, "...") {
+		t.Fatalf("header = %q", text)
+	}
+}
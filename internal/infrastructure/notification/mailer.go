@@ -0,0 +1,57 @@
+package notification
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"service-test-runner/internal/config"
+)
+
+// Mailer defines the interface for sending notification emails.
+type Mailer interface {
+	Send(to []string, subject, htmlBody string) error
+}
+
+// SMTPMailer sends HTML emails through an SMTP server. STARTTLS is used when the
+// server offers it, and authentication only when a username is configured.
+type SMTPMailer struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+// NewSMTPMailer creates a new SMTPMailer from the email notification settings.
+func NewSMTPMailer(cfg config.EmailConfig) *SMTPMailer {
+	mailer := &SMTPMailer{
+		addr: net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		from: cfg.From,
+	}
+	if cfg.Username != "" {
+		mailer.auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+	return mailer
+}
+
+// Send delivers an HTML email to the given recipients.
+func (m *SMTPMailer) Send(to []string, subject, htmlBody string) error {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", m.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/html; charset=UTF-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(htmlBody)
+
+	if err := smtp.SendMail(m.addr, m.auth, m.from, to, msg.Bytes()); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
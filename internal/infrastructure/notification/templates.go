@@ -0,0 +1,61 @@
+package notification
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"os"
+	"time"
+
+	"service-test-runner/internal/domain"
+)
+
+//go:embed templates/*.html
+var builtinTemplates embed.FS
+
+// runFinishedTemplate is the email template rendered when a run finishes.
+const runFinishedTemplate = "run_finished.html"
+
+// Templates renders notification emails from HTML templates.
+type Templates struct {
+	tmpl *template.Template
+}
+
+var templateFuncs = template.FuncMap{
+	"duration": func(d time.Duration) string {
+		return d.Round(time.Second).String()
+	},
+	"datetime": func(t time.Time) string {
+		return t.Format("2006-01-02 15:04:05 MST")
+	},
+}
+
+// NewTemplates loads the notification templates. When dir is empty the built-in
+// templates are used; otherwise the files in dir replace them.
+func NewTemplates(dir string) (*Templates, error) {
+	tmpl := template.New("").Funcs(templateFuncs)
+	var err error
+	if dir == "" {
+		tmpl, err = tmpl.ParseFS(builtinTemplates, "templates/*.html")
+	} else {
+		tmpl, err = tmpl.ParseFS(os.DirFS(dir), "*.html")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse notification templates: %w", err)
+	}
+	if tmpl.Lookup(runFinishedTemplate) == nil {
+		return nil, fmt.Errorf("notification template %s not found", runFinishedTemplate)
+	}
+	return &Templates{tmpl: tmpl}, nil
+}
+
+// RunFinished renders the subject and HTML body of a run summary email.
+func (t *Templates) RunFinished(summary domain.RunSummary) (string, string, error) {
+	var body bytes.Buffer
+	if err := t.tmpl.ExecuteTemplate(&body, runFinishedTemplate, summary); err != nil {
+		return "", "", err
+	}
+	subject := fmt.Sprintf("[%s] %s / %s (%s)", summary.Verdict, summary.Project, summary.Testsuite, summary.ReferenceNumber)
+	return subject, body.String(), nil
+}
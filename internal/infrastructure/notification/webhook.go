@@ -0,0 +1,152 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// maxWebhookResponseSize bounds the part of a webhook response kept in the delivery log.
+const maxWebhookResponseSize = 4 << 10
+
+// ErrForbiddenTarget is returned for webhook URLs pointing at a loopback, private,
+// link-local or unspecified address outside of the allowed networks.
+var ErrForbiddenTarget = errors.New("webhook target address not allowed")
+
+// WebhookTargets decides which addresses webhooks may be delivered to: public ones,
+// and the internal ones within the allowed networks.
+type WebhookTargets struct {
+	allowed []*net.IPNet
+}
+
+// NewWebhookTargets creates the target policy from the CIDRs of the internal networks
+// webhooks may be delivered to.
+func NewWebhookTargets(allowedNetworks []string) (*WebhookTargets, error) {
+	targets := &WebhookTargets{}
+	for _, cidr := range allowedNetworks {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed webhook network %q: %v", cidr, err)
+		}
+		targets.allowed = append(targets.allowed, network)
+	}
+	return targets, nil
+}
+
+// Allowed reports whether webhooks may be delivered to ip.
+func (t *WebhookTargets) Allowed(ip net.IP) bool {
+	for _, network := range t.allowed {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsUnspecified())
+}
+
+// Check returns ErrForbiddenTarget when the host of a webhook URL is, or resolves to,
+// an address webhooks may not be delivered to. A host that cannot be resolved yet is
+// accepted, as every delivery checks the address it connects to again.
+func (t *WebhookTargets) Check(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	host := u.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		if !t.Allowed(ip) {
+			return fmt.Errorf("%w: %s", ErrForbiddenTarget, host)
+		}
+		return nil
+	}
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		if !t.Allowed(addr.IP) {
+			return fmt.Errorf("%w: %s resolves to %s", ErrForbiddenTarget, host, addr.IP)
+		}
+	}
+	return nil
+}
+
+// control refuses connections to addresses webhooks may not be delivered to. Checking
+// the address dialed, rather than the URL, also covers redirects and DNS answers that
+// changed since the webhook was created.
+func (t *WebhookTargets) control(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !t.Allowed(ip) {
+		return fmt.Errorf("%w: %s", ErrForbiddenTarget, host)
+	}
+	return nil
+}
+
+// WebhookDeliverer defines the interface for delivering webhook payloads.
+type WebhookDeliverer interface {
+	Send(ctx context.Context, url, secret, event string, deliveryID uint, payload []byte) (int, string, error)
+}
+
+// WebhookSender posts signed JSON payloads to webhook URLs.
+type WebhookSender struct {
+	client *http.Client
+}
+
+// NewWebhookSender creates a new WebhookSender whose requests time out after timeout
+// and only connect to the addresses targets allows.
+func NewWebhookSender(timeout time.Duration, targets *WebhookTargets) *WebhookSender {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = (&net.Dialer{Timeout: timeout, Control: targets.control}).DialContext
+	return &WebhookSender{client: &http.Client{Timeout: timeout, Transport: transport}}
+}
+
+// Send posts a payload to a webhook and returns the response status code and the
+// beginning of the response body. The X-Webhook-Timestamp header carries the Unix
+// time of the attempt and X-Webhook-Signature-256 its signature together with the
+// payload, so a receiver can refuse old or replayed deliveries.
+func (s *WebhookSender) Send(ctx context.Context, url, secret, event string, deliveryID uint, payload []byte) (int, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "service-test-runner-webhooks")
+	req.Header.Set("X-Webhook-Event", event)
+	req.Header.Set("X-Webhook-Delivery", strconv.FormatUint(uint64(deliveryID), 10))
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature-256", SignPayload(secret, timestamp, payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxWebhookResponseSize))
+	return resp.StatusCode, string(body), nil
+}
+
+// SignPayload returns the signature header value of a payload sent at timestamp:
+// "sha256=" followed by the hex encoded HMAC-SHA256 of timestamp + "." + payload
+// keyed with secret.
+func SignPayload(secret, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,106 @@
+package notification
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSendSignsTimestampAndBody(t *testing.T) {
+	payload := []byte(`{"event":"run.finished"}`)
+	var header http.Header
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	// The test server listens on the loopback interface.
+	targets, err := NewWebhookTargets([]string{"127.0.0.0/8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	status, _, err := NewWebhookSender(time.Second, targets).Send(context.Background(), server.URL, "secret", "run.finished", 7, payload)
+	if err != nil || status != http.StatusNoContent {
+		t.Fatalf("Send = %d, %v", status, err)
+	}
+
+	timestamp := header.Get("X-Webhook-Timestamp")
+	sent, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || time.Since(time.Unix(sent, 0)) > time.Minute {
+		t.Fatalf("X-Webhook-Timestamp = %q", timestamp)
+	}
+	// Verify the way a receiver would.
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(timestamp + "." + string(body)))
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if got := header.Get("X-Webhook-Signature-256"); !hmac.Equal([]byte(got), []byte(want)) {
+		t.Fatalf("X-Webhook-Signature-256 = %q, want %q", got, want)
+	}
+	if SignPayload("secret", "1700000000", payload) == SignPayload("secret", "1700000001", payload) {
+		t.Fatal("the signature does not depend on the timestamp")
+	}
+}
+
+func TestWebhookTargets(t *testing.T) {
+	targets, err := NewWebhookTargets([]string{"10.20.0.0/16"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://203.0.113.10/hook", true},
+		{"https://10.20.3.4/hook", true},
+		{"http://127.0.0.1:8080/hook", false},
+		{"http://localhost/hook", false},
+		{"http://10.1.2.3/hook", false},
+		{"http://192.168.1.1/hook", false},
+		{"http://169.254.169.254/latest/meta-data", false},
+		{"http://0.0.0.0/hook", false},
+		{"http://[::1]/hook", false},
+		{"http://[fe80::1]/hook", false},
+		{"http://[fd00::1]/hook", false},
+	}
+	for _, tt := range tests {
+		err := targets.Check(context.Background(), tt.url)
+		if got := err == nil; got != tt.want {
+			t.Errorf("Check(%s) = %v, want allowed %v", tt.url, err, tt.want)
+		}
+		if err != nil && !errors.Is(err, ErrForbiddenTarget) {
+			t.Errorf("Check(%s) = %v, want ErrForbiddenTarget", tt.url, err)
+		}
+	}
+
+	if _, err := NewWebhookTargets([]string{"10.20.0.0"}); err == nil {
+		t.Fatal("NewWebhookTargets accepted a network without a prefix length")
+	}
+}
+
+func TestSendRefusesInternalAddresses(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	targets, err := NewWebhookTargets(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = NewWebhookSender(time.Second, targets).Send(context.Background(), server.URL, "secret", "run.finished", 7, []byte(`{}`))
+	if !errors.Is(err, ErrForbiddenTarget) || called {
+		t.Fatalf("Send to a loopback address = %v, called %v; want ErrForbiddenTarget", err, called)
+	}
+}
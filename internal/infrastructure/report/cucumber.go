@@ -0,0 +1,185 @@
+package report
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"service-test-runner/internal/domain"
+)
+
+// Embedding is an attachment (typically a screenshot) extracted from a Cucumber report.
+type Embedding struct {
+	Feature  string
+	Scenario string
+	Step     string
+	MimeType string
+	Data     []byte
+}
+
+type cucumberFeature struct {
+	URI      string            `json:"uri"`
+	Name     string            `json:"name"`
+	Elements []cucumberElement `json:"elements"`
+}
+
+type cucumberElement struct {
+	Name   string         `json:"name"`
+	Type   string         `json:"type"`
+	Before []cucumberStep `json:"before"`
+	Steps  []cucumberStep `json:"steps"`
+	After  []cucumberStep `json:"after"`
+}
+
+type cucumberStep struct {
+	Keyword    string              `json:"keyword"`
+	Name       string              `json:"name"`
+	Result     cucumberResult      `json:"result"`
+	Embeddings []cucumberEmbedding `json:"embeddings"`
+}
+
+type cucumberResult struct {
+	Status       string      `json:"status"`
+	Duration     json.Number `json:"duration"`
+	ErrorMessage string      `json:"error_message"`
+}
+
+type cucumberEmbedding struct {
+	MimeType string `json:"mime_type"`
+	Media    struct {
+		Type string `json:"type"`
+	} `json:"media"`
+	Data string `json:"data"`
+}
+
+// ParseCucumber parses a Cucumber JSON report into one suite per feature and one
+// test case per scenario, with the step results attached to each scenario.
+// Background steps are merged into the scenario that follows them, and every
+// embedding of a step or hook is returned alongside the results.
+func ParseCucumber(r io.Reader) ([]domain.TestSuiteResult, []Embedding, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+	var features []cucumberFeature
+	if err := decoder.Decode(&features); err != nil {
+		return nil, nil, fmt.Errorf("%w (cucumber): %v", ErrInvalidReport, err)
+	}
+
+	var suites []domain.TestSuiteResult
+	var embeddings []Embedding
+	for _, feature := range features {
+		suite := domain.TestSuiteResult{Name: feature.Name}
+		var background []cucumberStep
+		for _, element := range feature.Elements {
+			if element.Type == "background" {
+				background = element.Steps
+				continue
+			}
+
+			tc := domain.TestCaseResult{
+				Name:      element.Name,
+				ClassName: feature.URI,
+			}
+			var hookFailure string
+			steps := append(append([]cucumberStep{}, background...), element.Steps...)
+			for _, step := range steps {
+				tc.Steps = append(tc.Steps, domain.StepResult{
+					Keyword:      strings.TrimSpace(step.Keyword),
+					Name:         step.Name,
+					Status:       step.Result.Status,
+					Duration:     parseCucumberDuration(step.Result.Duration),
+					ErrorMessage: step.Result.ErrorMessage,
+				})
+			}
+			for _, hook := range append(append([]cucumberStep{}, element.Before...), element.After...) {
+				if hook.Result.Status == "failed" && hookFailure == "" {
+					hookFailure = hook.Result.ErrorMessage
+				}
+			}
+
+			// Hook embeddings are attributed to the last step that ran before them.
+			lastStep := ""
+			for _, step := range element.Before {
+				embeddings = appendEmbeddings(embeddings, feature.Name, element.Name, "", step.Embeddings)
+			}
+			for _, step := range steps {
+				if step.Result.Status != "skipped" {
+					lastStep = step.Name
+				}
+				embeddings = appendEmbeddings(embeddings, feature.Name, element.Name, step.Name, step.Embeddings)
+			}
+			for _, step := range element.After {
+				embeddings = appendEmbeddings(embeddings, feature.Name, element.Name, lastStep, step.Embeddings)
+			}
+
+			tc.Status, tc.FailureMessage = scenarioStatus(tc.Steps, hookFailure)
+			for _, step := range tc.Steps {
+				tc.Duration += step.Duration
+			}
+			suite.Duration += tc.Duration
+			suite.Cases = append(suite.Cases, tc)
+		}
+		suites = append(suites, CountSuite(suite))
+	}
+	return suites, embeddings, nil
+}
+
+// scenarioStatus derives a test case status from its step results: any failed step
+// fails the scenario, undefined, pending or ambiguous steps make it an error, and a
+// scenario whose steps were all skipped is skipped.
+func scenarioStatus(steps []domain.StepResult, hookFailure string) (string, string) {
+	status, message := domain.TestStatusSkipped, ""
+	for _, step := range steps {
+		switch step.Status {
+		case "failed":
+			return domain.TestStatusFailed, step.ErrorMessage
+		case "undefined", "pending", "ambiguous":
+			if status != domain.TestStatusError {
+				status, message = domain.TestStatusError, fmt.Sprintf("step %q is %s", step.Name, step.Status)
+			}
+		case "passed":
+			if status == domain.TestStatusSkipped {
+				status = domain.TestStatusPassed
+			}
+		}
+	}
+	if hookFailure != "" {
+		return domain.TestStatusFailed, hookFailure
+	}
+	return status, message
+}
+
+func appendEmbeddings(embeddings []Embedding, feature, scenario, step string, raw []cucumberEmbedding) []Embedding {
+	for _, e := range raw {
+		mimeType := e.MimeType
+		if mimeType == "" {
+			mimeType = e.Media.Type
+		}
+		data, err := base64.StdEncoding.DecodeString(e.Data)
+		if err != nil {
+			continue
+		}
+		embeddings = append(embeddings, Embedding{
+			Feature:  feature,
+			Scenario: scenario,
+			Step:     step,
+			MimeType: mimeType,
+			Data:     data,
+		})
+	}
+	return embeddings
+}
+
+// parseCucumberDuration returns a step duration in seconds. Cucumber reports integer
+// nanoseconds while behave reports fractional seconds.
+func parseCucumberDuration(value json.Number) float64 {
+	duration, err := value.Float64()
+	if err != nil {
+		return 0
+	}
+	if strings.ContainsAny(value.String(), ".eE") {
+		return duration
+	}
+	return duration / 1e9
+}
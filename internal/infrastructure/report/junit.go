@@ -0,0 +1,211 @@
+package report
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"service-test-runner/internal/domain"
+)
+
+// ErrInvalidReport is returned when an uploaded test report cannot be parsed.
+var ErrInvalidReport = errors.New("invalid report")
+
+// junitSuite mirrors a <testsuite> element. Suites may be nested.
+type junitSuite struct {
+	Name   string       `xml:"name,attr"`
+	Time   string       `xml:"time,attr"`
+	Suites []junitSuite `xml:"testsuite"`
+	Cases  []junitCase  `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitProblem `xml:"failure"`
+	Error     *junitProblem `xml:"error"`
+	Skipped   *junitProblem `xml:"skipped"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// xunitAssembly mirrors an <assembly> element of the xUnit.net v2 format.
+type xunitAssembly struct {
+	Name        string            `xml:"name,attr"`
+	Collections []xunitCollection `xml:"collection"`
+}
+
+type xunitCollection struct {
+	Name  string      `xml:"name,attr"`
+	Tests []xunitTest `xml:"test"`
+}
+
+type xunitTest struct {
+	Name    string `xml:"name,attr"`
+	Type    string `xml:"type,attr"`
+	Time    string `xml:"time,attr"`
+	Result  string `xml:"result,attr"`
+	Reason  string `xml:"reason"`
+	Failure *struct {
+		Message    string `xml:"message"`
+		StackTrace string `xml:"stack-trace"`
+	} `xml:"failure"`
+}
+
+// ParseJUnit parses a JUnit XML (<testsuites> or <testsuite> root) or an
+// xUnit.net v2 XML (<assemblies> or <assembly> root) report into suite results.
+func ParseJUnit(r io.Reader) ([]domain.TestSuiteResult, error) {
+	decoder := xml.NewDecoder(r)
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, fmt.Errorf("%w (junit): %v", ErrInvalidReport, err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "testsuites":
+			var root struct {
+				Suites []junitSuite `xml:"testsuite"`
+			}
+			if err := decoder.DecodeElement(&root, &start); err != nil {
+				return nil, fmt.Errorf("%w (junit): %v", ErrInvalidReport, err)
+			}
+			return convertJUnitSuites(root.Suites, ""), nil
+		case "testsuite":
+			var suite junitSuite
+			if err := decoder.DecodeElement(&suite, &start); err != nil {
+				return nil, fmt.Errorf("%w (junit): %v", ErrInvalidReport, err)
+			}
+			return convertJUnitSuites([]junitSuite{suite}, ""), nil
+		case "assemblies":
+			var root struct {
+				Assemblies []xunitAssembly `xml:"assembly"`
+			}
+			if err := decoder.DecodeElement(&root, &start); err != nil {
+				return nil, fmt.Errorf("%w (xunit): %v", ErrInvalidReport, err)
+			}
+			return convertXUnitAssemblies(root.Assemblies), nil
+		case "assembly":
+			var assembly xunitAssembly
+			if err := decoder.DecodeElement(&assembly, &start); err != nil {
+				return nil, fmt.Errorf("%w (xunit): %v", ErrInvalidReport, err)
+			}
+			return convertXUnitAssemblies([]xunitAssembly{assembly}), nil
+		default:
+			return nil, fmt.Errorf("%w (junit): unexpected root element <%s>", ErrInvalidReport, start.Name.Local)
+		}
+	}
+}
+
+// convertJUnitSuites flattens nested suites, joining their names with a dot.
+func convertJUnitSuites(suites []junitSuite, parent string) []domain.TestSuiteResult {
+	var results []domain.TestSuiteResult
+	for _, s := range suites {
+		name := s.Name
+		if parent != "" {
+			name = parent + "." + name
+		}
+		if len(s.Cases) > 0 {
+			result := domain.TestSuiteResult{Name: name}
+			for _, c := range s.Cases {
+				tc := domain.TestCaseResult{
+					Name:      c.Name,
+					ClassName: c.ClassName,
+					Status:    domain.TestStatusPassed,
+					Duration:  parseSeconds(c.Time),
+				}
+				switch {
+				case c.Error != nil:
+					tc.Status = domain.TestStatusError
+					tc.FailureMessage = c.Error.Message
+					tc.StackTrace = strings.TrimSpace(c.Error.Body)
+				case c.Failure != nil:
+					tc.Status = domain.TestStatusFailed
+					tc.FailureMessage = c.Failure.Message
+					tc.StackTrace = strings.TrimSpace(c.Failure.Body)
+				case c.Skipped != nil:
+					tc.Status = domain.TestStatusSkipped
+					tc.FailureMessage = c.Skipped.Message
+				}
+				result.Cases = append(result.Cases, tc)
+			}
+			if result.Duration = parseSeconds(s.Time); result.Duration == 0 {
+				for _, tc := range result.Cases {
+					result.Duration += tc.Duration
+				}
+			}
+			results = append(results, CountSuite(result))
+		}
+		results = append(results, convertJUnitSuites(s.Suites, name)...)
+	}
+	return results
+}
+
+// convertXUnitAssemblies maps each xUnit.net test collection onto a suite.
+func convertXUnitAssemblies(assemblies []xunitAssembly) []domain.TestSuiteResult {
+	var results []domain.TestSuiteResult
+	for _, a := range assemblies {
+		for _, c := range a.Collections {
+			result := domain.TestSuiteResult{Name: c.Name}
+			for _, t := range c.Tests {
+				tc := domain.TestCaseResult{
+					Name:      t.Name,
+					ClassName: t.Type,
+					Status:    domain.TestStatusPassed,
+					Duration:  parseSeconds(t.Time),
+				}
+				switch strings.ToLower(t.Result) {
+				case "fail":
+					tc.Status = domain.TestStatusFailed
+					if t.Failure != nil {
+						tc.FailureMessage = strings.TrimSpace(t.Failure.Message)
+						tc.StackTrace = strings.TrimSpace(t.Failure.StackTrace)
+					}
+				case "skip":
+					tc.Status = domain.TestStatusSkipped
+					tc.FailureMessage = strings.TrimSpace(t.Reason)
+				}
+				result.Duration += tc.Duration
+				result.Cases = append(result.Cases, tc)
+			}
+			results = append(results, CountSuite(result))
+		}
+	}
+	return results
+}
+
+// CountSuite fills in the test, failure, error and skipped counts of a suite from its cases.
+func CountSuite(suite domain.TestSuiteResult) domain.TestSuiteResult {
+	suite.Tests, suite.Failures, suite.Errors, suite.Skipped = len(suite.Cases), 0, 0, 0
+	for _, tc := range suite.Cases {
+		switch tc.Status {
+		case domain.TestStatusFailed:
+			suite.Failures++
+		case domain.TestStatusError:
+			suite.Errors++
+		case domain.TestStatusSkipped:
+			suite.Skipped++
+		}
+	}
+	return suite
+}
+
+// parseSeconds parses a JUnit time attribute, tolerating thousands separators.
+func parseSeconds(value string) float64 {
+	seconds, err := strconv.ParseFloat(strings.ReplaceAll(strings.TrimSpace(value), ",", ""), 64)
+	if err != nil {
+		return 0
+	}
+	return seconds
+}
@@ -0,0 +1,222 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"service-test-runner/internal/config"
+	"service-test-runner/internal/infrastructure/metrics"
+)
+
+// defaultSigningKey is the placeholder signing key of config.json, refused so that
+// signed URLs cannot be forged with a published key.
+const defaultSigningKey = "change-me"
+
+// LocalStore is the ArtifactStore backed by a directory on the local filesystem.
+// Its signed URLs point at this service's /storage/ route, which verifies them.
+type LocalStore struct {
+	root       string
+	baseURL    string
+	signingKey []byte
+	urlExpiry  time.Duration
+}
+
+// NewLocalStore creates the root directory if needed and returns a LocalStore on it.
+func NewLocalStore(cfg *config.LocalStorageConfig) (*LocalStore, error) {
+	if cfg.Path == "" {
+		return nil, errors.New("storage.local.path is required")
+	}
+	if cfg.SigningKey == "" || cfg.SigningKey == defaultSigningKey {
+		return nil, errors.New("storage.local.signing_key must be set to a secret of your own")
+	}
+	if err := os.MkdirAll(cfg.Path, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %v", err)
+	}
+	return &LocalStore{
+		root:       cfg.Path,
+		baseURL:    strings.TrimSuffix(cfg.BaseURL, "/"),
+		signingKey: []byte(cfg.SigningKey),
+		urlExpiry:  cfg.URLExpiry,
+	}, nil
+}
+
+// Put writes the content of reader to a temporary file and renames it into place,
+// so readers never see a partially written object.
+func (s *LocalStore) Put(_ context.Context, objectName string, reader io.Reader, size int64, contentType string) error {
+	target := s.path(objectName)
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return fmt.Errorf("failed to upload file: %v", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to upload file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	start := time.Now()
+	written, err := io.Copy(tmp, reader)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	metrics.ObserveUpload("local", written, start, err)
+	if err != nil {
+		return fmt.Errorf("failed to upload file: %w", err)
+	}
+	if size >= 0 && written != size {
+		return fmt.Errorf("failed to upload file: wrote %d of %d bytes", written, size)
+	}
+	if err := os.Rename(tmp.Name(), target); err != nil {
+		return fmt.Errorf("failed to upload file: %v", err)
+	}
+	return nil
+}
+
+// Get opens the object for reading. The caller must close the returned reader.
+func (s *LocalStore) Get(_ context.Context, objectName string) (io.ReadCloser, ObjectInfo, error) {
+	file, err := os.Open(s.path(objectName))
+	if err != nil {
+		return nil, ObjectInfo{}, s.wrapError(err)
+	}
+	stat, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, ObjectInfo{}, s.wrapError(err)
+	}
+	return file, s.toObjectInfo(objectName, stat), nil
+}
+
+// Stat returns the metadata of the object.
+func (s *LocalStore) Stat(_ context.Context, objectName string) (ObjectInfo, error) {
+	stat, err := os.Stat(s.path(objectName))
+	if err != nil {
+		return ObjectInfo{}, s.wrapError(err)
+	}
+	return s.toObjectInfo(objectName, stat), nil
+}
+
+// Delete removes the object. Deleting a missing object is not an error.
+func (s *LocalStore) Delete(_ context.Context, objectName string) error {
+	if err := os.Remove(s.path(objectName)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete file: %v", err)
+	}
+	return nil
+}
+
+// Ping checks that the root directory still exists.
+func (s *LocalStore) Ping(_ context.Context) error {
+	info, err := os.Stat(s.root)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", s.root)
+	}
+	return nil
+}
+
+// List returns every object whose name starts with prefix.
+func (s *LocalStore) List(_ context.Context, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	err := filepath.WalkDir(s.root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".upload-") {
+			return nil
+		}
+		rel, err := filepath.Rel(s.root, p)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if !strings.HasPrefix(name, prefix) {
+			return nil
+		}
+		stat, err := d.Info()
+		if err != nil {
+			return err
+		}
+		objects = append(objects, s.toObjectInfo(name, stat))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files: %v", err)
+	}
+	return objects, nil
+}
+
+// SignedURL returns a URL on this service's /storage/ route carrying an expiry
+// and an HMAC-SHA256 signature over the object name and expiry.
+func (s *LocalStore) SignedURL(_ context.Context, objectName string) (string, error) {
+	expires := time.Now().Add(s.urlExpiry).Unix()
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", s.sign(objectName, expires))
+	return fmt.Sprintf("%s/storage/%s?%s", s.baseURL, escapeObjectName(objectName), query.Encode()), nil
+}
+
+// escapeObjectName escapes each segment of an object name for use as a URL path, so
+// names holding '?', '#', '%' or spaces come back unchanged once the path is decoded.
+func escapeObjectName(objectName string) string {
+	segments := strings.Split(objectName, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// VerifySignedURL checks the expiry and signature of a URL produced by SignedURL.
+func (s *LocalStore) VerifySignedURL(objectName string, expires int64, signature string) error {
+	if time.Now().Unix() > expires {
+		return errors.New("signed URL has expired")
+	}
+	if !hmac.Equal([]byte(s.sign(objectName, expires)), []byte(signature)) {
+		return errors.New("invalid signature")
+	}
+	return nil
+}
+
+func (s *LocalStore) sign(objectName string, expires int64) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	fmt.Fprintf(mac, "%s\n%d", objectName, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// path maps an object name onto the filesystem, keeping it inside the root directory.
+func (s *LocalStore) path(objectName string) string {
+	return filepath.Join(s.root, filepath.FromSlash(path.Clean("/"+objectName)))
+}
+
+func (s *LocalStore) wrapError(err error) error {
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrObjectNotFound
+	}
+	return err
+}
+
+func (s *LocalStore) toObjectInfo(objectName string, stat fs.FileInfo) ObjectInfo {
+	contentType := mime.TypeByExtension(path.Ext(objectName))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	return ObjectInfo{
+		Name:         objectName,
+		Size:         stat.Size(),
+		ContentType:  contentType,
+		LastModified: stat.ModTime(),
+	}
+}
@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"service-test-runner/internal/config"
+)
+
+func TestNewLocalStoreRefusesDefaultKey(t *testing.T) {
+	for _, key := range []string{"", defaultSigningKey} {
+		_, err := NewLocalStore(&config.LocalStorageConfig{Path: t.TempDir(), SigningKey: key})
+		if err == nil {
+			t.Errorf("NewLocalStore accepted the signing key %q", key)
+		}
+	}
+}
+
+func TestSignedURLRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocalStore(&config.LocalStorageConfig{
+		Path:       t.TempDir(),
+		BaseURL:    "http://localhost:6000/",
+		SigningKey: "test-key",
+		URLExpiry:  time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{
+		"reports/ref-1/report.pdf",
+		"reports/ref 1/what?#50%.log",
+	} {
+		if err := store.Put(ctx, name, strings.NewReader("content"), -1, "text/plain"); err != nil {
+			t.Fatal(err)
+		}
+		signed, err := store.SignedURL(ctx, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		u, err := url.Parse(signed)
+		if err != nil {
+			t.Fatalf("SignedURL(%q) = %q: %v", name, signed, err)
+		}
+		// The router matches the decoded path, as the download handler sees it.
+		objectName := strings.TrimPrefix(u.Path, "/storage/")
+		if objectName != name {
+			t.Fatalf("SignedURL(%q) decodes to %q", name, objectName)
+		}
+		expires, err := strconv.ParseInt(u.Query().Get("expires"), 10, 64)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := store.VerifySignedURL(objectName, expires, u.Query().Get("signature")); err != nil {
+			t.Fatalf("VerifySignedURL(%q): %v", name, err)
+		}
+		if err := store.VerifySignedURL(objectName+"x", expires, u.Query().Get("signature")); err == nil {
+			t.Fatalf("VerifySignedURL accepted another object name")
+		}
+	}
+}
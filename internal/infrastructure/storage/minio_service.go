@@ -1,19 +1,31 @@
 package storage
 
 import (
-	"bytes"
 	"context"
 	"fmt"
+	"io"
+	"net/url"
 	"service-test-runner/internal/config"
+	"service-test-runner/internal/infrastructure/metrics"
+	"service-test-runner/internal/infrastructure/tracing"
+	"time"
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// minioPartSize is the part size of multipart uploads. Without it minio-go sizes the
+// parts of an upload of unknown size for the largest possible object (about 560 MiB)
+// and buffers a whole part in memory.
+const minioPartSize = 16 << 20
+
+// MinioService is the ArtifactStore backed by a MinIO (or any S3 compatible) bucket.
 type MinioService struct {
 	client     *minio.Client
 	bucketName string
-	endpoint   string
+	urlExpiry  time.Duration
 }
 
 func NewMinioService(cfg *config.MinIOConfig) (*MinioService, error) {
@@ -42,27 +54,113 @@ func NewMinioService(cfg *config.MinIOConfig) (*MinioService, error) {
 	return &MinioService{
 		client:     client,
 		bucketName: cfg.BucketName,
-		endpoint:   cfg.Endpoint,
+		urlExpiry:  cfg.URLExpiry,
 	}, nil
 }
 
-func (s *MinioService) UploadPDF(objectName string, fileBytes []byte) error {
-	reader := bytes.NewReader(fileBytes)
-	_, err := s.client.PutObject(
-		context.Background(),
+// Put stores the content of reader under objectName. A size of -1 means unknown.
+func (s *MinioService) Put(ctx context.Context, objectName string, reader io.Reader, size int64, contentType string) (err error) {
+	ctx, span := tracing.Start(ctx, "minio put", trace.SpanKindClient,
+		attribute.String("storage.bucket", s.bucketName),
+		attribute.String("storage.object", objectName))
+	defer func() { tracing.End(span, err) }()
+
+	start := time.Now()
+	info, err := s.client.PutObject(
+		ctx,
 		s.bucketName,
 		objectName,
 		reader,
-		int64(len(fileBytes)),
-		minio.PutObjectOptions{ContentType: "application/pdf"},
+		size,
+		minio.PutObjectOptions{ContentType: contentType, PartSize: minioPartSize},
 	)
+	metrics.ObserveUpload("minio", info.Size, start, err)
+	span.SetAttributes(attribute.Int64("storage.size", info.Size))
+	if err != nil {
+		return fmt.Errorf("failed to upload file: %w", err)
+	}
+	return nil
+}
+
+// Get opens the object for reading. The caller must close the returned reader.
+func (s *MinioService) Get(ctx context.Context, objectName string) (io.ReadCloser, ObjectInfo, error) {
+	object, err := s.client.GetObject(ctx, s.bucketName, objectName, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, ObjectInfo{}, s.wrapError(err)
+	}
+	stat, err := object.Stat()
 	if err != nil {
-		return fmt.Errorf("failed to upload file: %v", err)
+		object.Close()
+		return nil, ObjectInfo{}, s.wrapError(err)
+	}
+	return object, toObjectInfo(stat), nil
+}
+
+// Stat returns the metadata of the object.
+func (s *MinioService) Stat(ctx context.Context, objectName string) (ObjectInfo, error) {
+	stat, err := s.client.StatObject(ctx, s.bucketName, objectName, minio.StatObjectOptions{})
+	if err != nil {
+		return ObjectInfo{}, s.wrapError(err)
+	}
+	return toObjectInfo(stat), nil
+}
+
+// Delete removes the object. Deleting a missing object is not an error.
+func (s *MinioService) Delete(ctx context.Context, objectName string) error {
+	if err := s.client.RemoveObject(ctx, s.bucketName, objectName, minio.RemoveObjectOptions{}); err != nil {
+		return fmt.Errorf("failed to delete file: %v", err)
 	}
 	return nil
 }
 
-func (s *MinioService) GetFileURL(objectName string) string {
-	// Return direct URL to the object in MinIO
-	return fmt.Sprintf("http://%s/%s/%s", s.endpoint, s.bucketName, objectName)
+// List returns every object whose name starts with prefix.
+func (s *MinioService) List(ctx context.Context, prefix string) ([]ObjectInfo, error) {
+	var objects []ObjectInfo
+	for object := range s.client.ListObjects(ctx, s.bucketName, minio.ListObjectsOptions{
+		Prefix:    prefix,
+		Recursive: true,
+	}) {
+		if object.Err != nil {
+			return nil, fmt.Errorf("failed to list files: %v", object.Err)
+		}
+		objects = append(objects, toObjectInfo(object))
+	}
+	return objects, nil
+}
+
+// SignedURL returns a pre-signed GET URL for the object that expires after the configured expiry.
+func (s *MinioService) SignedURL(ctx context.Context, objectName string) (string, error) {
+	u, err := s.client.PresignedGetObject(ctx, s.bucketName, objectName, s.urlExpiry, url.Values{})
+	if err != nil {
+		return "", fmt.Errorf("failed to sign file URL: %v", err)
+	}
+	return u.String(), nil
+}
+
+// Ping checks that the bucket is reachable and exists.
+func (s *MinioService) Ping(ctx context.Context) error {
+	exists, err := s.client.BucketExists(ctx, s.bucketName)
+	if err != nil {
+		return fmt.Errorf("failed to check bucket existence: %v", err)
+	}
+	if !exists {
+		return fmt.Errorf("bucket %s does not exist", s.bucketName)
+	}
+	return nil
+}
+
+func (s *MinioService) wrapError(err error) error {
+	if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return ErrObjectNotFound
+	}
+	return err
+}
+
+func toObjectInfo(info minio.ObjectInfo) ObjectInfo {
+	return ObjectInfo{
+		Name:         info.Key,
+		Size:         info.Size,
+		ContentType:  info.ContentType,
+		LastModified: info.LastModified,
+	}
 }
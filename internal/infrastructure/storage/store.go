@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"service-test-runner/internal/config"
+)
+
+// ErrObjectNotFound is returned when the requested object does not exist in the store.
+var ErrObjectNotFound = errors.New("object not found")
+
+// ObjectInfo describes a stored object.
+type ObjectInfo struct {
+	Name         string
+	Size         int64
+	ContentType  string
+	LastModified time.Time
+}
+
+// ArtifactStore defines the contract for the object storage holding run artifacts.
+type ArtifactStore interface {
+	Put(ctx context.Context, objectName string, reader io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, objectName string) (io.ReadCloser, ObjectInfo, error)
+	Stat(ctx context.Context, objectName string) (ObjectInfo, error)
+	Delete(ctx context.Context, objectName string) error
+	List(ctx context.Context, prefix string) ([]ObjectInfo, error)
+	SignedURL(ctx context.Context, objectName string) (string, error)
+	Ping(ctx context.Context) error
+}
+
+// SignedURLVerifier is implemented by stores whose signed URLs point back at this
+// service instead of at the storage backend itself.
+type SignedURLVerifier interface {
+	VerifySignedURL(objectName string, expires int64, signature string) error
+}
+
+// NewArtifactStore creates the artifact store selected by storage.driver.
+func NewArtifactStore(cfg *config.Config) (ArtifactStore, error) {
+	switch cfg.Storage.Driver {
+	case "", "minio":
+		return NewMinioService(&cfg.MinIO)
+	case "local":
+		return NewLocalStore(&cfg.Storage.Local)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Storage.Driver)
+	}
+}
@@ -0,0 +1,89 @@
+package artifactRepo
+
+import (
+	"context"
+	"time"
+
+	"service-test-runner/internal/db"
+	"service-test-runner/internal/domain"
+	"service-test-runner/internal/infrastructure/logging"
+
+	"gorm.io/gorm"
+)
+
+// ArtifactRepository defines the repository interface for run artifacts.
+type ArtifactRepository interface {
+	Create(ctx context.Context, artifact *domain.Artifact) error
+	GetByReferenceNumber(ctx context.Context, referenceNumber string) ([]domain.Artifact, error)
+	Delete(ctx context.Context, id uint) error
+	DeleteByReferenceNumber(ctx context.Context, referenceNumber string) error
+}
+
+// artifactRepository is the concrete implementation.
+type artifactRepository struct {
+	db *gorm.DB
+}
+
+// NewArtifactRepository creates a new instance of the repository.
+func NewArtifactRepository(gdb *gorm.DB) ArtifactRepository {
+	return &artifactRepository{db: gdb}
+}
+
+// Create stores a new artifact record, setting its ID and creation time.
+func (r *artifactRepository) Create(ctx context.Context, artifact *domain.Artifact) error {
+	row := db.TblArtifact{
+		ReferenceNumber: artifact.ReferenceNumber,
+		IdTest:          artifact.IdTest,
+		StepName:        artifact.StepName,
+		Name:            artifact.Name,
+		Type:            artifact.Type,
+		ContentType:     artifact.ContentType,
+		Size:            artifact.Size,
+		Checksum:        artifact.Checksum,
+		ObjectName:      artifact.ObjectName,
+		CreatedAt:       time.Now(),
+	}
+	if err := r.db.WithContext(ctx).Create(&row).Error; err != nil {
+		logging.FromContext(ctx).Error("Error inserting Artifact record", "reference_number", artifact.ReferenceNumber, "error", err)
+		return err
+	}
+	artifact.ID = row.ID
+	artifact.CreatedAt = row.CreatedAt
+	return nil
+}
+
+// GetByReferenceNumber fetches all artifacts of a run, oldest first.
+func (r *artifactRepository) GetByReferenceNumber(ctx context.Context, referenceNumber string) ([]domain.Artifact, error) {
+	var rows []db.TblArtifact
+	if err := r.db.WithContext(ctx).Where("reference_number = ?", referenceNumber).Order("id").Find(&rows).Error; err != nil {
+		logging.FromContext(ctx).Error("Error selecting Artifact records", "reference_number", referenceNumber, "error", err)
+		return nil, err
+	}
+	artifacts := make([]domain.Artifact, 0, len(rows))
+	for _, row := range rows {
+		artifacts = append(artifacts, domain.Artifact{
+			ID:              row.ID,
+			ReferenceNumber: row.ReferenceNumber,
+			IdTest:          row.IdTest,
+			StepName:        row.StepName,
+			Name:            row.Name,
+			Type:            row.Type,
+			ContentType:     row.ContentType,
+			Size:            row.Size,
+			Checksum:        row.Checksum,
+			ObjectName:      row.ObjectName,
+			CreatedAt:       row.CreatedAt,
+		})
+	}
+	return artifacts, nil
+}
+
+// Delete removes a single artifact record.
+func (r *artifactRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&db.TblArtifact{}, id).Error
+}
+
+// DeleteByReferenceNumber removes all artifact records of a run.
+func (r *artifactRepository) DeleteByReferenceNumber(ctx context.Context, referenceNumber string) error {
+	return r.db.WithContext(ctx).Where("reference_number = ?", referenceNumber).Delete(&db.TblArtifact{}).Error
+}
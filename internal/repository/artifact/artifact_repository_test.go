@@ -0,0 +1,70 @@
+package artifactRepo_test
+
+import (
+	"context"
+	"testing"
+
+	"service-test-runner/internal/db/dbtest"
+	"service-test-runner/internal/domain"
+	artifactRepo "service-test-runner/internal/repository/artifact"
+)
+
+func TestCreateGetDelete(t *testing.T) {
+	ctx := context.Background()
+	repo := artifactRepo.NewArtifactRepository(dbtest.Open(t))
+
+	for _, a := range []domain.Artifact{
+		{ReferenceNumber: "ref-1", Name: "report.pdf", Type: domain.ArtifactTypePDF, ContentType: "application/pdf", Size: 10, Checksum: "abc", ObjectName: "ref-1/report.pdf"},
+		{ReferenceNumber: "ref-1", StepName: "login", Name: "login.png", Type: domain.ArtifactTypeScreenshot, ContentType: "image/png", Size: 20, ObjectName: "ref-1/login.png"},
+		{ReferenceNumber: "ref-2", Name: "run.log", Type: domain.ArtifactTypeLog, ContentType: "text/plain", Size: 30, ObjectName: "ref-2/run.log"},
+	} {
+		if err := repo.Create(ctx, &a); err != nil {
+			t.Fatal(err)
+		}
+		if a.ID == 0 {
+			t.Fatal("created artifact has no ID")
+		}
+	}
+
+	artifacts, err := repo.GetByReferenceNumber(ctx, "ref-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(artifacts) != 2 || artifacts[0].Checksum != "abc" || artifacts[1].StepName != "login" || artifacts[1].ObjectName != "ref-1/login.png" {
+		t.Fatalf("GetByReferenceNumber = %+v", artifacts)
+	}
+
+	if err := repo.DeleteByReferenceNumber(ctx, "ref-1"); err != nil {
+		t.Fatal(err)
+	}
+	if artifacts, err := repo.GetByReferenceNumber(ctx, "ref-1"); err != nil || len(artifacts) != 0 {
+		t.Fatalf("artifacts after delete = %+v, %v", artifacts, err)
+	}
+	if artifacts, err := repo.GetByReferenceNumber(ctx, "ref-2"); err != nil || len(artifacts) != 1 {
+		t.Fatalf("artifacts of another run = %+v, %v", artifacts, err)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	ctx := context.Background()
+	repo := artifactRepo.NewArtifactRepository(dbtest.Open(t))
+
+	kept := domain.Artifact{ReferenceNumber: "ref-1", Name: "run.log", Type: domain.ArtifactTypeLog, ObjectName: "ref-1/run.log"}
+	deleted := domain.Artifact{ReferenceNumber: "ref-1", Name: "shot.png", Type: domain.ArtifactTypeScreenshot, ObjectName: "ref-1/shot.png"}
+	for _, a := range []*domain.Artifact{&kept, &deleted} {
+		if err := repo.Create(ctx, a); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := repo.Delete(ctx, deleted.ID); err != nil {
+		t.Fatal(err)
+	}
+	artifacts, err := repo.GetByReferenceNumber(ctx, "ref-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(artifacts) != 1 || artifacts[0].ID != kept.ID {
+		t.Fatalf("artifacts after delete = %+v", artifacts)
+	}
+}
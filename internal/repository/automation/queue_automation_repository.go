@@ -1,41 +1,320 @@
 package automationRepo
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
 	"service-test-runner/internal/db" // adjust the import path accordingly
+	"service-test-runner/internal/domain"
+	"service-test-runner/internal/infrastructure/logging"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // QueueAutomationRepository defines the repository interface.
 type QueueAutomationRepository interface {
-	GetByIdTest(idTest string) (*db.TblQueueAutomation, error)
-	GetByReferenceNumber(referenceNumber string) (*db.TblQueueAutomation, error)
-	UpdateStatus(idTest string, stepName string, checkpoint int, status int, referenceNumber string) error
-	UpdateStatusByReferenceNumber(referenceNumber string, stepName string, status int) error
+	Create(ctx context.Context, run *domain.Run) error
+	GetByIdTest(ctx context.Context, idTest string) (*domain.Run, error)
+	GetByReferenceNumber(ctx context.Context, referenceNumber string) (*domain.Run, error)
+	UpdateStatus(ctx context.Context, idTest string, stepName string, checkpoint int, status int, referenceNumber string) error
+	UpdateStatusByReferenceNumber(ctx context.Context, referenceNumber string, stepName string, status int) error
+	UpdateReportFile(ctx context.Context, idTest string, reportFile string) error
+	GetRetainable(ctx context.Context, project string, finalStatuses []int) ([]domain.Run, error)
+	CountByStatus(ctx context.Context, status int) (int64, error)
+	CountByProjectAndStatus(ctx context.Context, project string, status int, excludeReferenceNumber string) (int64, error)
+	GetByPriority(ctx context.Context, status int, priorities []string, limit int) ([]domain.Run, error)
+	CountByRequester(ctx context.Context, status int) ([]domain.RequesterRunCount, error)
+	CountByRunner(ctx context.Context, project string, status int) (map[string]int64, error)
+	MarkStarted(ctx context.Context, referenceNumber, idTest, runnerURL string, fromStatus, toStatus int) (bool, error)
+	MarkFinished(ctx context.Context, referenceNumber string, status int, finalStatuses []int) (bool, error)
+	SetRunner(ctx context.Context, referenceNumber, runnerURL string) error
+	GetPreviousFinished(ctx context.Context, project, testsuite, referenceNumber string, before time.Time, finalStatuses []int) (*domain.Run, error)
+	Archive(ctx context.Context, referenceNumber string) error
+	SetPinned(ctx context.Context, referenceNumber string, pinned bool) error
 }
 
 // queueAutomationRepository is the concrete implementation.
-type queueAutomationRepository struct{}
+type queueAutomationRepository struct {
+	db *gorm.DB
+}
+
+// NewQueueAutomationRepository creates a new instance of the repository on the given
+// database handle, which may be a transaction.
+func NewQueueAutomationRepository(gdb *gorm.DB) QueueAutomationRepository {
+	return &queueAutomationRepository{db: gdb}
+}
 
-// NewQueueAutomationRepository creates a new instance of the repository.
-func NewQueueAutomationRepository() QueueAutomationRepository {
-	return &queueAutomationRepository{}
+// Create inserts a new record, setting its ID and creation time.
+func (r *queueAutomationRepository) Create(ctx context.Context, run *domain.Run) error {
+	run.CreatedAt = time.Now()
+	row := toRow(run)
+	if err := r.db.WithContext(ctx).Create(&row).Error; err != nil {
+		logging.FromContext(ctx).Error("Error inserting QueueAutomation record", "reference_number", run.ReferenceNumber, "error", err)
+		return err
+	}
+	run.ID = row.ID
+
+	logging.FromContext(ctx).Info("Inserted QueueAutomation record", "reference_number", run.ReferenceNumber, "project", run.Project, "testsuite_id", run.IdTest)
+	return nil
 }
 
 // GetByIdTest fetches a record by its idTest.
-func (r *queueAutomationRepository) GetByIdTest(idTest string) (*db.TblQueueAutomation, error) {
-	return db.SelectQueueAutomationByIdTest(idTest)
+func (r *queueAutomationRepository) GetByIdTest(ctx context.Context, idTest string) (*domain.Run, error) {
+	var row db.TblQueueAutomation
+	if err := r.db.WithContext(ctx).Where("id_test = ?", idTest).First(&row).Error; err != nil {
+		logging.FromContext(ctx).Error("Error selecting QueueAutomation record", "testsuite_id", idTest, "error", err)
+		return nil, err
+	}
+	run := toDomain(row)
+	return &run, nil
 }
 
 // UpdateStatus updates the record’s checkpoint and status.
-func (r *queueAutomationRepository) UpdateStatus(idTest string, stepName string, checkpoint int, status int, referenceNumber string) error {
-	return db.UpdateQueueAutomationStatus(idTest, stepName, checkpoint, status, referenceNumber)
+func (r *queueAutomationRepository) UpdateStatus(ctx context.Context, idTest string, stepName string, checkpoint int, status int, referenceNumber string) error {
+	return r.db.WithContext(ctx).Model(&db.TblQueueAutomation{}).
+		Where("reference_number = ?", referenceNumber).
+		Updates(map[string]interface{}{
+			"step_name":  stepName,
+			"checkpoint": checkpoint,
+			"status":     status,
+			"id_test":    idTest,
+		}).Error
 }
 
 // GetByReferenceNumber fetches a record by its reference number.
-func (r *queueAutomationRepository) GetByReferenceNumber(referenceNumber string) (*db.TblQueueAutomation, error) {
-	return db.SelectQueueAutomationByRefnum(referenceNumber)
+func (r *queueAutomationRepository) GetByReferenceNumber(ctx context.Context, referenceNumber string) (*domain.Run, error) {
+	var row db.TblQueueAutomation
+	if err := r.db.WithContext(ctx).Where("reference_number = ?", referenceNumber).First(&row).Error; err != nil {
+		logging.FromContext(ctx).Error("Error selecting QueueAutomation record", "reference_number", referenceNumber, "error", err)
+		return nil, err
+	}
+	run := toDomain(row)
+	return &run, nil
 }
 
 // UpdateStatusByReferenceNumber updates the record’s status by its reference number.
-func (r *queueAutomationRepository) UpdateStatusByReferenceNumber(idTest string, referenceNumber string, status int) error {
-	return db.UpdateQueueAutomationStatusByReferenceNumber(idTest, referenceNumber, status)
+func (r *queueAutomationRepository) UpdateStatusByReferenceNumber(ctx context.Context, idTest string, referenceNumber string, status int) error {
+	return r.db.WithContext(ctx).Model(&db.TblQueueAutomation{}).
+		Where("reference_number = ?", referenceNumber).
+		Update("status", status).Error
+}
+
+// UpdateReportFile updates the report_file object name of the record with the given idTest.
+func (r *queueAutomationRepository) UpdateReportFile(ctx context.Context, idTest string, reportFile string) error {
+	return r.db.WithContext(ctx).Model(&db.TblQueueAutomation{}).
+		Where("id_test = ?", idTest).
+		Update("report_file", reportFile).Error
+}
+
+// GetRetainable fetches the unpinned and not yet archived runs of a project with one of
+// the given final statuses, grouped by test suite and newest first within each suite.
+func (r *queueAutomationRepository) GetRetainable(ctx context.Context, project string, finalStatuses []int) ([]domain.Run, error) {
+	var rows []db.TblQueueAutomation
+	err := r.db.WithContext(ctx).Where("project = ? AND pinned = ? AND archived_at IS NULL AND status IN ?", project, false, finalStatuses).
+		Order("testsuite").
+		Order("created_at DESC").
+		Find(&rows).Error
+	if err != nil {
+		logging.FromContext(ctx).Error("Error selecting retainable QueueAutomation records", "project", project, "error", err)
+		return nil, err
+	}
+	return toDomainList(rows), nil
+}
+
+// CountByStatus counts the records with the given status.
+func (r *queueAutomationRepository) CountByStatus(ctx context.Context, status int) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&db.TblQueueAutomation{}).Where("status = ?", status).Count(&count).Error
+	return count, err
+}
+
+// CountByProjectAndStatus counts the records of a project with the given status, other
+// than the one with excludeReferenceNumber.
+func (r *queueAutomationRepository) CountByProjectAndStatus(ctx context.Context, project string, status int, excludeReferenceNumber string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&db.TblQueueAutomation{}).
+		Where("project = ? AND status = ? AND reference_number <> ?", project, status, excludeReferenceNumber).
+		Count(&count).Error
+	return count, err
+}
+
+// GetByPriority fetches up to limit runs with the given status, in the order of
+// priorities and oldest first within a priority.
+func (r *queueAutomationRepository) GetByPriority(ctx context.Context, status int, priorities []string, limit int) ([]domain.Run, error) {
+	rank := "CASE priority"
+	vars := make([]interface{}, 0, len(priorities))
+	for i, priority := range priorities {
+		rank += fmt.Sprintf(" WHEN ? THEN %d", i)
+		vars = append(vars, priority)
+	}
+	// The rank and the creation time go in one ORDER BY expression: a later Order call
+	// would replace an OrderBy expression rather than add to it.
+	rank += fmt.Sprintf(" ELSE %d END, created_at, id", len(priorities))
+
+	var rows []db.TblQueueAutomation
+	err := r.db.WithContext(ctx).Where("status = ?", status).
+		Order(clause.OrderBy{Expression: clause.Expr{SQL: rank, Vars: vars, WithoutParentheses: true}}).
+		Limit(limit).
+		Find(&rows).Error
+	if err != nil {
+		logging.FromContext(ctx).Error("Error selecting QueueAutomation records by priority", "status", status, "error", err)
+		return nil, err
+	}
+	return toDomainList(rows), nil
+}
+
+// CountByRequester counts the records with the given status per project and requester email.
+func (r *queueAutomationRepository) CountByRequester(ctx context.Context, status int) ([]domain.RequesterRunCount, error) {
+	var counts []domain.RequesterRunCount
+	err := r.db.WithContext(ctx).Model(&db.TblQueueAutomation{}).
+		Select("project, COALESCE(email, '') AS email, COUNT(*) AS count").
+		Where("status = ?", status).
+		Group("project, COALESCE(email, '')").
+		Scan(&counts).Error
+	return counts, err
+}
+
+// CountByRunner counts the records of a project with the given status per runner node URL.
+func (r *queueAutomationRepository) CountByRunner(ctx context.Context, project string, status int) (map[string]int64, error) {
+	var counts []struct {
+		RunnerURL string
+		Count     int64
+	}
+	err := r.db.WithContext(ctx).Model(&db.TblQueueAutomation{}).
+		Select("runner_url, COUNT(*) AS count").
+		Where("project = ? AND status = ? AND runner_url IS NOT NULL", project, status).
+		Group("runner_url").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+	byRunner := make(map[string]int64, len(counts))
+	for _, c := range counts {
+		byRunner[c.RunnerURL] = c.Count
+	}
+	return byRunner, nil
+}
+
+// MarkStarted moves a run to toStatus with its runner ID and node if it is still in
+// fromStatus. It reports false if the run was no longer in fromStatus.
+func (r *queueAutomationRepository) MarkStarted(ctx context.Context, referenceNumber, idTest, runnerURL string, fromStatus, toStatus int) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&db.TblQueueAutomation{}).
+		Where("reference_number = ? AND status = ?", referenceNumber, fromStatus).
+		Updates(map[string]interface{}{
+			"id_test":    idTest,
+			"runner_url": runnerURL,
+			"status":     toStatus,
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// MarkFinished moves a run to the given status unless it already has one of the final
+// statuses. It reports false if the run had already finished.
+func (r *queueAutomationRepository) MarkFinished(ctx context.Context, referenceNumber string, status int, finalStatuses []int) (bool, error) {
+	result := r.db.WithContext(ctx).Model(&db.TblQueueAutomation{}).
+		Where("reference_number = ? AND status NOT IN ?", referenceNumber, finalStatuses).
+		Update("status", status)
+	return result.RowsAffected > 0, result.Error
+}
+
+// SetRunner records the runner node a run was sent to.
+func (r *queueAutomationRepository) SetRunner(ctx context.Context, referenceNumber, runnerURL string) error {
+	return r.db.WithContext(ctx).Model(&db.TblQueueAutomation{}).
+		Where("reference_number = ?", referenceNumber).
+		Update("runner_url", runnerURL).Error
+}
+
+// GetPreviousFinished fetches the latest run of a test suite created before the given
+// time with one of the given statuses, or nil if there is none.
+func (r *queueAutomationRepository) GetPreviousFinished(ctx context.Context, project, testsuite, referenceNumber string, before time.Time, finalStatuses []int) (*domain.Run, error) {
+	var row db.TblQueueAutomation
+	err := r.db.WithContext(ctx).Where("project = ? AND testsuite = ? AND reference_number <> ? AND created_at < ? AND status IN ?",
+		project, testsuite, referenceNumber, before, finalStatuses).
+		Order("created_at DESC").
+		First(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("Error selecting previous QueueAutomation record", "project", project, "testsuite", testsuite, "error", err)
+		return nil, err
+	}
+	run := toDomain(row)
+	return &run, nil
+}
+
+// Archive marks the record as archived and clears its report file.
+func (r *queueAutomationRepository) Archive(ctx context.Context, referenceNumber string) error {
+	return r.db.WithContext(ctx).Model(&db.TblQueueAutomation{}).
+		Where("reference_number = ?", referenceNumber).
+		Updates(map[string]interface{}{
+			"archived_at": time.Now(),
+			"report_file": "",
+		}).Error
+}
+
+// SetPinned pins or unpins the record so retention rules skip it.
+func (r *queueAutomationRepository) SetPinned(ctx context.Context, referenceNumber string, pinned bool) error {
+	return r.db.WithContext(ctx).Model(&db.TblQueueAutomation{}).
+		Where("reference_number = ?", referenceNumber).
+		Update("pinned", pinned).Error
+}
+
+func toRow(run *domain.Run) db.TblQueueAutomation {
+	return db.TblQueueAutomation{
+		ID:              run.ID,
+		ReferenceNumber: run.ReferenceNumber,
+		Testsuite:       run.Testsuite,
+		StepName:        run.StepName,
+		Checkpoint:      run.Checkpoint,
+		TotalSteps:      run.TotalSteps,
+		Status:          run.Status,
+		Priority:        run.Priority,
+		IdTest:          run.IdTest,
+		CreatedAt:       run.CreatedAt,
+		Project:         run.Project,
+		RunnerURL:       run.RunnerURL,
+		BrowserName:     run.BrowserName,
+		BrowserVersion:  run.BrowserVersion,
+		PlatformName:    run.PlatformName,
+		ReportFile:      run.ReportFile,
+		Email:           run.Email,
+		Pinned:          run.Pinned,
+		ArchivedAt:      run.ArchivedAt,
+	}
+}
+
+func toDomain(row db.TblQueueAutomation) domain.Run {
+	return domain.Run{
+		ID:              row.ID,
+		ReferenceNumber: row.ReferenceNumber,
+		Testsuite:       row.Testsuite,
+		StepName:        row.StepName,
+		Checkpoint:      row.Checkpoint,
+		TotalSteps:      row.TotalSteps,
+		Status:          row.Status,
+		Priority:        row.Priority,
+		IdTest:          row.IdTest,
+		CreatedAt:       row.CreatedAt,
+		Project:         row.Project,
+		RunnerURL:       row.RunnerURL,
+		BrowserName:     row.BrowserName,
+		BrowserVersion:  row.BrowserVersion,
+		PlatformName:    row.PlatformName,
+		ReportFile:      row.ReportFile,
+		Email:           row.Email,
+		Pinned:          row.Pinned,
+		ArchivedAt:      row.ArchivedAt,
+	}
+}
+
+func toDomainList(rows []db.TblQueueAutomation) []domain.Run {
+	runs := make([]domain.Run, 0, len(rows))
+	for _, row := range rows {
+		runs = append(runs, toDomain(row))
+	}
+	return runs
 }
@@ -0,0 +1,245 @@
+package automationRepo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"service-test-runner/internal/db/dbtest"
+	"service-test-runner/internal/domain"
+	automationRepo "service-test-runner/internal/repository/automation"
+)
+
+func createRun(t *testing.T, repo automationRepo.QueueAutomationRepository, run domain.Run) domain.Run {
+	t.Helper()
+	if run.Project == "" {
+		run.Project = "web1"
+	}
+	if run.Testsuite == "" {
+		run.Testsuite = "suite1"
+	}
+	if run.Priority == "" {
+		run.Priority = domain.RunPriorityNormal
+	}
+	if err := repo.Create(context.Background(), &run); err != nil {
+		t.Fatalf("create run %s: %v", run.ReferenceNumber, err)
+	}
+	return run
+}
+
+func TestCreateAndGet(t *testing.T) {
+	ctx := context.Background()
+	repo := automationRepo.NewQueueAutomationRepository(dbtest.Open(t))
+
+	created := createRun(t, repo, domain.Run{
+		ReferenceNumber: "ref-1",
+		Status:          domain.RunStatusTriggered,
+		IdTest:          "run-1",
+		Email:           "dev@example.com",
+		BrowserName:     "chrome",
+	})
+	if created.ID == 0 || created.CreatedAt.IsZero() {
+		t.Fatalf("created run has no ID or creation time: %+v", created)
+	}
+
+	byRef, err := repo.GetByReferenceNumber(ctx, "ref-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	byID, err := repo.GetByIdTest(ctx, "run-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, run := range []*domain.Run{byRef, byID} {
+		if run.ID != created.ID || run.Email != "dev@example.com" || run.BrowserName != "chrome" || run.Status != domain.RunStatusTriggered {
+			t.Errorf("got %+v, want the created run", run)
+		}
+	}
+	if _, err := repo.GetByReferenceNumber(ctx, "missing"); err == nil {
+		t.Error("expected an error for a missing run")
+	}
+}
+
+func TestMarkStartedOnlyFromStatus(t *testing.T) {
+	ctx := context.Background()
+	repo := automationRepo.NewQueueAutomationRepository(dbtest.Open(t))
+	createRun(t, repo, domain.Run{ReferenceNumber: "ref-1", Status: domain.RunStatusQueued})
+
+	started, err := repo.MarkStarted(ctx, "ref-1", "run-1", "http://node-1", domain.RunStatusQueued, domain.RunStatusTriggered)
+	if err != nil || !started {
+		t.Fatalf("MarkStarted = %v, %v; want true", started, err)
+	}
+	started, err = repo.MarkStarted(ctx, "ref-1", "run-2", "http://node-2", domain.RunStatusQueued, domain.RunStatusTriggered)
+	if err != nil || started {
+		t.Fatalf("second MarkStarted = %v, %v; want false", started, err)
+	}
+	run, err := repo.GetByReferenceNumber(ctx, "ref-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run.IdTest != "run-1" || run.RunnerURL != "http://node-1" || run.Status != domain.RunStatusTriggered {
+		t.Fatalf("got %+v, want the first start", run)
+	}
+}
+
+func TestMarkFinishedOnlyOnce(t *testing.T) {
+	ctx := context.Background()
+	repo := automationRepo.NewQueueAutomationRepository(dbtest.Open(t))
+	createRun(t, repo, domain.Run{ReferenceNumber: "ref-1", Status: domain.RunStatusTriggered})
+
+	finished, err := repo.MarkFinished(ctx, "ref-1", domain.RunStatusFailed, domain.FinalRunStatuses)
+	if err != nil || !finished {
+		t.Fatalf("MarkFinished = %v, %v; want true", finished, err)
+	}
+	finished, err = repo.MarkFinished(ctx, "ref-1", domain.RunStatusPassed, domain.FinalRunStatuses)
+	if err != nil || finished {
+		t.Fatalf("second MarkFinished = %v, %v; want false", finished, err)
+	}
+	run, err := repo.GetByReferenceNumber(ctx, "ref-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run.Status != domain.RunStatusFailed {
+		t.Fatalf("status = %d, want the first final status", run.Status)
+	}
+}
+
+func TestCounts(t *testing.T) {
+	ctx := context.Background()
+	repo := automationRepo.NewQueueAutomationRepository(dbtest.Open(t))
+	createRun(t, repo, domain.Run{ReferenceNumber: "ref-1", Status: domain.RunStatusTriggered, RunnerURL: "http://node-1", Email: "a@example.com"})
+	createRun(t, repo, domain.Run{ReferenceNumber: "ref-2", Status: domain.RunStatusTriggered, RunnerURL: "http://node-1", Email: "a@example.com"})
+	createRun(t, repo, domain.Run{ReferenceNumber: "ref-3", Status: domain.RunStatusTriggered, RunnerURL: "http://node-2"})
+	createRun(t, repo, domain.Run{ReferenceNumber: "ref-4", Status: domain.RunStatusQueued, Project: "mobile1"})
+
+	if n, err := repo.CountByStatus(ctx, domain.RunStatusTriggered); err != nil || n != 3 {
+		t.Errorf("CountByStatus = %d, %v; want 3", n, err)
+	}
+	if n, err := repo.CountByProjectAndStatus(ctx, "mobile1", domain.RunStatusQueued, ""); err != nil || n != 1 {
+		t.Errorf("CountByProjectAndStatus = %d, %v; want 1", n, err)
+	}
+	if n, err := repo.CountByProjectAndStatus(ctx, "web1", domain.RunStatusTriggered, "ref-2"); err != nil || n != 2 {
+		t.Errorf("CountByProjectAndStatus without ref-2 = %d, %v; want 2", n, err)
+	}
+
+	byRunner, err := repo.CountByRunner(ctx, "web1", domain.RunStatusTriggered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if byRunner["http://node-1"] != 2 || byRunner["http://node-2"] != 1 || len(byRunner) != 2 {
+		t.Errorf("CountByRunner = %v", byRunner)
+	}
+
+	byRequester, err := repo.CountByRequester(ctx, domain.RunStatusTriggered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]int64{"a@example.com": 2, "": 1}
+	if len(byRequester) != len(want) {
+		t.Fatalf("CountByRequester = %+v", byRequester)
+	}
+	for _, c := range byRequester {
+		if c.Project != "web1" || want[c.Email] != c.Count {
+			t.Errorf("CountByRequester has %+v", c)
+		}
+	}
+}
+
+func TestGetPreviousFinished(t *testing.T) {
+	ctx := context.Background()
+	repo := automationRepo.NewQueueAutomationRepository(dbtest.Open(t))
+	final := []int{domain.RunStatusPassed, domain.RunStatusFailed}
+
+	createRun(t, repo, domain.Run{ReferenceNumber: "ref-1", Status: domain.RunStatusFailed})
+	time.Sleep(10 * time.Millisecond)
+	createRun(t, repo, domain.Run{ReferenceNumber: "ref-2", Status: domain.RunStatusTriggered})
+	time.Sleep(10 * time.Millisecond)
+	current := createRun(t, repo, domain.Run{ReferenceNumber: "ref-3", Status: domain.RunStatusPassed})
+
+	previous, err := repo.GetPreviousFinished(ctx, "web1", "suite1", "ref-3", current.CreatedAt, final)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if previous == nil || previous.ReferenceNumber != "ref-1" {
+		t.Fatalf("GetPreviousFinished = %+v, want ref-1", previous)
+	}
+	previous, err = repo.GetPreviousFinished(ctx, "web1", "other", "ref-3", current.CreatedAt, final)
+	if err != nil || previous != nil {
+		t.Fatalf("GetPreviousFinished of another suite = %+v, %v; want nil", previous, err)
+	}
+}
+
+func TestRetainableOnlyFinishedUnpinnedAndUnarchived(t *testing.T) {
+	ctx := context.Background()
+	repo := automationRepo.NewQueueAutomationRepository(dbtest.Open(t))
+
+	createRun(t, repo, domain.Run{ReferenceNumber: "ref-1", Status: domain.RunStatusPassed})
+	createRun(t, repo, domain.Run{ReferenceNumber: "ref-2", Status: domain.RunStatusFailed})
+	createRun(t, repo, domain.Run{ReferenceNumber: "ref-3", Status: domain.RunStatusPassed})
+	createRun(t, repo, domain.Run{ReferenceNumber: "ref-4", Status: domain.RunStatusTriggered})
+	createRun(t, repo, domain.Run{ReferenceNumber: "ref-5", Status: 0})
+	createRun(t, repo, domain.Run{ReferenceNumber: "ref-6", Status: 7})
+	if err := repo.SetPinned(ctx, "ref-2", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Archive(ctx, "ref-3"); err != nil {
+		t.Fatal(err)
+	}
+
+	runs, err := repo.GetRetainable(ctx, "web1", domain.FinalRunStatuses)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 1 || runs[0].ReferenceNumber != "ref-1" {
+		t.Fatalf("GetRetainable = %+v, want only ref-1", runs)
+	}
+	archived, err := repo.GetByReferenceNumber(ctx, "ref-3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if archived.ArchivedAt == nil {
+		t.Error("archived run has no archived_at")
+	}
+}
+
+func TestGetByPriorityOrdersByPriorityThenAge(t *testing.T) {
+	ctx := context.Background()
+	repo := automationRepo.NewQueueAutomationRepository(dbtest.Open(t))
+
+	for _, run := range []domain.Run{
+		{ReferenceNumber: "low-old", Priority: domain.RunPriorityLow},
+		{ReferenceNumber: "critical", Priority: domain.RunPriorityCritical},
+		{ReferenceNumber: "normal", Priority: domain.RunPriorityNormal},
+		{ReferenceNumber: "low-new", Priority: domain.RunPriorityLow},
+		{ReferenceNumber: "started", Priority: domain.RunPriorityCritical, Status: domain.RunStatusTriggered},
+	} {
+		if run.Status == 0 {
+			run.Status = domain.RunStatusQueued
+		}
+		createRun(t, repo, run)
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	runs, err := repo.GetByPriority(ctx, domain.RunStatusQueued, domain.RunPriorities, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"critical", "normal", "low-old", "low-new"}
+	if len(runs) != len(want) {
+		t.Fatalf("GetByPriority returned %d runs, want %d", len(runs), len(want))
+	}
+	for i, run := range runs {
+		if run.ReferenceNumber != want[i] {
+			t.Fatalf("run %d = %s, want order %v", i, run.ReferenceNumber, want)
+		}
+	}
+
+	// A batch smaller than the backlog of older low-priority runs still holds the critical run.
+	first, err := repo.GetByPriority(ctx, domain.RunStatusQueued, domain.RunPriorities, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(first) != 1 || first[0].ReferenceNumber != "critical" {
+		t.Fatalf("GetByPriority with limit 1 = %+v, want the critical run", first)
+	}
+}
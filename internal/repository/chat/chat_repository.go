@@ -0,0 +1,109 @@
+package chatRepo
+
+import (
+	"context"
+	"errors"
+
+	"service-test-runner/internal/db"
+	"service-test-runner/internal/domain"
+	"service-test-runner/internal/infrastructure/logging"
+
+	"gorm.io/gorm"
+)
+
+// ChatRepository defines the repository interface for Slack and Teams chat notifications.
+type ChatRepository interface {
+	Create(ctx context.Context, chat *domain.ChatNotification) error
+	GetByID(ctx context.Context, id uint) (*domain.ChatNotification, error)
+	GetByProject(ctx context.Context, project string) ([]domain.ChatNotification, error)
+	Update(ctx context.Context, chat *domain.ChatNotification) error
+	Delete(ctx context.Context, id uint) error
+}
+
+// chatRepository is the concrete implementation.
+type chatRepository struct {
+	db *gorm.DB
+}
+
+// NewChatRepository creates a new instance of the repository.
+func NewChatRepository(gdb *gorm.DB) ChatRepository {
+	return &chatRepository{db: gdb}
+}
+
+// Create inserts a new chat notification, setting its ID and creation time.
+func (r *chatRepository) Create(ctx context.Context, chat *domain.ChatNotification) error {
+	row := toRow(chat)
+	if err := r.db.WithContext(ctx).Create(&row).Error; err != nil {
+		logging.FromContext(ctx).Error("Error inserting ChatNotification record", "error", err)
+		return err
+	}
+	chat.ID = row.ID
+	chat.CreatedAt = row.CreatedAt
+	return nil
+}
+
+// GetByID fetches a chat notification, or nil if it does not exist.
+func (r *chatRepository) GetByID(ctx context.Context, id uint) (*domain.ChatNotification, error) {
+	var row db.TblChatNotification
+	err := r.db.WithContext(ctx).First(&row, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("Error selecting ChatNotification record", "chat_id", id, "error", err)
+		return nil, err
+	}
+	chat := toDomain(row)
+	return &chat, nil
+}
+
+// GetByProject fetches the chat notifications of a project.
+func (r *chatRepository) GetByProject(ctx context.Context, project string) ([]domain.ChatNotification, error) {
+	var rows []db.TblChatNotification
+	if err := r.db.WithContext(ctx).Where("project = ?", project).Order("id").Find(&rows).Error; err != nil {
+		logging.FromContext(ctx).Error("Error selecting ChatNotification records", "project", project, "error", err)
+		return nil, err
+	}
+	chats := make([]domain.ChatNotification, 0, len(rows))
+	for _, row := range rows {
+		chats = append(chats, toDomain(row))
+	}
+	return chats, nil
+}
+
+// Update saves the test suite, provider, webhook URL and active flag of a chat notification.
+func (r *chatRepository) Update(ctx context.Context, chat *domain.ChatNotification) error {
+	row := toRow(chat)
+	return r.db.WithContext(ctx).Model(&row).
+		Select("testsuite", "provider", "webhook_url", "active").
+		Updates(&row).Error
+}
+
+// Delete removes a chat notification.
+func (r *chatRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&db.TblChatNotification{}, id).Error
+}
+
+func toRow(chat *domain.ChatNotification) db.TblChatNotification {
+	return db.TblChatNotification{
+		ID:         chat.ID,
+		Project:    chat.Project,
+		Testsuite:  chat.Testsuite,
+		Provider:   chat.Provider,
+		WebhookURL: chat.WebhookURL,
+		Active:     chat.Active,
+		CreatedAt:  chat.CreatedAt,
+	}
+}
+
+func toDomain(row db.TblChatNotification) domain.ChatNotification {
+	return domain.ChatNotification{
+		ID:         row.ID,
+		Project:    row.Project,
+		Testsuite:  row.Testsuite,
+		Provider:   row.Provider,
+		WebhookURL: row.WebhookURL,
+		Active:     row.Active,
+		CreatedAt:  row.CreatedAt,
+	}
+}
@@ -0,0 +1,51 @@
+package chatRepo_test
+
+import (
+	"context"
+	"testing"
+
+	"service-test-runner/internal/db/dbtest"
+	"service-test-runner/internal/domain"
+	chatRepo "service-test-runner/internal/repository/chat"
+)
+
+func TestChatLifecycle(t *testing.T) {
+	ctx := context.Background()
+	repo := chatRepo.NewChatRepository(dbtest.Open(t))
+
+	chat := domain.ChatNotification{Project: "web1", Provider: domain.ChatProviderSlack, WebhookURL: "https://hooks.slack.com/services/x", Active: true}
+	if err := repo.Create(ctx, &chat); err != nil {
+		t.Fatal(err)
+	}
+	suite := domain.ChatNotification{Project: "web1", Testsuite: "smoke", Provider: domain.ChatProviderTeams, WebhookURL: "https://teams.example.com/x", Active: true}
+	if err := repo.Create(ctx, &suite); err != nil {
+		t.Fatal(err)
+	}
+
+	chat.Active = false
+	chat.WebhookURL = "https://hooks.slack.com/services/y"
+	if err := repo.Update(ctx, &chat); err != nil {
+		t.Fatal(err)
+	}
+	got, err := repo.GetByID(ctx, chat.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || got.Active || got.WebhookURL != chat.WebhookURL {
+		t.Fatalf("GetByID after Update = %+v", got)
+	}
+
+	if err := repo.Delete(ctx, suite.ID); err != nil {
+		t.Fatal(err)
+	}
+	chats, err := repo.GetByProject(ctx, "web1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chats) != 1 || chats[0].ID != chat.ID {
+		t.Fatalf("GetByProject after Delete = %+v", chats)
+	}
+	if got, err := repo.GetByID(ctx, suite.ID); err != nil || got != nil {
+		t.Fatalf("GetByID of deleted = %+v, %v; want nil", got, err)
+	}
+}
@@ -0,0 +1,197 @@
+package grid
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"service-test-runner/internal/domain"
+	"service-test-runner/internal/infrastructure/logging"
+	"service-test-runner/internal/infrastructure/metrics"
+	"service-test-runner/internal/infrastructure/tracing"
+)
+
+// ErrNoGrid is returned for projects whose runner does not use a Selenium Grid.
+var ErrNoGrid = errors.New("project has no Selenium Grid")
+
+// statusResponse is the body of GET /status of a Selenium Grid 4 hub.
+type statusResponse struct {
+	Value struct {
+		Ready   bool   `json:"ready"`
+		Message string `json:"message"`
+		Nodes   []struct {
+			ID           string `json:"id"`
+			URI          string `json:"uri"`
+			Availability string `json:"availability"`
+			MaxSessions  int    `json:"maxSessions"`
+			Slots        []struct {
+				Session    json.RawMessage `json:"session"`
+				Stereotype struct {
+					BrowserName    string `json:"browserName"`
+					BrowserVersion string `json:"browserVersion"`
+					PlatformName   string `json:"platformName"`
+				} `json:"stereotype"`
+			} `json:"slots"`
+		} `json:"nodes"`
+	} `json:"value"`
+}
+
+// GridRepository queries the Selenium Grid 4 hubs of the projects.
+type GridRepository interface {
+	HasGrid(project string) bool
+	Status(ctx context.Context, project string) (domain.GridStatus, error)
+}
+
+// gridRepository is the concrete implementation.
+type gridRepository struct {
+	hubs   map[string]string
+	client *http.Client
+}
+
+// NewGridRepository creates a GridRepository for the given project to hub URL mapping,
+// whose calls are cut off after timeout.
+func NewGridRepository(hubs map[string]string, timeout time.Duration) GridRepository {
+	return &gridRepository{
+		hubs: hubs,
+		client: &http.Client{
+			Transport: tracing.NewTransport(http.DefaultTransport, "grid"),
+			Timeout:   timeout,
+		},
+	}
+}
+
+// HasGrid reports whether the runner of a project uses a Selenium Grid.
+func (g *gridRepository) HasGrid(project string) bool {
+	_, ok := g.hubs[project]
+	return ok
+}
+
+// Status calls GET /status on the hub of a project and sums up its nodes, slots and browsers.
+func (g *gridRepository) Status(ctx context.Context, project string) (domain.GridStatus, error) {
+	hubURL, ok := g.hubs[project]
+	if !ok {
+		return domain.GridStatus{}, ErrNoGrid
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(hubURL, "/")+"/status", nil)
+	if err != nil {
+		return domain.GridStatus{}, err
+	}
+
+	start := time.Now()
+	resp, err := g.client.Do(req)
+	statusCode := 0
+	if resp != nil {
+		statusCode = resp.StatusCode
+	}
+	metrics.ObserveRunnerRequest(project, "grid_status", start, statusCode, err)
+	if err != nil {
+		logging.FromContext(ctx).Warn("Grid status request failed", "project", project, "error", err)
+		return domain.GridStatus{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return domain.GridStatus{}, fmt.Errorf("grid hub answered %s", resp.Status)
+	}
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return domain.GridStatus{}, err
+	}
+	var body statusResponse
+	if err := json.Unmarshal(data, &body); err != nil {
+		return domain.GridStatus{}, fmt.Errorf("invalid grid status: %v", err)
+	}
+
+	status := domain.GridStatus{
+		Project: project,
+		Ready:   body.Value.Ready,
+		Message: body.Value.Message,
+		Nodes:   []domain.GridNode{},
+	}
+	browsers := make(map[domain.Browser]*domain.GridBrowser)
+	for _, n := range body.Value.Nodes {
+		node := domain.GridNode{
+			ID:           n.ID,
+			URI:          n.URI,
+			Availability: n.Availability,
+			MaxSessions:  n.MaxSessions,
+			Browsers:     []domain.GridBrowser{},
+		}
+		nodeBrowsers := make(map[domain.Browser]*domain.GridBrowser)
+		for _, slot := range n.Slots {
+			free := isFree(slot.Session)
+			browser := domain.Browser{
+				BrowserName:    slot.Stereotype.BrowserName,
+				BrowserVersion: slot.Stereotype.BrowserVersion,
+				PlatformName:   slot.Stereotype.PlatformName,
+			}
+			b, ok := nodeBrowsers[browser]
+			if !ok {
+				b = &domain.GridBrowser{Browser: browser}
+				nodeBrowsers[browser] = b
+			}
+			b.Slots++
+			node.Slots++
+			if free {
+				b.FreeSlots++
+				node.FreeSlots++
+			}
+		}
+		// The hub offers maxSessions slots per browser, but a node never runs more
+		// than maxSessions sessions in all.
+		if busy := node.Slots - node.FreeSlots; n.MaxSessions > 0 {
+			node.FreeSlots = min(node.FreeSlots, max(n.MaxSessions-busy, 0))
+		}
+		for _, b := range nodeBrowsers {
+			b.FreeSlots = min(b.FreeSlots, node.FreeSlots)
+		}
+		node.Browsers = sortedBrowsers(nodeBrowsers)
+		status.Nodes = append(status.Nodes, node)
+
+		if !strings.EqualFold(n.Availability, "UP") {
+			continue
+		}
+		status.Slots += node.Slots
+		status.FreeSlots += node.FreeSlots
+		for browser, nb := range nodeBrowsers {
+			b, ok := browsers[browser]
+			if !ok {
+				b = &domain.GridBrowser{Browser: browser}
+				browsers[browser] = b
+			}
+			b.Slots += nb.Slots
+			b.FreeSlots += nb.FreeSlots
+		}
+	}
+	status.Browsers = sortedBrowsers(browsers)
+	return status, nil
+}
+
+// isFree reports whether a slot runs no session; the hub reports null for free slots.
+func isFree(session json.RawMessage) bool {
+	return len(session) == 0 || string(session) == "null"
+}
+
+func sortedBrowsers(browsers map[domain.Browser]*domain.GridBrowser) []domain.GridBrowser {
+	sorted := make([]domain.GridBrowser, 0, len(browsers))
+	for _, b := range browsers {
+		sorted = append(sorted, *b)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.BrowserName != b.BrowserName {
+			return a.BrowserName < b.BrowserName
+		}
+		if a.BrowserVersion != b.BrowserVersion {
+			return a.BrowserVersion < b.BrowserVersion
+		}
+		return a.PlatformName < b.PlatformName
+	})
+	return sorted
+}
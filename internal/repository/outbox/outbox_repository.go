@@ -0,0 +1,109 @@
+package outboxRepo
+
+import (
+	"context"
+
+	"service-test-runner/internal/db"
+	"service-test-runner/internal/domain"
+	"service-test-runner/internal/infrastructure/logging"
+
+	"gorm.io/gorm"
+)
+
+// OutboxRepository defines the repository interface for the RabbitMQ outbox.
+type OutboxRepository interface {
+	Create(ctx context.Context, message *domain.OutboxMessage) error
+	GetOldest(ctx context.Context, status string, limit int) ([]domain.OutboxMessage, error)
+	UpdateAttempt(ctx context.Context, message *domain.OutboxMessage) error
+	CountByStatus(ctx context.Context, status string) (int64, error)
+}
+
+// outboxRepository is the concrete implementation.
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+// NewOutboxRepository creates a new instance of the repository on the given database
+// handle, which may be a transaction.
+func NewOutboxRepository(gdb *gorm.DB) OutboxRepository {
+	return &outboxRepository{db: gdb}
+}
+
+// Create inserts a new message, setting its ID. It is meant to run in the transaction
+// that writes the run the message announces.
+func (r *outboxRepository) Create(ctx context.Context, message *domain.OutboxMessage) error {
+	row := toRow(message)
+	if err := r.db.WithContext(ctx).Create(&row).Error; err != nil {
+		logging.FromContext(ctx).Error("Error inserting OutboxMessage record", "reference_number", message.ReferenceNumber, "error", err)
+		return err
+	}
+	message.ID = row.ID
+	message.CreatedAt = row.CreatedAt
+	return nil
+}
+
+// GetOldest fetches the oldest messages in a status, in the order they were written,
+// whether their next attempt is due or not.
+func (r *outboxRepository) GetOldest(ctx context.Context, status string, limit int) ([]domain.OutboxMessage, error) {
+	var rows []db.TblOutboxMessage
+	err := r.db.WithContext(ctx).Where("status = ?", status).
+		Order("id").
+		Limit(limit).
+		Find(&rows).Error
+	if err != nil {
+		logging.FromContext(ctx).Error("Error selecting OutboxMessage records", "status", status, "error", err)
+		return nil, err
+	}
+	messages := make([]domain.OutboxMessage, 0, len(rows))
+	for _, row := range rows {
+		messages = append(messages, toDomain(row))
+	}
+	return messages, nil
+}
+
+// UpdateAttempt saves the outcome of a publish attempt.
+func (r *outboxRepository) UpdateAttempt(ctx context.Context, message *domain.OutboxMessage) error {
+	row := toRow(message)
+	return r.db.WithContext(ctx).Model(&row).
+		Select("status", "attempts", "error", "next_attempt_at", "sent_at").
+		Updates(&row).Error
+}
+
+// CountByStatus counts the messages in a status.
+func (r *outboxRepository) CountByStatus(ctx context.Context, status string) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&db.TblOutboxMessage{}).Where("status = ?", status).Count(&count).Error
+	return count, err
+}
+
+func toRow(message *domain.OutboxMessage) db.TblOutboxMessage {
+	return db.TblOutboxMessage{
+		ID:              message.ID,
+		ReferenceNumber: message.ReferenceNumber,
+		Project:         message.Project,
+		Priority:        message.Priority,
+		Payload:         message.Payload,
+		Status:          message.Status,
+		Attempts:        message.Attempts,
+		Error:           message.Error,
+		NextAttemptAt:   message.NextAttemptAt,
+		SentAt:          message.SentAt,
+		CreatedAt:       message.CreatedAt,
+	}
+}
+
+func toDomain(row db.TblOutboxMessage) domain.OutboxMessage {
+	return domain.OutboxMessage{
+		ID:              row.ID,
+		ReferenceNumber: row.ReferenceNumber,
+		Project:         row.Project,
+		Priority:        row.Priority,
+		Payload:         row.Payload,
+		Status:          row.Status,
+		Attempts:        row.Attempts,
+		Error:           row.Error,
+		NextAttemptAt:   row.NextAttemptAt,
+		SentAt:          row.SentAt,
+		CreatedAt:       row.CreatedAt,
+	}
+}
@@ -0,0 +1,51 @@
+package outboxRepo_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"service-test-runner/internal/db/dbtest"
+	"service-test-runner/internal/domain"
+	outboxRepo "service-test-runner/internal/repository/outbox"
+)
+
+func TestGetOldestAndUpdateAttempt(t *testing.T) {
+	ctx := context.Background()
+	repo := outboxRepo.NewOutboxRepository(dbtest.Open(t))
+	now := time.Now()
+
+	for _, m := range []domain.OutboxMessage{
+		{ReferenceNumber: "ref-1", NextAttemptAt: now.Add(-time.Minute)},
+		{ReferenceNumber: "ref-2", NextAttemptAt: now.Add(time.Minute)},
+		{ReferenceNumber: "ref-3", NextAttemptAt: now.Add(-time.Second)},
+	} {
+		m.Project, m.Priority, m.Payload, m.Status = "web1", domain.RunPriorityNormal, "{}", domain.OutboxMessagePending
+		if err := repo.Create(ctx, &m); err != nil {
+			t.Fatal(err)
+		}
+		if m.ID == 0 {
+			t.Fatal("created message has no ID")
+		}
+	}
+
+	due, err := repo.GetOldest(ctx, domain.OutboxMessagePending, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(due) != 3 || due[0].ReferenceNumber != "ref-1" || due[1].ReferenceNumber != "ref-2" || due[2].ReferenceNumber != "ref-3" {
+		t.Fatalf("GetOldest = %+v, want ref-1, ref-2 and ref-3", due)
+	}
+
+	sentAt := now
+	due[0].Status, due[0].Attempts, due[0].SentAt = domain.OutboxMessageSent, 1, &sentAt
+	if err := repo.UpdateAttempt(ctx, &due[0]); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := repo.CountByStatus(ctx, domain.OutboxMessagePending); err != nil || n != 2 {
+		t.Errorf("pending = %d, %v; want 2", n, err)
+	}
+	if n, err := repo.CountByStatus(ctx, domain.OutboxMessageSent); err != nil || n != 1 {
+		t.Errorf("sent = %d, %v; want 1", n, err)
+	}
+}
@@ -1,19 +1,128 @@
 package project
 
 import (
+	"context"
+	"errors"
+	"sync"
+
+	"service-test-runner/internal/db"
 	"service-test-runner/internal/domain"
+	"service-test-runner/internal/infrastructure/logging"
+
+	"gorm.io/gorm"
 )
 
-type ProjectRepository struct {
+// ProjectRepository defines the repository interface for the projects and their runners.
+type ProjectRepository interface {
+	LoadProjects(ctx context.Context) (map[string]string, error)
+	LoadGridHubs(ctx context.Context) (map[string]string, error)
+	LoadRunnerNodes(ctx context.Context) (map[string][]domain.RunnerNode, error)
+	GetAll(ctx context.Context) ([]domain.Project, error)
+	GetByName(ctx context.Context, name string) (*domain.Project, error)
+	ShowProject() (domain.ShowProjectResponse, error)
+}
+
+// projectRepository is the concrete implementation. It remembers the projects loaded
+// at startup, which are the ones the service has runners for.
+type projectRepository struct {
+	db *gorm.DB
+
+	mu       sync.RWMutex
 	projects map[string]string
 }
 
-func NewProjectRepository(projects map[string]string) *ProjectRepository {
-	return &ProjectRepository{projects: projects}
+// NewProjectRepository creates a new instance of the repository.
+func NewProjectRepository(gdb *gorm.DB) ProjectRepository {
+	return &projectRepository{db: gdb}
 }
 
-// ShowProject converts the projects map into a slice of ProjectResponse.
-func (s *ProjectRepository) ShowProject() (domain.ShowProjectResponse, error) {
+// LoadProjects queries the tbl_project table and returns a mapping of project name to URL.
+func (s *projectRepository) LoadProjects(ctx context.Context) (map[string]string, error) {
+	var rows []db.TblProjects
+	if err := s.db.WithContext(ctx).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	projectMap := make(map[string]string)
+	for _, p := range rows {
+		projectMap[p.Name] = p.URL
+	}
+	logging.FromContext(ctx).Info("Loaded projects from DB", "projects", len(projectMap))
+
+	s.mu.Lock()
+	s.projects = projectMap
+	s.mu.Unlock()
+	return projectMap, nil
+}
+
+// LoadGridHubs queries the tbl_project table and returns a mapping of project name to
+// Selenium Grid hub URL, for the projects that have one.
+func (s *projectRepository) LoadGridHubs(ctx context.Context) (map[string]string, error) {
+	var rows []db.TblProjects
+	if err := s.db.WithContext(ctx).Where("grid_url IS NOT NULL AND grid_url <> ''").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	hubs := make(map[string]string, len(rows))
+	for _, p := range rows {
+		hubs[p.Name] = p.GridURL
+	}
+	return hubs, nil
+}
+
+// LoadRunnerNodes queries the enabled runner nodes and groups them by project.
+func (s *projectRepository) LoadRunnerNodes(ctx context.Context) (map[string][]domain.RunnerNode, error) {
+	var rows []db.TblRunnerNode
+	if err := s.db.WithContext(ctx).Where("enabled = ?", true).Order("project").Order("id").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	nodeMap := make(map[string][]domain.RunnerNode)
+	for _, n := range rows {
+		nodeMap[n.Project] = append(nodeMap[n.Project], domain.RunnerNode{
+			ID:      n.ID,
+			Project: n.Project,
+			URL:     n.URL,
+			Weight:  n.Weight,
+		})
+	}
+	logging.FromContext(ctx).Info("Loaded runner nodes from DB", "nodes", len(rows), "projects", len(nodeMap))
+	return nodeMap, nil
+}
+
+// GetAll fetches every project from the database ordered by name, with its current runner capacity.
+func (s *projectRepository) GetAll(ctx context.Context) ([]domain.Project, error) {
+	var rows []db.TblProjects
+	if err := s.db.WithContext(ctx).Order("name").Find(&rows).Error; err != nil {
+		logging.FromContext(ctx).Error("Error selecting projects", "error", err)
+		return nil, err
+	}
+	projects := make([]domain.Project, 0, len(rows))
+	for _, row := range rows {
+		projects = append(projects, toDomain(row))
+	}
+	return projects, nil
+}
+
+// GetByName fetches a project from the database, or nil if there is none.
+func (s *projectRepository) GetByName(ctx context.Context, name string) (*domain.Project, error) {
+	var row db.TblProjects
+	err := s.db.WithContext(ctx).Where("name = ?", name).First(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("Error selecting project", "project", name, "error", err)
+		return nil, err
+	}
+	project := toDomain(row)
+	return &project, nil
+}
+
+// ShowProject converts the projects loaded by LoadProjects into a slice of ProjectResponse.
+func (s *projectRepository) ShowProject() (domain.ShowProjectResponse, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	var resp domain.ShowProjectResponse
 	for name, url := range s.projects {
 		resp = append(resp, domain.ProjectResponse{
@@ -23,3 +132,13 @@ func (s *ProjectRepository) ShowProject() (domain.ShowProjectResponse, error) {
 	}
 	return resp, nil
 }
+
+func toDomain(row db.TblProjects) domain.Project {
+	return domain.Project{
+		ID:                row.ID,
+		Name:              row.Name,
+		URL:               row.URL,
+		GridURL:           row.GridURL,
+		MaxConcurrentRuns: row.MaxConcurrentRuns,
+	}
+}
@@ -0,0 +1,70 @@
+package project_test
+
+import (
+	"context"
+	"testing"
+
+	"service-test-runner/internal/db/dbtest"
+	"service-test-runner/internal/repository/project"
+)
+
+func TestLoadProjectsAndNodes(t *testing.T) {
+	ctx := context.Background()
+	gdb := dbtest.Open(t)
+	repo := project.NewProjectRepository(gdb)
+
+	err := gdb.Exec("UPDATE tbl_projects SET grid_url = ?, max_concurrent_runs = ? WHERE name = ?", "http://grid:4444", 3, "web1").Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = gdb.Exec("INSERT INTO tbl_runner_nodes (project, url, weight, enabled) VALUES (?, ?, ?, ?), (?, ?, ?, ?)",
+		"web1", "http://localhost:5002", 2, true,
+		"web1", "http://localhost:5003", 1, false).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	projects, err := repo.LoadProjects(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(projects) != 2 || projects["web1"] != "http://localhost:5000" {
+		t.Errorf("LoadProjects = %v", projects)
+	}
+	shown, err := repo.ShowProject()
+	if err != nil || len(shown) != 2 {
+		t.Errorf("ShowProject = %v, %v", shown, err)
+	}
+
+	hubs, err := repo.LoadGridHubs(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hubs) != 1 || hubs["web1"] != "http://grid:4444" {
+		t.Errorf("LoadGridHubs = %v", hubs)
+	}
+
+	nodes, err := repo.LoadRunnerNodes(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	web := nodes["web1"]
+	if len(web) != 2 || web[0].URL != "http://localhost:5000" || web[1].URL != "http://localhost:5002" || web[1].Weight != 2 {
+		t.Errorf("web1 nodes = %+v, want the seeded node and the enabled one", web)
+	}
+
+	p, err := repo.GetByName(ctx, "web1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p == nil || p.MaxConcurrentRuns != 3 || p.GridURL != "http://grid:4444" {
+		t.Errorf("GetByName = %+v", p)
+	}
+	if p, err := repo.GetByName(ctx, "missing"); err != nil || p != nil {
+		t.Errorf("GetByName(missing) = %+v, %v; want nil", p, err)
+	}
+	all, err := repo.GetAll(ctx)
+	if err != nil || len(all) != 2 || all[0].Name != "mobile1" {
+		t.Errorf("GetAll = %+v, %v; want mobile1 first", all, err)
+	}
+}
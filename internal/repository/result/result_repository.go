@@ -0,0 +1,119 @@
+package resultRepo
+
+import (
+	"context"
+
+	"service-test-runner/internal/db"
+	"service-test-runner/internal/domain"
+	"service-test-runner/internal/infrastructure/logging"
+
+	"gorm.io/gorm"
+)
+
+// ResultRepository defines the repository interface for structured test results.
+type ResultRepository interface {
+	Replace(ctx context.Context, referenceNumber string, suites []domain.TestSuiteResult) error
+	GetByReferenceNumber(ctx context.Context, referenceNumber string) ([]domain.TestSuiteResult, error)
+}
+
+// resultRepository is the concrete implementation.
+type resultRepository struct {
+	db *gorm.DB
+}
+
+// NewResultRepository creates a new instance of the repository.
+func NewResultRepository(gdb *gorm.DB) ResultRepository {
+	return &resultRepository{db: gdb}
+}
+
+// Replace deletes the stored results of a run and inserts the test cases of the given
+// suites, including their steps, in a single transaction.
+func (r *resultRepository) Replace(ctx context.Context, referenceNumber string, suites []domain.TestSuiteResult) error {
+	var rows []db.TblTestResult
+	for _, suite := range suites {
+		for _, tc := range suite.Cases {
+			row := db.TblTestResult{
+				ReferenceNumber: referenceNumber,
+				Suite:           suite.Name,
+				Name:            tc.Name,
+				ClassName:       tc.ClassName,
+				Status:          tc.Status,
+				Duration:        tc.Duration,
+				FailureMessage:  tc.FailureMessage,
+				StackTrace:      tc.StackTrace,
+			}
+			for _, step := range tc.Steps {
+				row.Steps = append(row.Steps, db.TblStepResult{
+					ReferenceNumber: referenceNumber,
+					Keyword:         step.Keyword,
+					Name:            step.Name,
+					Status:          step.Status,
+					Duration:        step.Duration,
+					ErrorMessage:    step.ErrorMessage,
+				})
+			}
+			rows = append(rows, row)
+		}
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("reference_number = ?", referenceNumber).Delete(&db.TblStepResult{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("reference_number = ?", referenceNumber).Delete(&db.TblTestResult{}).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		return tx.Create(&rows).Error
+	})
+	if err != nil {
+		logging.FromContext(ctx).Error("Error replacing TestResult records", "reference_number", referenceNumber, "error", err)
+	}
+	return err
+}
+
+// GetByReferenceNumber fetches all test case results of a run, with their steps, grouped
+// by suite in insertion order. The suite durations are summed up, their counts are not set.
+func (r *resultRepository) GetByReferenceNumber(ctx context.Context, referenceNumber string) ([]domain.TestSuiteResult, error) {
+	var rows []db.TblTestResult
+	err := r.db.WithContext(ctx).Preload("Steps", func(tx *gorm.DB) *gorm.DB {
+		return tx.Order("id")
+	}).Where("reference_number = ?", referenceNumber).Order("id").Find(&rows).Error
+	if err != nil {
+		logging.FromContext(ctx).Error("Error selecting TestResult records", "reference_number", referenceNumber, "error", err)
+		return nil, err
+	}
+
+	var suites []domain.TestSuiteResult
+	index := make(map[string]int)
+	for _, row := range rows {
+		i, ok := index[row.Suite]
+		if !ok {
+			i = len(suites)
+			index[row.Suite] = i
+			suites = append(suites, domain.TestSuiteResult{Name: row.Suite})
+		}
+		suites[i].Duration += row.Duration
+		tc := domain.TestCaseResult{
+			Name:           row.Name,
+			ClassName:      row.ClassName,
+			Status:         row.Status,
+			Duration:       row.Duration,
+			FailureMessage: row.FailureMessage,
+			StackTrace:     row.StackTrace,
+		}
+		for _, step := range row.Steps {
+			tc.Steps = append(tc.Steps, domain.StepResult{
+				Keyword:      step.Keyword,
+				Name:         step.Name,
+				Status:       step.Status,
+				Duration:     step.Duration,
+				ErrorMessage: step.ErrorMessage,
+			})
+		}
+		suites[i].Cases = append(suites[i].Cases, tc)
+	}
+	return suites, nil
+}
@@ -0,0 +1,54 @@
+package resultRepo_test
+
+import (
+	"context"
+	"testing"
+
+	"service-test-runner/internal/db/dbtest"
+	"service-test-runner/internal/domain"
+	resultRepo "service-test-runner/internal/repository/result"
+)
+
+func TestReplaceAndGet(t *testing.T) {
+	ctx := context.Background()
+	repo := resultRepo.NewResultRepository(dbtest.Open(t))
+
+	first := []domain.TestSuiteResult{{Name: "old", Cases: []domain.TestCaseResult{{Name: "gone", Status: domain.TestStatusPassed}}}}
+	if err := repo.Replace(ctx, "ref-1", first); err != nil {
+		t.Fatal(err)
+	}
+	second := []domain.TestSuiteResult{
+		{Name: "login", Cases: []domain.TestCaseResult{
+			{Name: "valid user", Status: domain.TestStatusPassed, Duration: 1.5, Steps: []domain.StepResult{
+				{Keyword: "Given ", Name: "a user", Status: domain.TestStatusPassed},
+				{Keyword: "When ", Name: "they log in", Status: domain.TestStatusPassed},
+			}},
+			{Name: "bad password", Status: domain.TestStatusFailed, Duration: 0.5, FailureMessage: "expected error"},
+		}},
+		{Name: "search", Cases: []domain.TestCaseResult{{Name: "by name", Status: domain.TestStatusSkipped}}},
+	}
+	if err := repo.Replace(ctx, "ref-1", second); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Replace(ctx, "ref-2", first); err != nil {
+		t.Fatal(err)
+	}
+
+	suites, err := repo.GetByReferenceNumber(ctx, "ref-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(suites) != 2 || suites[0].Name != "login" || suites[1].Name != "search" {
+		t.Fatalf("suites = %+v, want login then search", suites)
+	}
+	login := suites[0]
+	if login.Duration != 2 || len(login.Cases) != 2 || login.Cases[1].FailureMessage != "expected error" {
+		t.Errorf("login = %+v", login)
+	}
+	if steps := login.Cases[0].Steps; len(steps) != 2 || steps[1].Name != "they log in" {
+		t.Errorf("steps = %+v", steps)
+	}
+	if other, err := repo.GetByReferenceNumber(ctx, "ref-2"); err != nil || len(other) != 1 || other[0].Name != "old" {
+		t.Errorf("results of another run = %+v, %v", other, err)
+	}
+}
@@ -0,0 +1,67 @@
+package retentionRepo
+
+import (
+	"context"
+	"errors"
+
+	"service-test-runner/internal/db"
+	"service-test-runner/internal/domain"
+	"service-test-runner/internal/infrastructure/logging"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RetentionRepository defines the repository interface for per-project retention policies.
+type RetentionRepository interface {
+	GetByProject(ctx context.Context, project string) (*domain.RetentionPolicy, error)
+	Save(ctx context.Context, policy *domain.RetentionPolicy) error
+}
+
+// retentionRepository is the concrete implementation.
+type retentionRepository struct {
+	db *gorm.DB
+}
+
+// NewRetentionRepository creates a new instance of the repository.
+func NewRetentionRepository(gdb *gorm.DB) RetentionRepository {
+	return &retentionRepository{db: gdb}
+}
+
+// GetByProject fetches the policy of a project, or nil if it has none.
+func (r *retentionRepository) GetByProject(ctx context.Context, project string) (*domain.RetentionPolicy, error) {
+	var row db.TblRetentionPolicy
+	err := r.db.WithContext(ctx).Where("project = ?", project).First(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("Error selecting RetentionPolicy record", "project", project, "error", err)
+		return nil, err
+	}
+	return &domain.RetentionPolicy{
+		Project:          row.Project,
+		KeepLastRuns:     row.KeepLastRuns,
+		MaxAgeDays:       row.MaxAgeDays,
+		FailedMaxAgeDays: row.FailedMaxAgeDays,
+	}, nil
+}
+
+// Save inserts the policy of a project or replaces its limits.
+func (r *retentionRepository) Save(ctx context.Context, policy *domain.RetentionPolicy) error {
+	row := db.TblRetentionPolicy{
+		Project:          policy.Project,
+		KeepLastRuns:     policy.KeepLastRuns,
+		MaxAgeDays:       policy.MaxAgeDays,
+		FailedMaxAgeDays: policy.FailedMaxAgeDays,
+	}
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "project"}},
+		DoUpdates: clause.AssignmentColumns([]string{"keep_last_runs", "max_age_days", "failed_max_age_days", "updated_at"}),
+	}).Create(&row).Error
+	if err != nil {
+		logging.FromContext(ctx).Error("Error upserting RetentionPolicy record", "project", policy.Project, "error", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package retentionRepo_test
+
+import (
+	"context"
+	"testing"
+
+	"service-test-runner/internal/db/dbtest"
+	"service-test-runner/internal/domain"
+	retentionRepo "service-test-runner/internal/repository/retention"
+)
+
+func TestSaveReplacesPolicy(t *testing.T) {
+	ctx := context.Background()
+	repo := retentionRepo.NewRetentionRepository(dbtest.Open(t))
+
+	if policy, err := repo.GetByProject(ctx, "web1"); err != nil || policy != nil {
+		t.Fatalf("GetByProject before Save = %+v, %v; want nil", policy, err)
+	}
+	for _, keep := range []int{3, 5} {
+		if err := repo.Save(ctx, &domain.RetentionPolicy{Project: "web1", KeepLastRuns: keep, MaxAgeDays: 30}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	policy, err := repo.GetByProject(ctx, "web1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := domain.RetentionPolicy{Project: "web1", KeepLastRuns: 5, MaxAgeDays: 30}
+	if policy == nil || *policy != want {
+		t.Fatalf("GetByProject = %+v, want %+v", policy, want)
+	}
+}
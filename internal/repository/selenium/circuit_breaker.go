@@ -0,0 +1,88 @@
+package selenium
+
+import (
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"service-test-runner/internal/infrastructure/metrics"
+)
+
+// ErrCircuitOpen is returned, without calling the runner, while the circuit breaker of
+// its project is open.
+var ErrCircuitOpen = errors.New("runner circuit breaker is open")
+
+// circuitBreaker stops the calls to the runners of a project once threshold calls in a
+// row have failed. After cooldown a single trial call is let through: the circuit closes
+// again when it succeeds, and stays open for another cooldown when it fails.
+type circuitBreaker struct {
+	project   string
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	trial     bool // the trial call is in flight
+}
+
+// newCircuitBreaker creates the circuit breaker of a project; a threshold of 0 disables it.
+func newCircuitBreaker(project string, threshold int, cooldown time.Duration) *circuitBreaker {
+	return &circuitBreaker{project: project, threshold: threshold, cooldown: cooldown, now: time.Now}
+}
+
+// allow reports whether a call may go to the runners. Each allowed call must be ended
+// with record or abandon.
+func (b *circuitBreaker) allow() bool {
+	if b.threshold <= 0 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.failures < b.threshold {
+		return true
+	}
+	if b.trial || b.now().Before(b.openUntil) {
+		return false
+	}
+	b.trial = true
+	return true
+}
+
+// record ends a call with its outcome.
+func (b *circuitBreaker) record(success bool) {
+	if b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+	if success {
+		if b.failures >= b.threshold {
+			slog.Info("Runner circuit breaker closed", "project", b.project)
+			metrics.SetRunnerCircuitOpen(b.project, false)
+		}
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= b.threshold {
+		if b.failures == b.threshold {
+			slog.Warn("Runner circuit breaker opened", "project", b.project, "failures", b.failures, "cooldown", b.cooldown)
+			metrics.SetRunnerCircuitOpen(b.project, true)
+		}
+		b.openUntil = b.now().Add(b.cooldown)
+	}
+}
+
+// abandon ends a call that was cancelled by its caller, which says nothing of the runners.
+func (b *circuitBreaker) abandon() {
+	if b.threshold <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.trial = false
+}
@@ -0,0 +1,153 @@
+package selenium
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"service-test-runner/internal/config"
+	"service-test-runner/internal/domain"
+)
+
+// fakeClock is a clock that only moves when told to.
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func newTestBreaker(threshold int) (*circuitBreaker, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2025, 3, 15, 21, 0, 0, 0, time.UTC)}
+	b := newCircuitBreaker("web1", threshold, 30*time.Second)
+	b.now = clock.Now
+	return b, clock
+}
+
+// call makes one call through the breaker if it allows it, and reports whether it did.
+func call(b *circuitBreaker, success bool) bool {
+	if !b.allow() {
+		return false
+	}
+	b.record(success)
+	return true
+}
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	b, clock := newTestBreaker(3)
+
+	// Closed: failures below the threshold, or broken by a success, keep it closed.
+	for _, success := range []bool{false, false, true, false, false} {
+		if !call(b, success) {
+			t.Fatal("closed circuit refused a call")
+		}
+	}
+	// Open: the third failure in a row opens it for the cooldown.
+	if !call(b, false) {
+		t.Fatal("closed circuit refused a call")
+	}
+	clock.now = clock.now.Add(29 * time.Second)
+	if b.allow() {
+		t.Fatal("open circuit allowed a call within the cooldown")
+	}
+
+	// Half-open: a failed trial keeps it open for another cooldown.
+	clock.now = clock.now.Add(time.Second)
+	if !call(b, false) {
+		t.Fatal("circuit refused the trial call after the cooldown")
+	}
+	if b.allow() {
+		t.Fatal("circuit allowed a call right after a failed trial")
+	}
+
+	// A successful trial closes it.
+	clock.now = clock.now.Add(30 * time.Second)
+	if !call(b, true) {
+		t.Fatal("circuit refused the trial call after the cooldown")
+	}
+	for i := 0; i < 2; i++ {
+		if !call(b, false) {
+			t.Fatal("circuit closed by a trial refused a call")
+		}
+	}
+}
+
+func TestCircuitBreakerLetsOneTrialThrough(t *testing.T) {
+	b, clock := newTestBreaker(1)
+	call(b, false)
+	clock.now = clock.now.Add(30 * time.Second)
+
+	if !b.allow() {
+		t.Fatal("circuit refused the trial call after the cooldown")
+	}
+	if b.allow() {
+		t.Fatal("circuit allowed a second call while the trial is in flight")
+	}
+
+	// A trial abandoned by its caller lets the next call be the trial.
+	b.abandon()
+	if !b.allow() {
+		t.Fatal("circuit refused a trial after the previous one was abandoned")
+	}
+	if b.allow() {
+		t.Fatal("circuit allowed a second call while the trial is in flight")
+	}
+	b.record(true)
+	if !b.allow() {
+		t.Fatal("circuit refused a call after a successful trial")
+	}
+}
+
+func TestCircuitBreakerDisabled(t *testing.T) {
+	b, _ := newTestBreaker(0)
+	for i := 0; i < 10; i++ {
+		if !call(b, false) {
+			t.Fatal("disabled circuit breaker refused a call")
+		}
+	}
+}
+
+func TestOnlyGetRequestsAreRetried(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(repo SeleniumRepository) error
+		want int32
+	}{
+		{"testsuites", func(repo SeleniumRepository) error {
+			_, err := repo.GetTestSuites(context.Background(), "web1")
+			return err
+		}, 3},
+		{"testsuite_detail", func(repo SeleniumRepository) error {
+			_, err := repo.GetTestSuiteDetail(context.Background(), "web1", "suite1")
+			return err
+		}, 1},
+		{"run", func(repo SeleniumRepository) error {
+			_, err := repo.RunAutomation(context.Background(), "web1", "suite1", "", "ref-1", domain.Browser{})
+			return err
+		}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := newFakeRunner(t, http.StatusServiceUnavailable)
+			repo := NewSeleniumRepository(map[string]string{"web1": runner.URL}, nil, fakeRuns{},
+				config.RunnerConfig{Strategy: StrategyLeastBusy, GetRetries: 2, RetryBackoff: time.Millisecond, BreakerThreshold: 1, BreakerCooldown: time.Minute})
+
+			var runnerErr *RunnerError
+			if err := tt.call(repo); !errors.As(err, &runnerErr) || runnerErr.StatusCode != http.StatusServiceUnavailable {
+				t.Fatalf("call = %v, want the runner's 503", err)
+			}
+			if got := runner.hits.Load(); got != tt.want {
+				t.Fatalf("runner called %d times, want %d", got, tt.want)
+			}
+
+			// Retries count as one call for the circuit breaker, which the failure opened.
+			if err := tt.call(repo); !errors.Is(err, ErrCircuitOpen) {
+				t.Fatalf("call after the failure = %v, want ErrCircuitOpen", err)
+			}
+			if got := runner.hits.Load(); got != tt.want {
+				t.Fatalf("runner called %d times with the circuit open, want %d", got, tt.want)
+			}
+		})
+	}
+}
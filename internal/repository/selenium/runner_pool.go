@@ -0,0 +1,143 @@
+package selenium
+
+import (
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"service-test-runner/internal/config"
+	"service-test-runner/internal/domain"
+)
+
+// Runner node selection strategies, see config.RunnerConfig.
+const (
+	StrategyLeastBusy  = "least_busy"
+	StrategyRoundRobin = "round_robin"
+)
+
+// runnerNode is one runner endpoint in the pool of a project.
+type runnerNode struct {
+	url       string
+	weight    int
+	current   int       // smooth weighted round-robin credit
+	starting  int       // runs being sent to the node by this instance
+	downUntil time.Time // the node is tried last until then, after a failure
+}
+
+// runnerPool holds the runner nodes of a project, their health and the circuit breaker
+// of the project.
+type runnerPool struct {
+	strategy string
+	cooldown time.Duration
+	breaker  *circuitBreaker
+
+	mu    sync.Mutex
+	nodes []*runnerNode
+}
+
+// newRunnerPool builds the pool of a project from its runner nodes, or from the
+// single URL of the project when it has none.
+func newRunnerPool(project, projectURL string, nodes []domain.RunnerNode, cfg config.RunnerConfig) *runnerPool {
+	pool := &runnerPool{
+		strategy: cfg.Strategy,
+		cooldown: cfg.FailureCooldown,
+		breaker:  newCircuitBreaker(project, cfg.BreakerThreshold, cfg.BreakerCooldown),
+	}
+	if pool.strategy != StrategyLeastBusy && pool.strategy != StrategyRoundRobin {
+		slog.Warn("Unknown runner strategy, using least_busy", "project", project, "strategy", cfg.Strategy)
+		pool.strategy = StrategyLeastBusy
+	}
+	for _, n := range nodes {
+		weight := n.Weight
+		if weight < 1 {
+			weight = 1
+		}
+		pool.nodes = append(pool.nodes, &runnerNode{url: n.URL, weight: weight})
+	}
+	if len(pool.nodes) == 0 && projectURL != "" {
+		pool.nodes = append(pool.nodes, &runnerNode{url: projectURL, weight: 1})
+	}
+	return pool
+}
+
+// order returns the nodes in the order they should be tried, the selected one first.
+// Nodes in their failure cooldown come last, so they are only tried once every healthy
+// node has failed too. active holds the runs in progress per node URL for least_busy.
+func (p *runnerPool) order(active map[string]int64) []*runnerNode {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	var up, down []*runnerNode
+	for _, n := range p.nodes {
+		if now.Before(n.downUntil) {
+			down = append(down, n)
+		} else {
+			up = append(up, n)
+		}
+	}
+	if len(up) == 0 {
+		return down
+	}
+
+	switch p.strategy {
+	case StrategyRoundRobin:
+		first := p.nextRoundRobin(up)
+		ordered := []*runnerNode{first}
+		for _, n := range up {
+			if n != first {
+				ordered = append(ordered, n)
+			}
+		}
+		up = ordered
+	default:
+		load := func(n *runnerNode) float64 {
+			return float64(active[n.url]+int64(n.starting)) / float64(n.weight)
+		}
+		sort.SliceStable(up, func(i, j int) bool { return load(up[i]) < load(up[j]) })
+	}
+	return append(up, down...)
+}
+
+// nextRoundRobin picks a node by smooth weighted round-robin: over any sequence of
+// picks each node is chosen in proportion to its weight, without bursts.
+func (p *runnerPool) nextRoundRobin(nodes []*runnerNode) *runnerNode {
+	total := 0
+	var best *runnerNode
+	for _, n := range nodes {
+		n.current += n.weight
+		total += n.weight
+		if best == nil || n.current > best.current {
+			best = n
+		}
+	}
+	best.current -= total
+	return best
+}
+
+// begin counts a run being sent to a node until the returned func is called.
+func (p *runnerPool) begin(n *runnerNode) func() {
+	p.mu.Lock()
+	n.starting++
+	p.mu.Unlock()
+	return func() {
+		p.mu.Lock()
+		n.starting--
+		p.mu.Unlock()
+	}
+}
+
+// failed puts a node in its failure cooldown.
+func (p *runnerPool) failed(n *runnerNode) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n.downUntil = time.Now().Add(p.cooldown)
+}
+
+// succeeded ends the failure cooldown of a node.
+func (p *runnerPool) succeeded(n *runnerNode) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n.downUntil = time.Time{}
+}
@@ -0,0 +1,172 @@
+package selenium
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"service-test-runner/internal/config"
+	"service-test-runner/internal/domain"
+	automationRepo "service-test-runner/internal/repository/automation"
+)
+
+// fakeRuns reports the runs in progress on each runner node.
+type fakeRuns struct {
+	automationRepo.QueueAutomationRepository
+	active map[string]int64
+}
+
+func (f fakeRuns) CountByRunner(ctx context.Context, project string, status int) (map[string]int64, error) {
+	return f.active, nil
+}
+
+// fakeRunner is a runner node answering every call with status.
+type fakeRunner struct {
+	*httptest.Server
+	hits atomic.Int32
+}
+
+func newFakeRunner(t *testing.T, status int) *fakeRunner {
+	t.Helper()
+	r := &fakeRunner{}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.hits.Add(1)
+		w.WriteHeader(status)
+		w.Write([]byte(`{"status": "success", "message": "", "data": {"running_id": "run-1", "reference_number": "ref-1"}}`))
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+// unreachableURL returns the URL of a server that is no longer listening.
+func unreachableURL(t *testing.T) string {
+	t.Helper()
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	return server.URL
+}
+
+func urls(nodes []*runnerNode) []string {
+	out := make([]string, len(nodes))
+	for i, n := range nodes {
+		out[i] = n.url
+	}
+	return out
+}
+
+func TestRunnerPoolOrder(t *testing.T) {
+	nodes := []domain.RunnerNode{{URL: "http://a", Weight: 1}, {URL: "http://b", Weight: 2}, {URL: "http://c", Weight: 1}}
+	tests := []struct {
+		name     string
+		strategy string
+		active   map[string]int64
+		down     string
+		want     []string
+	}{
+		{"least busy first", StrategyLeastBusy, map[string]int64{"http://a": 2, "http://b": 1, "http://c": 0}, "", []string{"http://c", "http://b", "http://a"}},
+		{"load relative to weight", StrategyLeastBusy, map[string]int64{"http://a": 1, "http://b": 1, "http://c": 1}, "", []string{"http://b", "http://a", "http://c"}},
+		{"unhealthy node last", StrategyLeastBusy, nil, "http://a", []string{"http://b", "http://c", "http://a"}},
+		{"round robin by weight", StrategyRoundRobin, nil, "", []string{"http://b", "http://a", "http://c"}},
+		{"unknown strategy", "random", map[string]int64{"http://a": 1}, "", []string{"http://b", "http://c", "http://a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pool := newRunnerPool("web1", "", nodes, config.RunnerConfig{Strategy: tt.strategy, FailureCooldown: time.Minute})
+			for _, n := range pool.nodes {
+				if n.url == tt.down {
+					pool.failed(n)
+				}
+			}
+			got := urls(pool.order(tt.active))
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Fatalf("order = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRunnerPoolRoundRobinFollowsWeights(t *testing.T) {
+	pool := newRunnerPool("web1", "", []domain.RunnerNode{{URL: "http://a", Weight: 1}, {URL: "http://b", Weight: 3}}, config.RunnerConfig{Strategy: StrategyRoundRobin, FailureCooldown: time.Minute})
+	picks := map[string]int{}
+	for i := 0; i < 8; i++ {
+		picks[pool.order(nil)[0].url]++
+	}
+	if picks["http://a"] != 2 || picks["http://b"] != 6 {
+		t.Fatalf("picks = %v, want 2 for a and 6 for b", picks)
+	}
+
+	// A node in its failure cooldown is never selected while another one is up.
+	pool.failed(pool.nodes[1])
+	for i := 0; i < 3; i++ {
+		if got := pool.order(nil)[0].url; got != "http://a" {
+			t.Fatalf("selected %s while b is down", got)
+		}
+	}
+	pool.succeeded(pool.nodes[1])
+	if got := urls(pool.order(nil)); len(got) != 2 {
+		t.Fatalf("order after recovery = %v", got)
+	}
+}
+
+func TestRunAutomationFailover(t *testing.T) {
+	tests := []struct {
+		name       string
+		first      int // status of the first node, 0 when it cannot be reached
+		second     int
+		wantErr    error
+		wantRunner int // the node that took the run, 1 or 2, 0 for none
+		firstDown  bool
+	}{
+		{"first node takes the run", http.StatusOK, http.StatusOK, nil, 1, false},
+		{"connection error fails over", 0, http.StatusOK, nil, 2, true},
+		{"server error fails over", http.StatusBadGateway, http.StatusOK, nil, 2, true},
+		{"full node passes the run on", http.StatusTooManyRequests, http.StatusOK, nil, 2, false},
+		{"every node full", http.StatusTooManyRequests, http.StatusTooManyRequests, ErrRunnerBusy, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			second := newFakeRunner(t, tt.second)
+			var first *fakeRunner
+			firstURL := unreachableURL(t)
+			if tt.first != 0 {
+				first = newFakeRunner(t, tt.first)
+				firstURL = first.URL
+			}
+			nodes := []domain.RunnerNode{{URL: firstURL, Weight: 1}, {URL: second.URL, Weight: 1}}
+			// The second node is busier, so least_busy selects the first one.
+			runs := fakeRuns{active: map[string]int64{second.URL: 1}}
+			repo := NewSeleniumRepository(map[string]string{"web1": ""}, map[string][]domain.RunnerNode{"web1": nodes}, runs,
+				config.RunnerConfig{Strategy: StrategyLeastBusy, FailureCooldown: time.Minute, RunTimeout: 5 * time.Second})
+
+			resp, err := repo.RunAutomation(context.Background(), "web1", "suite1", "", "ref-1", domain.Browser{})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("RunAutomation = %v, want %v", err, tt.wantErr)
+			}
+			wantURL := map[int]string{1: firstURL, 2: second.URL}[tt.wantRunner]
+			if resp.RunnerURL != wantURL {
+				t.Fatalf("runner_url = %q, want %q", resp.RunnerURL, wantURL)
+			}
+			if first != nil && first.hits.Load() != 1 {
+				t.Fatalf("first node called %d times, want once", first.hits.Load())
+			}
+			wantHits := int32(1)
+			if tt.wantRunner == 1 {
+				wantHits = 0
+			}
+			if second.hits.Load() != wantHits {
+				t.Fatalf("second node called %d times, want %d", second.hits.Load(), wantHits)
+			}
+
+			pool := repo.(*seleniumRepository).pools["web1"]
+			if down := time.Now().Before(pool.nodes[0].downUntil); down != tt.firstDown {
+				t.Fatalf("first node in its failure cooldown = %v, want %v", down, tt.firstDown)
+			}
+		})
+	}
+}
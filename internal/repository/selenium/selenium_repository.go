@@ -2,61 +2,315 @@ package selenium
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"log/slog"
 	"net/http"
+	"strings"
+	"time"
 
+	"service-test-runner/internal/config"
 	"service-test-runner/internal/domain"
+	"service-test-runner/internal/infrastructure/logging"
+	"service-test-runner/internal/infrastructure/metrics"
+	"service-test-runner/internal/infrastructure/tracing"
 	repository "service-test-runner/internal/repository"
+	automationRepo "service-test-runner/internal/repository/automation"
 )
 
-type SeleniumRepository struct {
-	projects map[string]string
+// ErrRunnerBusy is returned by RunAutomation when the runner has no free slot (HTTP 429).
+var ErrRunnerBusy = errors.New("your request is queued")
+
+// maxErrorBody caps how much of a runner's answer is kept in a RunnerError.
+const maxErrorBody = 1024
+
+// RunnerError is returned when a runner answers a call with an unexpected status.
+type RunnerError struct {
+	Operation  string
+	URL        string
+	StatusCode int
+	Body       string
 }
 
-func NewSeleniumRepository(projects map[string]string) *SeleniumRepository {
-	return &SeleniumRepository{projects: projects}
+func (e *RunnerError) Error() string {
+	msg := fmt.Sprintf("runner %s answered %d %s to %s", e.URL, e.StatusCode, http.StatusText(e.StatusCode), e.Operation)
+	if e.Body != "" {
+		msg += ": " + e.Body
+	}
+	return msg
 }
 
-func (s *SeleniumRepository) getBaseURL(project string) (string, error) {
-	url, ok := s.projects[project]
-	if !ok {
-		return "", errors.New("project not found")
+// unexpectedStatus reads the answer of a runner node into a RunnerError.
+func unexpectedStatus(operation string, n *runnerNode, resp *http.Response) error {
+	data, _ := ioutil.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	return &RunnerError{
+		Operation:  operation,
+		URL:        n.url,
+		StatusCode: resp.StatusCode,
+		Body:       strings.TrimSpace(string(data)),
 	}
-	return url, nil
 }
 
-// RunAutomation calls POST /selenium/run with payload {"testsuite_id", "email"}.
-func (s *SeleniumRepository) RunAutomation(project, testsuiteID, email, refnum string) (domain.RunResponse, error) {
-	baseURL, err := s.getBaseURL(project)
+// SeleniumRepository calls the runner nodes of the projects.
+type SeleniumRepository interface {
+	Ping(ctx context.Context, project string) error
+	RunAutomation(ctx context.Context, project, testsuiteID, email, refnum string, browser domain.Browser) (domain.RunResponse, error)
+	GetTestSuites(ctx context.Context, project string) ([]string, error)
+	GetTestSuiteDetail(ctx context.Context, project, testsuiteName string) (domain.TestSuiteDetail, error)
+}
+
+// seleniumRepository is the concrete implementation. Every call goes to the node
+// selected by the pool of its project and fails over to the next ones when a node
+// cannot be reached or answers with a server error. Calls are cut off after the
+// timeouts of cfg, and skipped while the circuit breaker of their project is open.
+type seleniumRepository struct {
+	pools  map[string]*runnerPool
+	runs   automationRepo.QueueAutomationRepository
+	client *http.Client
+	cfg    config.RunnerConfig
+}
+
+// NewSeleniumRepository builds the runner pools from the project URLs and the enabled
+// runner nodes; a project without nodes is served by its own URL. runs is used to
+// count the runs in progress on each node.
+func NewSeleniumRepository(projects map[string]string, nodes map[string][]domain.RunnerNode, runs automationRepo.QueueAutomationRepository, cfg config.RunnerConfig) SeleniumRepository {
+	pools := make(map[string]*runnerPool, len(projects))
+	for project, url := range projects {
+		pools[project] = newRunnerPool(project, url, nodes[project], cfg)
+	}
+	return &seleniumRepository{
+		pools:  pools,
+		runs:   runs,
+		client: &http.Client{Transport: tracing.NewTransport(http.DefaultTransport, "runner")},
+		cfg:    cfg,
+	}
+}
+
+func (s *seleniumRepository) getPool(project string) (*runnerPool, error) {
+	pool, ok := s.pools[project]
+	if !ok || len(pool.nodes) == 0 {
+		return nil, errors.New("project not found")
+	}
+	return pool, nil
+}
+
+// send makes a call to the runners of a project through its circuit breaker. A call
+// that fails on every node is tried again up to retries times, which must only be
+// used for idempotent calls.
+func (s *seleniumRepository) send(ctx context.Context, logger *slog.Logger, project, operation string, pool *runnerPool, nodes []*runnerNode, retries int,
+	request func(n *runnerNode) (*http.Response, error), next func(resp *http.Response) bool) (*http.Response, *runnerNode, error) {
+	if !pool.breaker.allow() {
+		return nil, nil, fmt.Errorf("%w for project %s", ErrCircuitOpen, project)
+	}
+	backoff := s.cfg.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		resp, node, err := s.failover(ctx, logger, project, operation, pool, nodes, request, next)
+		failed := err != nil || resp.StatusCode >= http.StatusInternalServerError
+		if ctx.Err() != nil {
+			pool.breaker.abandon()
+			return resp, node, err
+		}
+		if !failed || attempt >= retries {
+			pool.breaker.record(!failed)
+			return resp, node, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		logger.Warn("Retrying runner request", "operation", operation, "attempt", attempt+1, "backoff", backoff)
+		select {
+		case <-ctx.Done():
+			pool.breaker.abandon()
+			return nil, nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// failover makes a request to each node in turn until one can be reached and answers
+// below 500, putting the others in their failure cooldown. A response for which next
+// reports true is also passed on to the following node. The answer of the last node
+// tried is returned as is, together with that node.
+func (s *seleniumRepository) failover(ctx context.Context, logger *slog.Logger, project, operation string, pool *runnerPool, nodes []*runnerNode,
+	request func(n *runnerNode) (*http.Response, error), next func(resp *http.Response) bool) (*http.Response, *runnerNode, error) {
+	var (
+		resp *http.Response
+		err  error
+	)
+	for i, n := range nodes {
+		start := time.Now()
+		resp, err = request(n)
+		observeRunnerRequest(logger.With("runner_url", n.url), project, operation, start, resp, err)
+		last := i == len(nodes)-1 || ctx.Err() != nil
+
+		if err != nil || resp.StatusCode >= http.StatusInternalServerError {
+			pool.failed(n)
+		} else {
+			pool.succeeded(n)
+			if next == nil || !next(resp) {
+				return resp, n, nil
+			}
+		}
+		if last {
+			return resp, n, err
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+		logger.Warn("Failing over to the next runner node", "operation", operation, "runner_url", n.url)
+	}
+	return nil, nil, errors.New("project not found")
+}
+
+// post sends a JSON payload to the runner, propagating the trace context of ctx.
+func (s *seleniumRepository) post(ctx context.Context, endpoint string, body []byte, timeout time.Duration) (*http.Response, error) {
+	return s.do(ctx, http.MethodPost, endpoint, bytes.NewReader(body), timeout)
+}
+
+// get calls the runner, propagating the trace context of ctx.
+func (s *seleniumRepository) get(ctx context.Context, endpoint string, timeout time.Duration) (*http.Response, error) {
+	return s.do(ctx, http.MethodGet, endpoint, nil, timeout)
+}
+
+// do makes a request that is cut off after timeout, reading the body included; the
+// caller's ctx can still cancel it earlier.
+func (s *seleniumRepository) do(ctx context.Context, method, endpoint string, body io.Reader, timeout time.Duration) (*http.Response, error) {
+	cancel := context.CancelFunc(func() {})
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelBody releases the timeout of a request when its body is closed.
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// Ping checks that the runner nodes of a project answer HTTP requests, and updates
+// their health accordingly. Any response below 500 counts, as runners expose no
+// dedicated health route. It fails only when no node answers.
+func (s *seleniumRepository) Ping(ctx context.Context, project string) error {
+	pool, err := s.getPool(project)
+	if err != nil {
+		return err
+	}
+	answered := false
+	var lastErr error
+	for _, n := range pool.order(nil) {
+		resp, err := s.get(ctx, n.url, s.cfg.RequestTimeout)
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode >= http.StatusInternalServerError {
+				err = fmt.Errorf("runner %s answered %s", n.url, resp.Status)
+			}
+		}
+		if err != nil {
+			pool.failed(n)
+			lastErr = err
+			continue
+		}
+		pool.succeeded(n)
+		answered = true
+	}
+	if !answered {
+		return lastErr
+	}
+	return nil
+}
+
+// observeRunnerRequest records and logs the latency and outcome of a runner call.
+func observeRunnerRequest(logger *slog.Logger, project, operation string, start time.Time, resp *http.Response, err error) {
+	statusCode := 0
+	if resp != nil {
+		statusCode = resp.StatusCode
+	}
+	metrics.ObserveRunnerRequest(project, operation, start, statusCode, err)
+
+	duration := time.Since(start).Milliseconds()
+	if err != nil {
+		logger.Warn("Runner request failed", "operation", operation, "duration_ms", duration, "error", err)
+		return
+	}
+	logger.Debug("Runner request", "operation", operation, "status", statusCode, "duration_ms", duration)
+}
+
+// RunAutomation calls POST /selenium/run with payload {"testsuite_id", "email", "reference_number"},
+// plus "browser_name", "browser_version" and "platform_name" when the run asks for a browser.
+func (s *seleniumRepository) RunAutomation(ctx context.Context, project, testsuiteID, email, refnum string, browser domain.Browser) (domain.RunResponse, error) {
+	pool, err := s.getPool(project)
 	if err != nil {
 		return domain.RunResponse{}, err
 	}
-	endpoint := fmt.Sprintf("%s/selenium/run", baseURL)
 	payload := map[string]string{
 		"testsuite_id":     testsuiteID,
 		"email":            email,
 		"reference_number": refnum,
 	}
+	if browser.BrowserName != "" {
+		payload["browser_name"] = browser.BrowserName
+	}
+	if browser.BrowserVersion != "" {
+		payload["browser_version"] = browser.BrowserVersion
+	}
+	if browser.PlatformName != "" {
+		payload["platform_name"] = browser.PlatformName
+	}
 	body, err := json.Marshal(payload)
 	if err != nil {
 		return domain.RunResponse{}, err
 	}
 
-	resp, err := http.Post(endpoint, "application/json", bytes.NewBuffer(body))
+	// Prefer the node with the fewest runs in progress, and try the others when it is full.
+	active, err := s.runs.CountByRunner(ctx, project, domain.RunStatusTriggered)
+	if err != nil {
+		logging.FromContext(ctx).Warn("Error counting the runs of each runner node", "project", project, "error", err)
+	}
+	logger := logging.FromContext(ctx).With("project", project, "reference_number", refnum, "testsuite_id", testsuiteID)
+	// Starting a run is not idempotent, so it is never retried.
+	resp, node, err := s.send(ctx, logger, project, "run", pool, pool.order(active), 0,
+		func(n *runnerNode) (*http.Response, error) {
+			defer pool.begin(n)()
+			return s.post(ctx, n.url+"/selenium/run", body, s.cfg.RunTimeout)
+		},
+		func(resp *http.Response) bool { return resp.StatusCode == http.StatusTooManyRequests })
 	if err != nil {
 		return domain.RunResponse{}, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusTooManyRequests {
-		return domain.RunResponse{}, errors.New("your request is queued")
+		return domain.RunResponse{}, ErrRunnerBusy
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return domain.RunResponse{}, errors.New("failed to run automation")
+		return domain.RunResponse{}, unexpectedStatus("run", node, resp)
 	}
 
 	data, err := ioutil.ReadAll(resp.Body)
@@ -69,24 +323,27 @@ func (s *SeleniumRepository) RunAutomation(project, testsuiteID, email, refnum s
 	if err != nil {
 		return domain.RunResponse{}, err
 	}
+	runResp.RunnerURL = node.url
 	return runResp, nil
 }
 
 // GetTestSuites calls GET /selenium/testsuites.
-func (s *SeleniumRepository) GetTestSuites(project string) ([]string, error) {
-	baseURL, err := s.getBaseURL(project)
+func (s *seleniumRepository) GetTestSuites(ctx context.Context, project string) ([]string, error) {
+	pool, err := s.getPool(project)
 	if err != nil {
 		return nil, err
 	}
-	endpoint := fmt.Sprintf("%s/selenium/testsuites", baseURL)
-	resp, err := http.Get(endpoint)
+	resp, node, err := s.send(ctx, logging.FromContext(ctx).With("project", project), project, "testsuites", pool, pool.order(nil), s.cfg.GetRetries,
+		func(n *runnerNode) (*http.Response, error) {
+			return s.get(ctx, n.url+"/selenium/testsuites", s.cfg.RequestTimeout)
+		}, nil)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, errors.New("failed to get test suites")
+		return nil, unexpectedStatus("testsuites", node, resp)
 	}
 
 	data, err := ioutil.ReadAll(resp.Body)
@@ -105,12 +362,11 @@ func (s *SeleniumRepository) GetTestSuites(project string) ([]string, error) {
 }
 
 // GetTestSuiteDetail calls POST /selenium/testsuite/detail with payload {"testsuite_name"}.
-func (s *SeleniumRepository) GetTestSuiteDetail(project, testsuiteName string) (domain.TestSuiteDetail, error) {
-	baseURL, err := s.getBaseURL(project)
+func (s *seleniumRepository) GetTestSuiteDetail(ctx context.Context, project, testsuiteName string) (domain.TestSuiteDetail, error) {
+	pool, err := s.getPool(project)
 	if err != nil {
 		return domain.TestSuiteDetail{}, err
 	}
-	endpoint := fmt.Sprintf("%s/selenium/testsuite/detail", baseURL)
 	payload := map[string]string{
 		"testsuite_name": testsuiteName,
 	}
@@ -119,14 +375,17 @@ func (s *SeleniumRepository) GetTestSuiteDetail(project, testsuiteName string) (
 		return domain.TestSuiteDetail{}, err
 	}
 
-	resp, err := http.Post(endpoint, "application/json", bytes.NewBuffer(body))
+	resp, node, err := s.send(ctx, logging.FromContext(ctx).With("project", project), project, "testsuite_detail", pool, pool.order(nil), 0,
+		func(n *runnerNode) (*http.Response, error) {
+			return s.post(ctx, n.url+"/selenium/testsuite/detail", body, s.cfg.RequestTimeout)
+		}, nil)
 	if err != nil {
 		return domain.TestSuiteDetail{}, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return domain.TestSuiteDetail{}, errors.New("failed to get test suite detail")
+		return domain.TestSuiteDetail{}, unexpectedStatus("testsuite_detail", node, resp)
 	}
 
 	data, err := ioutil.ReadAll(resp.Body)
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+
+	automationRepo "service-test-runner/internal/repository/automation"
+	outboxRepo "service-test-runner/internal/repository/outbox"
+
+	"gorm.io/gorm"
+)
+
+// Transaction gives access to the repositories whose writes must commit together.
+type Transaction interface {
+	Runs() automationRepo.QueueAutomationRepository
+	Outbox() outboxRepo.OutboxRepository
+}
+
+// UnitOfWork runs a function in a database transaction.
+type UnitOfWork interface {
+	// Do commits the writes made through tx if fn returns nil, and rolls them back otherwise.
+	Do(ctx context.Context, fn func(tx Transaction) error) error
+}
+
+// unitOfWork is the GORM implementation.
+type unitOfWork struct {
+	db *gorm.DB
+}
+
+// NewUnitOfWork creates a UnitOfWork running its transactions on the given database handle.
+func NewUnitOfWork(gdb *gorm.DB) UnitOfWork {
+	return &unitOfWork{db: gdb}
+}
+
+// Do runs fn in a transaction, with repositories bound to it.
+func (u *unitOfWork) Do(ctx context.Context, fn func(tx Transaction) error) error {
+	return u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&transaction{db: tx})
+	})
+}
+
+// transaction hands out repositories bound to one GORM transaction.
+type transaction struct {
+	db *gorm.DB
+}
+
+func (t *transaction) Runs() automationRepo.QueueAutomationRepository {
+	return automationRepo.NewQueueAutomationRepository(t.db)
+}
+
+func (t *transaction) Outbox() outboxRepo.OutboxRepository {
+	return outboxRepo.NewOutboxRepository(t.db)
+}
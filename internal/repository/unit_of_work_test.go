@@ -0,0 +1,61 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"service-test-runner/internal/db/dbtest"
+	"service-test-runner/internal/domain"
+	"service-test-runner/internal/repository"
+	automationRepo "service-test-runner/internal/repository/automation"
+	outboxRepo "service-test-runner/internal/repository/outbox"
+)
+
+func queue(ctx context.Context, tx repository.Transaction, referenceNumber string) error {
+	run := domain.Run{ReferenceNumber: referenceNumber, Testsuite: "suite1", Project: "web1", Status: domain.RunStatusQueued, Priority: domain.RunPriorityNormal}
+	if err := tx.Runs().Create(ctx, &run); err != nil {
+		return err
+	}
+	return tx.Outbox().Create(ctx, &domain.OutboxMessage{
+		ReferenceNumber: referenceNumber,
+		Project:         run.Project,
+		Priority:        run.Priority,
+		Payload:         "{}",
+		Status:          domain.OutboxMessagePending,
+		NextAttemptAt:   time.Now(),
+	})
+}
+
+func TestUnitOfWorkCommitsAndRollsBack(t *testing.T) {
+	ctx := context.Background()
+	gdb := dbtest.Open(t)
+	uow := repository.NewUnitOfWork(gdb)
+	runs := automationRepo.NewQueueAutomationRepository(gdb)
+	outbox := outboxRepo.NewOutboxRepository(gdb)
+
+	if err := uow.Do(ctx, func(tx repository.Transaction) error { return queue(ctx, tx, "ref-1") }); err != nil {
+		t.Fatal(err)
+	}
+	failure := errors.New("publish refused")
+	err := uow.Do(ctx, func(tx repository.Transaction) error {
+		if err := queue(ctx, tx, "ref-2"); err != nil {
+			return err
+		}
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("Do = %v, want %v", err, failure)
+	}
+
+	if n, err := runs.CountByStatus(ctx, domain.RunStatusQueued); err != nil || n != 1 {
+		t.Errorf("queued runs = %d, %v; want 1", n, err)
+	}
+	if n, err := outbox.CountByStatus(ctx, domain.OutboxMessagePending); err != nil || n != 1 {
+		t.Errorf("pending messages = %d, %v; want 1", n, err)
+	}
+	if _, err := runs.GetByReferenceNumber(ctx, "ref-2"); err == nil {
+		t.Error("the rolled back run was written")
+	}
+}
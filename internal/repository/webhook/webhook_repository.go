@@ -0,0 +1,229 @@
+package webhookRepo
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"service-test-runner/internal/db"
+	"service-test-runner/internal/domain"
+	"service-test-runner/internal/infrastructure/logging"
+
+	"gorm.io/gorm"
+)
+
+// WebhookRepository defines the repository interface for webhooks and their delivery log.
+type WebhookRepository interface {
+	Create(ctx context.Context, webhook *domain.Webhook) error
+	GetByID(ctx context.Context, id uint) (*domain.Webhook, error)
+	GetByProject(ctx context.Context, project string) ([]domain.Webhook, error)
+	Update(ctx context.Context, webhook *domain.Webhook) error
+	Delete(ctx context.Context, id uint) error
+	CreateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error
+	GetDelivery(ctx context.Context, id uint) (*domain.WebhookDelivery, error)
+	GetDeliveries(ctx context.Context, webhookID uint, limit int) ([]domain.WebhookDelivery, error)
+	GetDueDeliveries(ctx context.Context, status string, now time.Time, limit int) ([]domain.WebhookDelivery, error)
+	UpdateDeliveryAttempt(ctx context.Context, delivery *domain.WebhookDelivery) error
+}
+
+// webhookRepository is the concrete implementation.
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookRepository creates a new instance of the repository.
+func NewWebhookRepository(gdb *gorm.DB) WebhookRepository {
+	return &webhookRepository{db: gdb}
+}
+
+// Create inserts a new webhook, setting its ID and creation time.
+func (r *webhookRepository) Create(ctx context.Context, webhook *domain.Webhook) error {
+	row := toRow(webhook)
+	if err := r.db.WithContext(ctx).Create(&row).Error; err != nil {
+		logging.FromContext(ctx).Error("Error inserting Webhook record", "error", err)
+		return err
+	}
+	webhook.ID = row.ID
+	webhook.CreatedAt = row.CreatedAt
+	return nil
+}
+
+// GetByID fetches a webhook, or nil if it does not exist.
+func (r *webhookRepository) GetByID(ctx context.Context, id uint) (*domain.Webhook, error) {
+	var row db.TblWebhook
+	err := r.db.WithContext(ctx).First(&row, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("Error selecting Webhook record", "webhook_id", id, "error", err)
+		return nil, err
+	}
+	webhook := toDomain(row)
+	return &webhook, nil
+}
+
+// GetByProject fetches the webhooks of a project.
+func (r *webhookRepository) GetByProject(ctx context.Context, project string) ([]domain.Webhook, error) {
+	var rows []db.TblWebhook
+	if err := r.db.WithContext(ctx).Where("project = ?", project).Order("id").Find(&rows).Error; err != nil {
+		logging.FromContext(ctx).Error("Error selecting Webhook records", "project", project, "error", err)
+		return nil, err
+	}
+	webhooks := make([]domain.Webhook, 0, len(rows))
+	for _, row := range rows {
+		webhooks = append(webhooks, toDomain(row))
+	}
+	return webhooks, nil
+}
+
+// Update saves the URL, events, secret and active flag of a webhook.
+func (r *webhookRepository) Update(ctx context.Context, webhook *domain.Webhook) error {
+	row := toRow(webhook)
+	return r.db.WithContext(ctx).Model(&row).
+		Select("url", "events", "secret", "active").
+		Updates(&row).Error
+}
+
+// Delete removes a webhook together with its delivery log.
+func (r *webhookRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", id).Delete(&db.TblWebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&db.TblWebhook{}, id).Error
+	})
+}
+
+// CreateDelivery inserts a new delivery, setting its ID and creation time.
+func (r *webhookRepository) CreateDelivery(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	row := deliveryToRow(delivery)
+	if err := r.db.WithContext(ctx).Create(&row).Error; err != nil {
+		logging.FromContext(ctx).Error("Error inserting WebhookDelivery record", "reference_number", delivery.ReferenceNumber, "error", err)
+		return err
+	}
+	delivery.ID = row.ID
+	delivery.CreatedAt = row.CreatedAt
+	return nil
+}
+
+// GetDelivery fetches a delivery, or nil if it does not exist.
+func (r *webhookRepository) GetDelivery(ctx context.Context, id uint) (*domain.WebhookDelivery, error) {
+	var row db.TblWebhookDelivery
+	err := r.db.WithContext(ctx).First(&row, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		logging.FromContext(ctx).Error("Error selecting WebhookDelivery record", "delivery_id", id, "error", err)
+		return nil, err
+	}
+	delivery := deliveryToDomain(row)
+	return &delivery, nil
+}
+
+// GetDeliveries fetches the most recent deliveries of a webhook, newest first.
+func (r *webhookRepository) GetDeliveries(ctx context.Context, webhookID uint, limit int) ([]domain.WebhookDelivery, error) {
+	var rows []db.TblWebhookDelivery
+	if err := r.db.WithContext(ctx).Where("webhook_id = ?", webhookID).Order("id DESC").Limit(limit).Find(&rows).Error; err != nil {
+		logging.FromContext(ctx).Error("Error selecting WebhookDelivery records", "webhook_id", webhookID, "error", err)
+		return nil, err
+	}
+	return deliveriesToDomain(rows), nil
+}
+
+// GetDueDeliveries fetches the deliveries in a status whose next attempt is due, oldest first.
+func (r *webhookRepository) GetDueDeliveries(ctx context.Context, status string, now time.Time, limit int) ([]domain.WebhookDelivery, error) {
+	var rows []db.TblWebhookDelivery
+	err := r.db.WithContext(ctx).Where("status = ? AND next_attempt_at <= ?", status, now).
+		Order("id").
+		Limit(limit).
+		Find(&rows).Error
+	if err != nil {
+		logging.FromContext(ctx).Error("Error selecting due WebhookDelivery records", "error", err)
+		return nil, err
+	}
+	return deliveriesToDomain(rows), nil
+}
+
+// UpdateDeliveryAttempt records the outcome of a delivery attempt.
+func (r *webhookRepository) UpdateDeliveryAttempt(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	row := deliveryToRow(delivery)
+	return r.db.WithContext(ctx).Model(&row).
+		Select("status", "attempts", "response_code", "response_body", "error", "next_attempt_at", "delivered_at").
+		Updates(&row).Error
+}
+
+// toRow stores the events of a webhook as a comma-separated list; empty means all events.
+func toRow(webhook *domain.Webhook) db.TblWebhook {
+	return db.TblWebhook{
+		ID:        webhook.ID,
+		Project:   webhook.Project,
+		URL:       webhook.URL,
+		Events:    strings.Join(webhook.Events, ","),
+		Secret:    webhook.Secret,
+		Active:    webhook.Active,
+		CreatedAt: webhook.CreatedAt,
+	}
+}
+
+func toDomain(row db.TblWebhook) domain.Webhook {
+	events := []string{}
+	if row.Events != "" {
+		events = strings.Split(row.Events, ",")
+	}
+	return domain.Webhook{
+		ID:        row.ID,
+		Project:   row.Project,
+		URL:       row.URL,
+		Events:    events,
+		Secret:    row.Secret,
+		Active:    row.Active,
+		CreatedAt: row.CreatedAt,
+	}
+}
+
+func deliveryToRow(delivery *domain.WebhookDelivery) db.TblWebhookDelivery {
+	return db.TblWebhookDelivery{
+		ID:              delivery.ID,
+		WebhookID:       delivery.WebhookID,
+		Event:           delivery.Event,
+		ReferenceNumber: delivery.ReferenceNumber,
+		Payload:         delivery.Payload,
+		Status:          delivery.Status,
+		Attempts:        delivery.Attempts,
+		ResponseCode:    delivery.ResponseCode,
+		ResponseBody:    delivery.ResponseBody,
+		Error:           delivery.Error,
+		NextAttemptAt:   delivery.NextAttemptAt,
+		DeliveredAt:     delivery.DeliveredAt,
+		CreatedAt:       delivery.CreatedAt,
+	}
+}
+
+func deliveryToDomain(row db.TblWebhookDelivery) domain.WebhookDelivery {
+	return domain.WebhookDelivery{
+		ID:              row.ID,
+		WebhookID:       row.WebhookID,
+		Event:           row.Event,
+		ReferenceNumber: row.ReferenceNumber,
+		Payload:         row.Payload,
+		Status:          row.Status,
+		Attempts:        row.Attempts,
+		ResponseCode:    row.ResponseCode,
+		ResponseBody:    row.ResponseBody,
+		Error:           row.Error,
+		NextAttemptAt:   row.NextAttemptAt,
+		DeliveredAt:     row.DeliveredAt,
+		CreatedAt:       row.CreatedAt,
+	}
+}
+
+func deliveriesToDomain(rows []db.TblWebhookDelivery) []domain.WebhookDelivery {
+	deliveries := make([]domain.WebhookDelivery, 0, len(rows))
+	for _, row := range rows {
+		deliveries = append(deliveries, deliveryToDomain(row))
+	}
+	return deliveries
+}
@@ -0,0 +1,104 @@
+package webhookRepo_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"service-test-runner/internal/db/dbtest"
+	"service-test-runner/internal/domain"
+	webhookRepo "service-test-runner/internal/repository/webhook"
+)
+
+func TestWebhookLifecycle(t *testing.T) {
+	ctx := context.Background()
+	repo := webhookRepo.NewWebhookRepository(dbtest.Open(t))
+
+	hook := domain.Webhook{
+		Project: "web1",
+		URL:     "https://hooks.example.com/runs",
+		Events:  []string{domain.RunEventQueued, domain.RunEventFinished},
+		Secret:  "s3cret",
+		Active:  true,
+	}
+	if err := repo.Create(ctx, &hook); err != nil {
+		t.Fatal(err)
+	}
+	got, err := repo.GetByID(ctx, hook.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == nil || !reflect.DeepEqual(got.Events, hook.Events) || got.Secret != "s3cret" || !got.Active {
+		t.Fatalf("GetByID = %+v, want %+v", got, hook)
+	}
+
+	hook.Events, hook.Active = []string{}, false
+	if err := repo.Update(ctx, &hook); err != nil {
+		t.Fatal(err)
+	}
+	hooks, err := repo.GetByProject(ctx, "web1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hooks) != 1 || len(hooks[0].Events) != 0 || hooks[0].Active {
+		t.Fatalf("GetByProject after Update = %+v", hooks)
+	}
+
+	if err := repo.CreateDelivery(ctx, &domain.WebhookDelivery{WebhookID: hook.ID, Event: domain.RunEventQueued, ReferenceNumber: "ref-1", Payload: "{}", Status: domain.WebhookDeliveryPending}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Delete(ctx, hook.ID); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := repo.GetByID(ctx, hook.ID); err != nil || got != nil {
+		t.Fatalf("GetByID after Delete = %+v, %v; want nil", got, err)
+	}
+	if deliveries, err := repo.GetDeliveries(ctx, hook.ID, 10); err != nil || len(deliveries) != 0 {
+		t.Fatalf("deliveries after Delete = %+v, %v; want none", deliveries, err)
+	}
+}
+
+func TestDueDeliveries(t *testing.T) {
+	ctx := context.Background()
+	repo := webhookRepo.NewWebhookRepository(dbtest.Open(t))
+	now := time.Now()
+	past, future := now.Add(-time.Minute), now.Add(time.Minute)
+
+	deliveries := []domain.WebhookDelivery{
+		{ReferenceNumber: "ref-1", NextAttemptAt: &past},
+		{ReferenceNumber: "ref-2", NextAttemptAt: &future},
+		{ReferenceNumber: "ref-3", NextAttemptAt: &past},
+	}
+	for i := range deliveries {
+		d := &deliveries[i]
+		d.WebhookID, d.Event, d.Payload, d.Status = 1, domain.RunEventFinished, `{"type":"run.finished"}`, domain.WebhookDeliveryPending
+		if err := repo.CreateDelivery(ctx, d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	due, err := repo.GetDueDeliveries(ctx, domain.WebhookDeliveryPending, now, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(due) != 2 || due[0].ReferenceNumber != "ref-1" || due[1].ReferenceNumber != "ref-3" || due[0].Payload != `{"type":"run.finished"}` {
+		t.Fatalf("GetDueDeliveries = %+v, want ref-1 then ref-3", due)
+	}
+
+	due[0].Status, due[0].Attempts, due[0].ResponseCode, due[0].DeliveredAt = domain.WebhookDeliverySucceeded, 1, 200, &now
+	if err := repo.UpdateDeliveryAttempt(ctx, &due[0]); err != nil {
+		t.Fatal(err)
+	}
+	got, err := repo.GetDelivery(ctx, due[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != domain.WebhookDeliverySucceeded || got.ResponseCode != 200 || got.DeliveredAt == nil {
+		t.Fatalf("GetDelivery = %+v", got)
+	}
+	latest, err := repo.GetDeliveries(ctx, 1, 2)
+	if err != nil || len(latest) != 2 || latest[0].ReferenceNumber != "ref-3" {
+		t.Fatalf("GetDeliveries = %+v, %v; want the two newest, ref-3 first", latest, err)
+	}
+}
@@ -0,0 +1,252 @@
+package usecase
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"service-test-runner/internal/domain"
+	"service-test-runner/internal/infrastructure/storage"
+	artifactRepo "service-test-runner/internal/repository/artifact"
+)
+
+// sniffLen is the number of leading bytes used to detect the content type of an upload.
+const sniffLen = 512
+
+var (
+	// ErrUnsupportedArtifact is returned when an upload does not match any known artifact type.
+	ErrUnsupportedArtifact = errors.New("unsupported artifact type")
+	// ErrArtifactTooLarge is returned when an upload exceeds the size limit of its type.
+	ErrArtifactTooLarge = errors.New("artifact too large")
+)
+
+// ArtifactUpload describes a single file uploaded for a run or step.
+// Size is the length of Reader in bytes, or -1 when it is not known up front. A
+// non-empty Type is the only artifact type accepted.
+type ArtifactUpload struct {
+	ReferenceNumber string
+	IdTest          string
+	StepName        string
+	Filename        string
+	Reader          io.Reader
+	Size            int64
+	Type            string
+}
+
+// ArtifactUsecase handles storing and listing run artifacts.
+type ArtifactUsecase struct {
+	repo     artifactRepo.ArtifactRepository
+	store    storage.ArtifactStore
+	maxSizes map[string]int64
+}
+
+// NewArtifactUsecase creates a new ArtifactUsecase with its dependencies injected.
+func NewArtifactUsecase(repo artifactRepo.ArtifactRepository, store storage.ArtifactStore, maxSizes map[string]int64) *ArtifactUsecase {
+	return &ArtifactUsecase{
+		repo:     repo,
+		store:    store,
+		maxSizes: maxSizes,
+	}
+}
+
+// Store streams the upload into storage and indexes it in tbl_artifacts. The content
+// type is sniffed from the first bytes and checked against upload.Type before anything
+// is stored, and the size limit of the artifact type and a SHA-256 checksum are
+// enforced and computed while the content flows through.
+func (uc *ArtifactUsecase) Store(ctx context.Context, upload ArtifactUpload) (domain.Artifact, error) {
+	name := filepath.Base(upload.Filename)
+	reader := bufio.NewReaderSize(upload.Reader, sniffLen)
+	head, err := reader.Peek(sniffLen)
+	if err != nil && err != io.EOF {
+		return domain.Artifact{}, err
+	}
+	contentType := http.DetectContentType(head)
+	artifactType, err := classifyArtifact(name, contentType)
+	if err != nil {
+		return domain.Artifact{}, err
+	}
+	if upload.Type != "" && artifactType != upload.Type {
+		return domain.Artifact{}, fmt.Errorf("%w: %s is a %s artifact, not %s", ErrUnsupportedArtifact, name, artifactType, upload.Type)
+	}
+	limit, hasLimit := uc.maxSizes[artifactType]
+	if !hasLimit {
+		limit = -1
+	}
+	if limit >= 0 && upload.Size > limit {
+		return domain.Artifact{}, fmt.Errorf("%w: %s artifact %s exceeds the %d bytes limit", ErrArtifactTooLarge, artifactType, name, limit)
+	}
+	// Sniffing cannot tell JUnit XML from any other XML document.
+	if artifactType == domain.ArtifactTypeJUnit {
+		contentType = "application/xml"
+	}
+
+	// Prefix the object with a timestamp so repeated uploads never overwrite each other.
+	objectName := fmt.Sprintf("reports/%s/%s_%s",
+		upload.ReferenceNumber, time.Now().Format("20060102150405.000000"), name)
+	hash := sha256.New()
+	counter := &countingReader{reader: io.TeeReader(reader, hash), limit: limit}
+	if err := uc.store.Put(ctx, objectName, counter, upload.Size, contentType); err != nil {
+		// Clean up even when the upload failed because the client went away.
+		uc.store.Delete(context.WithoutCancel(ctx), objectName)
+		if counter.exceeded {
+			return domain.Artifact{}, fmt.Errorf("%w: %s artifact %s exceeds the %d bytes limit", ErrArtifactTooLarge, artifactType, name, limit)
+		}
+		return domain.Artifact{}, err
+	}
+
+	artifact := domain.Artifact{
+		ReferenceNumber: upload.ReferenceNumber,
+		IdTest:          upload.IdTest,
+		StepName:        upload.StepName,
+		Name:            name,
+		Type:            artifactType,
+		ContentType:     contentType,
+		Size:            counter.count,
+		Checksum:        hex.EncodeToString(hash.Sum(nil)),
+		ObjectName:      objectName,
+	}
+	if err := uc.repo.Create(ctx, &artifact); err != nil {
+		uc.store.Delete(context.WithoutCancel(ctx), objectName)
+		return domain.Artifact{}, err
+	}
+	return uc.withURL(ctx, artifact)
+}
+
+// Open returns the stored content of an artifact. The caller must close it.
+func (uc *ArtifactUsecase) Open(ctx context.Context, artifact domain.Artifact) (io.ReadCloser, error) {
+	object, _, err := uc.store.Get(ctx, artifact.ObjectName)
+	return object, err
+}
+
+// Delete removes the stored content of an artifact and then its record, so an object
+// that could not be deleted is still indexed.
+func (uc *ArtifactUsecase) Delete(ctx context.Context, artifact domain.Artifact) error {
+	if err := uc.store.Delete(ctx, artifact.ObjectName); err != nil {
+		return fmt.Errorf("failed to delete %s: %v", artifact.ObjectName, err)
+	}
+	return uc.repo.Delete(ctx, artifact.ID)
+}
+
+// ListByReferenceNumber returns all artifacts stored for a run.
+func (uc *ArtifactUsecase) ListByReferenceNumber(ctx context.Context, referenceNumber string) ([]domain.Artifact, error) {
+	artifacts, err := uc.repo.GetByReferenceNumber(ctx, referenceNumber)
+	if err != nil {
+		return nil, err
+	}
+	for i := range artifacts {
+		if artifacts[i], err = uc.withURL(ctx, artifacts[i]); err != nil {
+			return nil, err
+		}
+	}
+	return artifacts, nil
+}
+
+// ReportURL returns a pre-signed download URL for a run's report_file. Older rows
+// hold a direct bucket URL instead of an object name; those are signed as well.
+func (uc *ArtifactUsecase) ReportURL(ctx context.Context, reportFile string) (string, error) {
+	if reportFile == "" {
+		return "", nil
+	}
+	objectName, ok := reportObjectName(reportFile)
+	if !ok {
+		return reportFile, nil
+	}
+	return uc.store.SignedURL(ctx, objectName)
+}
+
+// reportObjectName returns the object name held in a report_file column. Legacy rows
+// hold a direct bucket URL of the form http://{endpoint}/{bucket}/{object}.
+func reportObjectName(reportFile string) (string, bool) {
+	if !strings.HasPrefix(reportFile, "http://") && !strings.HasPrefix(reportFile, "https://") {
+		return reportFile, reportFile != ""
+	}
+	u, err := url.Parse(reportFile)
+	if err != nil {
+		return "", false
+	}
+	parts := strings.SplitN(strings.TrimPrefix(u.Path, "/"), "/", 2)
+	if len(parts) != 2 {
+		return "", false
+	}
+	return parts[1], true
+}
+
+// withURL sets the signed download URL of an artifact.
+func (uc *ArtifactUsecase) withURL(ctx context.Context, artifact domain.Artifact) (domain.Artifact, error) {
+	fileURL, err := uc.store.SignedURL(ctx, artifact.ObjectName)
+	if err != nil {
+		return domain.Artifact{}, err
+	}
+	artifact.URL = fileURL
+	return artifact, nil
+}
+
+// extensionTypes are the artifact types of the file extensions trusted when the content
+// type cannot be sniffed (application/octet-stream).
+var extensionTypes = map[string]string{
+	".xml":  domain.ArtifactTypeJUnit,
+	".log":  domain.ArtifactTypeLog,
+	".txt":  domain.ArtifactTypeLog,
+	".html": domain.ArtifactTypeHTML,
+	".htm":  domain.ArtifactTypeHTML,
+	".mp4":  domain.ArtifactTypeVideo,
+	".webm": domain.ArtifactTypeVideo,
+	".mov":  domain.ArtifactTypeVideo,
+	".mkv":  domain.ArtifactTypeVideo,
+}
+
+// classifyArtifact maps a sniffed content type to an artifact type. The file
+// extension disambiguates text formats, and is only trusted on its own when the
+// content type could not be sniffed, so a renamed PDF or image is still rejected.
+func classifyArtifact(filename, contentType string) (string, error) {
+	ext := strings.ToLower(filepath.Ext(filename))
+	mediaType := strings.TrimSpace(strings.Split(contentType, ";")[0])
+
+	switch {
+	case mediaType == "application/pdf":
+		return domain.ArtifactTypePDF, nil
+	case mediaType == "image/png" || mediaType == "image/jpeg" || mediaType == "image/gif" || mediaType == "image/webp":
+		return domain.ArtifactTypeScreenshot, nil
+	case strings.HasPrefix(mediaType, "video/"):
+		return domain.ArtifactTypeVideo, nil
+	case mediaType == "application/zip" && ext == ".zip":
+		return domain.ArtifactTypeHTML, nil
+	case mediaType == "text/html":
+		return domain.ArtifactTypeHTML, nil
+	case mediaType == "text/xml" || mediaType == "application/xml" || (mediaType == "text/plain" && ext == ".xml"):
+		return domain.ArtifactTypeJUnit, nil
+	case mediaType == "text/plain":
+		return domain.ArtifactTypeLog, nil
+	case mediaType == "application/octet-stream" && extensionTypes[ext] != "":
+		return extensionTypes[ext], nil
+	}
+	return "", fmt.Errorf("%w: %s (%s)", ErrUnsupportedArtifact, filename, mediaType)
+}
+
+// countingReader counts the bytes read through it and fails once they exceed limit.
+// A negative limit disables the check.
+type countingReader struct {
+	reader   io.Reader
+	limit    int64
+	count    int64
+	exceeded bool
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += int64(n)
+	if r.limit >= 0 && r.count > r.limit {
+		r.exceeded = true
+		return n, ErrArtifactTooLarge
+	}
+	return n, err
+}
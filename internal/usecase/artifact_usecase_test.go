@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"service-test-runner/internal/db/dbtest"
+	"service-test-runner/internal/domain"
+	artifactRepo "service-test-runner/internal/repository/artifact"
+)
+
+const junitXML = `<?xml version="1.0"?><testsuite name="s" tests="1"><testcase name="c"/></testsuite>`
+
+func TestStoreRejectsOtherTypeBeforeStoring(t *testing.T) {
+	ctx := context.Background()
+	repo := artifactRepo.NewArtifactRepository(dbtest.Open(t))
+	store := newFakeStore()
+	uc := NewArtifactUsecase(repo, store, nil)
+
+	_, err := uc.Store(ctx, ArtifactUpload{
+		ReferenceNumber: "ref-1",
+		Filename:        "run.log",
+		Reader:          strings.NewReader("plain log line\n"),
+		Size:            -1,
+		Type:            domain.ArtifactTypeJUnit,
+	})
+	if !errors.Is(err, ErrUnsupportedArtifact) {
+		t.Fatalf("Store = %v, want ErrUnsupportedArtifact", err)
+	}
+	if objects, _ := store.List(ctx, ""); len(objects) != 0 {
+		t.Errorf("objects stored for a rejected upload: %v", objects)
+	}
+	if artifacts, _ := repo.GetByReferenceNumber(ctx, "ref-1"); len(artifacts) != 0 {
+		t.Errorf("artifacts indexed for a rejected upload: %v", artifacts)
+	}
+
+	artifact, err := uc.Store(ctx, ArtifactUpload{
+		ReferenceNumber: "ref-1",
+		Filename:        "results.xml",
+		Reader:          strings.NewReader(junitXML),
+		Size:            -1,
+		Type:            domain.ArtifactTypeJUnit,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if artifact.Type != domain.ArtifactTypeJUnit || !store.has(artifact.ObjectName) {
+		t.Fatalf("stored %+v", artifact)
+	}
+}
+
+func TestClassifyArtifact(t *testing.T) {
+	tests := []struct {
+		filename    string
+		contentType string
+		want        string
+	}{
+		{"report.pdf", "application/pdf", domain.ArtifactTypePDF},
+		{"results.xml", "text/xml; charset=utf-8", domain.ArtifactTypeJUnit},
+		{"results.xml", "application/xml", domain.ArtifactTypeJUnit},
+		{"results.xml", "text/plain; charset=utf-8", domain.ArtifactTypeJUnit},
+		{"results.xml", "application/octet-stream", domain.ArtifactTypeJUnit},
+		{"run.log", "text/plain; charset=utf-8", domain.ArtifactTypeLog},
+		{"run.log", "application/octet-stream", domain.ArtifactTypeLog},
+		{"recording.mov", "application/octet-stream", domain.ArtifactTypeVideo},
+		{"report.zip", "application/zip", domain.ArtifactTypeHTML},
+		{"shot.png", "image/png", domain.ArtifactTypeScreenshot},
+		{"report.pdf", "application/octet-stream", ""},
+		{"tool.exe", "application/octet-stream", ""},
+		{"archive.jar", "application/zip", ""},
+	}
+	for _, tt := range tests {
+		got, err := classifyArtifact(tt.filename, tt.contentType)
+		if tt.want == "" {
+			if !errors.Is(err, ErrUnsupportedArtifact) {
+				t.Errorf("classifyArtifact(%s, %s) = %q, %v; want ErrUnsupportedArtifact", tt.filename, tt.contentType, got, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("classifyArtifact(%s, %s) = %q, %v; want %q", tt.filename, tt.contentType, got, err, tt.want)
+		}
+	}
+}
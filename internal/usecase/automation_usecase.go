@@ -1,55 +1,55 @@
 package usecase
 
 import (
-	"encoding/json"
-	"fmt"
+	"context"
+
 	"service-test-runner/internal/domain"
-	"service-test-runner/internal/infrastructure/messaging"
 	"service-test-runner/internal/repository/selenium"
 )
 
 // AutomationUsecase handles automation logic.
 type AutomationUsecase struct {
-	repo      *selenium.SeleniumRepository
-	publisher messaging.Publisher
+	repo     selenium.SeleniumRepository
+	capacity capacityReserver
+	grid     gridChecker
 }
 
-// NewAutomationUsecase creates a new AutomationUsecase with its dependencies injected.
-func NewAutomationUsecase(repo *selenium.SeleniumRepository, publisher messaging.Publisher) *AutomationUsecase {
-	return &AutomationUsecase{
-		repo:      repo,
-		publisher: publisher,
-	}
+// capacityReserver takes the slots of project runners, see CapacityUsecase.
+type capacityReserver interface {
+	Reserve(ctx context.Context, projectName, referenceNumber string) (release func(), err error)
 }
 
-// Run triggers the automation using testsuite_id and email.
-// If the request is queued, it publishes a RabbitMQ message.
-func (a *AutomationUsecase) Run(project, testsuiteID, email, refnum string) (domain.RunResponse, error) {
-	runResp, err := a.repo.RunAutomation(project, testsuiteID, email, refnum)
-	if err != nil {
-		return runResp, err
+// gridChecker checks that the Selenium Grid of a project can take a run, see GridUsecase.
+type gridChecker interface {
+	Check(ctx context.Context, project string, browser domain.Browser) error
+}
+
+// NewAutomationUsecase creates a new AutomationUsecase with its dependencies injected.
+func NewAutomationUsecase(repo selenium.SeleniumRepository, capacity capacityReserver, grid gridChecker) *AutomationUsecase {
+	return &AutomationUsecase{
+		repo:     repo,
+		capacity: capacity,
+		grid:     grid,
 	}
-	return runResp, nil
 }
 
-// HandleQueuedRequest handles a queued automation request by storing a DB record and publishing a RabbitMQ message.
-func (uc *AutomationUsecase) HandleQueuedRequest(project, testsuiteID string, totalSteps int, refnum string) error {
-	// Prepare the message payload.
-	msgBody := map[string]interface{}{
-		"reference_number": refnum,
-		"project":          project,
-		"testsuite_id":     testsuiteID,
-		"total_steps":      totalSteps,
+// Run triggers the automation using testsuite_id, email and browser. When the project
+// runner is already at capacity, or no slot of its Selenium Grid offers the browser,
+// it returns selenium.ErrRunnerBusy without calling it, so the run is queued just as
+// if the runner had answered 429.
+func (a *AutomationUsecase) Run(ctx context.Context, project, testsuiteID, email, refnum string, browser domain.Browser) (domain.RunResponse, error) {
+	if err := a.grid.Check(ctx, project, browser); err != nil {
+		return domain.RunResponse{}, err
 	}
-	msgBytes, err := json.Marshal(msgBody)
+	release, err := a.capacity.Reserve(ctx, project, refnum)
 	if err != nil {
-		return err
+		return domain.RunResponse{}, err
 	}
+	defer release()
 
-	// Publish the message to RabbitMQ.
-	fmt.Println("Publishing to RabbitMQ: %s", msgBytes)
-	if err := uc.publisher.Publish(msgBytes); err != nil {
-		return err
+	runResp, err := a.repo.RunAutomation(ctx, project, testsuiteID, email, refnum, browser)
+	if err != nil {
+		return runResp, err
 	}
-	return nil
+	return runResp, nil
 }
@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"service-test-runner/internal/domain"
+	"service-test-runner/internal/repository/selenium"
+)
+
+func TestRunChecksGridAndCapacityBeforeTheRunner(t *testing.T) {
+	tests := []struct {
+		name        string
+		gridErr     error
+		capacityErr error
+		runnerErr   error
+		wantErr     error
+		wantRun     bool
+	}{
+		{name: "started", wantRun: true},
+		{name: "browser not offered", gridErr: ErrNoMatchingBrowser, wantErr: ErrNoMatchingBrowser},
+		{name: "grid busy", gridErr: selenium.ErrRunnerBusy, wantErr: selenium.ErrRunnerBusy},
+		{name: "runner at capacity", capacityErr: selenium.ErrRunnerBusy, wantErr: selenium.ErrRunnerBusy},
+		{name: "runner answers 429", runnerErr: selenium.ErrRunnerBusy, wantErr: selenium.ErrRunnerBusy},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runner := &fakeSelenium{err: tt.runnerErr}
+			capacity := &fakeCapacity{err: tt.capacityErr}
+			uc := NewAutomationUsecase(runner, capacity, &fakeGrid{err: tt.gridErr})
+
+			resp, err := uc.Run(context.Background(), "web1", "suite1", "dev@example.com", "ref-1", domain.Browser{BrowserName: "chrome"})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Run = %v, want %v", err, tt.wantErr)
+			}
+			if ran := len(runner.triggered) == 1; ran != tt.wantRun || (ran && resp.RunningID != "run-ref-1") {
+				t.Fatalf("runner triggered %v, response %+v", runner.triggered, resp)
+			}
+			if capacity.reserved != capacity.released {
+				t.Fatalf("reserved %d slots, released %d", capacity.reserved, capacity.released)
+			}
+			if tt.gridErr != nil && capacity.reserved != 0 {
+				t.Fatal("a slot was reserved for a run the grid refused")
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+
+	"service-test-runner/internal/domain"
+	automationRepo "service-test-runner/internal/repository/automation"
+	"service-test-runner/internal/repository/project"
+	"service-test-runner/internal/repository/selenium"
+)
+
+// CapacityUsecase keeps the runs of each project within the capacity of its runner,
+// max_concurrent_runs in tbl_projects. The runs in progress are the triggered rows of
+// tbl_queue_automations, plus the runs this instance is sending to the runner.
+type CapacityUsecase struct {
+	projects  project.ProjectRepository
+	queueRepo automationRepo.QueueAutomationRepository
+
+	mu       sync.Mutex
+	starting map[string]int
+	// reserving serializes the reservations of each project, so that two of them never
+	// both take its last slot, without holding up the other projects.
+	reserving map[string]*sync.Mutex
+}
+
+// NewCapacityUsecase creates a new CapacityUsecase with its dependencies injected.
+func NewCapacityUsecase(projects project.ProjectRepository, queueRepo automationRepo.QueueAutomationRepository) *CapacityUsecase {
+	return &CapacityUsecase{
+		projects:  projects,
+		queueRepo: queueRepo,
+		starting:  make(map[string]int),
+		reserving: make(map[string]*sync.Mutex),
+	}
+}
+
+// Reserve takes a slot on the runner of project for the run with referenceNumber, about
+// to be sent to it, and returns selenium.ErrRunnerBusy, without calling the runner, when
+// every slot is taken. A queued run already claimed for its start does not count against
+// itself. The returned release must be called once the runner has answered.
+func (uc *CapacityUsecase) Reserve(ctx context.Context, projectName, referenceNumber string) (release func(), err error) {
+	lock := uc.projectLock(projectName)
+	lock.Lock()
+	defer lock.Unlock()
+
+	record, err := uc.projects.GetByName(ctx, projectName)
+	if err != nil {
+		return nil, err
+	}
+	if record != nil && record.MaxConcurrentRuns > 0 {
+		active, err := uc.queueRepo.CountByProjectAndStatus(ctx, projectName, domain.RunStatusTriggered, referenceNumber)
+		if err != nil {
+			return nil, err
+		}
+		uc.mu.Lock()
+		starting := uc.starting[projectName]
+		uc.mu.Unlock()
+		if active+int64(starting) >= int64(record.MaxConcurrentRuns) {
+			return nil, selenium.ErrRunnerBusy
+		}
+	}
+
+	uc.mu.Lock()
+	uc.starting[projectName]++
+	uc.mu.Unlock()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			uc.mu.Lock()
+			defer uc.mu.Unlock()
+			if uc.starting[projectName]--; uc.starting[projectName] <= 0 {
+				delete(uc.starting, projectName)
+			}
+		})
+	}, nil
+}
+
+// projectLock returns the mutex serializing the reservations of a project.
+func (uc *CapacityUsecase) projectLock(projectName string) *sync.Mutex {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	lock, ok := uc.reserving[projectName]
+	if !ok {
+		lock = &sync.Mutex{}
+		uc.reserving[projectName] = lock
+	}
+	return lock
+}
+
+// Usage returns the slot usage of every project runner.
+func (uc *CapacityUsecase) Usage(ctx context.Context) ([]domain.ProjectSlots, error) {
+	projects, err := uc.projects.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	active, err := uc.countByProject(ctx, domain.RunStatusTriggered)
+	if err != nil {
+		return nil, err
+	}
+	queued, err := uc.countByProject(ctx, domain.RunStatusQueued)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	usage := make([]domain.ProjectSlots, 0, len(projects))
+	for _, p := range projects {
+		slots := domain.ProjectSlots{
+			Project:           p.Name,
+			MaxConcurrentRuns: p.MaxConcurrentRuns,
+			Active:            active[p.Name],
+			Starting:          uc.starting[p.Name],
+			Queued:            queued[p.Name],
+		}
+		if p.MaxConcurrentRuns > 0 {
+			available := int64(p.MaxConcurrentRuns) - slots.Active - int64(slots.Starting)
+			if available < 0 {
+				available = 0
+			}
+			slots.Available = &available
+		}
+		usage = append(usage, slots)
+	}
+	return usage, nil
+}
+
+// countByProject counts the runs with the given status per project.
+func (uc *CapacityUsecase) countByProject(ctx context.Context, status int) (map[string]int64, error) {
+	counts, err := uc.queueRepo.CountByRequester(ctx, status)
+	if err != nil {
+		return nil, err
+	}
+	byProject := make(map[string]int64)
+	for _, c := range counts {
+		byProject[c.Project] += c.Count
+	}
+	return byProject, nil
+}
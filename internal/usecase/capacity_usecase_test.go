@@ -0,0 +1,71 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"service-test-runner/internal/db/dbtest"
+	"service-test-runner/internal/domain"
+	automationRepo "service-test-runner/internal/repository/automation"
+	"service-test-runner/internal/repository/project"
+	"service-test-runner/internal/repository/selenium"
+)
+
+func TestReserveKeepsRunsWithinCapacity(t *testing.T) {
+	ctx := context.Background()
+	gdb := dbtest.Open(t)
+	if err := gdb.Exec("UPDATE tbl_projects SET max_concurrent_runs = ? WHERE name = ?", 2, "web1").Error; err != nil {
+		t.Fatal(err)
+	}
+	runs := automationRepo.NewQueueAutomationRepository(gdb)
+	uc := NewCapacityUsecase(project.NewProjectRepository(gdb), runs)
+
+	active := domain.Run{ReferenceNumber: "ref-1", IdTest: "run-1", Testsuite: "suite1", Project: "web1", Status: domain.RunStatusTriggered}
+	claimed := domain.Run{ReferenceNumber: "ref-2", IdTest: "run-2", Testsuite: "suite1", Project: "web1", Status: domain.RunStatusTriggered}
+	for _, run := range []*domain.Run{&active, &claimed} {
+		if err := runs.Create(ctx, run); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The claimed run takes the second slot itself.
+	release, err := uc.Reserve(ctx, "web1", "ref-2")
+	if err != nil {
+		t.Fatalf("Reserve for the claimed run = %v", err)
+	}
+	if _, err := uc.Reserve(ctx, "web1", "ref-3"); !errors.Is(err, selenium.ErrRunnerBusy) {
+		t.Fatalf("Reserve on a full runner = %v, want ErrRunnerBusy", err)
+	}
+
+	// Other projects are not affected.
+	other, err := uc.Reserve(ctx, "mobile1", "ref-4")
+	if err != nil {
+		t.Fatalf("Reserve on another project = %v", err)
+	}
+	other()
+
+	// Releasing twice frees a single slot.
+	release()
+	release()
+	usage, err := uc.Usage(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, slots := range usage {
+		if slots.Starting != 0 {
+			t.Fatalf("%s has %d runs starting after release, want 0", slots.Project, slots.Starting)
+		}
+	}
+	if err := runs.UpdateStatusByReferenceNumber(ctx, "run-2", "ref-2", domain.RunStatusPassed); err != nil {
+		t.Fatal(err)
+	}
+	next, err := uc.Reserve(ctx, "web1", "ref-3")
+	if err != nil {
+		t.Fatalf("Reserve after a run finished = %v", err)
+	}
+	if _, err := uc.Reserve(ctx, "web1", "ref-5"); !errors.Is(err, selenium.ErrRunnerBusy) {
+		t.Fatalf("Reserve with a run starting = %v, want ErrRunnerBusy", err)
+	}
+	next()
+}
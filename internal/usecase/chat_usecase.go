@@ -0,0 +1,188 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"service-test-runner/internal/domain"
+	"service-test-runner/internal/infrastructure/logging"
+	"service-test-runner/internal/infrastructure/notification"
+	automationRepo "service-test-runner/internal/repository/automation"
+	chatRepo "service-test-runner/internal/repository/chat"
+)
+
+var (
+	// ErrChatNotificationNotFound is returned for unknown chat notifications.
+	ErrChatNotificationNotFound = errors.New("chat notification not found")
+	// ErrInvalidChatNotification is returned when a chat notification is malformed.
+	ErrInvalidChatNotification = errors.New("invalid chat notification")
+)
+
+// ChatUsecase posts Slack and Microsoft Teams messages when a run fails, or passes
+// after the previous run of its test suite failed.
+type ChatUsecase struct {
+	repo          chatRepo.ChatRepository
+	queueRepo     automationRepo.QueueAutomationRepository
+	notifications runSummarizer
+	poster        notification.ChatSender
+}
+
+// runSummarizer summarizes a finished run for its notifications.
+type runSummarizer interface {
+	Summarize(ctx context.Context, event domain.RunEvent) (domain.RunSummary, error)
+}
+
+// NewChatUsecase creates a new ChatUsecase with its dependencies injected.
+func NewChatUsecase(
+	repo chatRepo.ChatRepository,
+	queueRepo automationRepo.QueueAutomationRepository,
+	notifications runSummarizer,
+	poster notification.ChatSender,
+) *ChatUsecase {
+	return &ChatUsecase{
+		repo:          repo,
+		queueRepo:     queueRepo,
+		notifications: notifications,
+		poster:        poster,
+	}
+}
+
+// List returns the chat notifications of a project.
+func (uc *ChatUsecase) List(ctx context.Context, project string) ([]domain.ChatNotification, error) {
+	return uc.repo.GetByProject(ctx, project)
+}
+
+// Create adds a chat notification to a project, or to one of its test suites.
+func (uc *ChatUsecase) Create(ctx context.Context, chat domain.ChatNotification) (domain.ChatNotification, error) {
+	if err := validateChat(chat); err != nil {
+		return domain.ChatNotification{}, err
+	}
+	created := &domain.ChatNotification{
+		Project:    chat.Project,
+		Testsuite:  chat.Testsuite,
+		Provider:   chat.Provider,
+		WebhookURL: chat.WebhookURL,
+		Active:     chat.Active,
+	}
+	if err := uc.repo.Create(ctx, created); err != nil {
+		return domain.ChatNotification{}, err
+	}
+	return *created, nil
+}
+
+// Update replaces the test suite, provider, webhook URL and active flag of a chat notification.
+func (uc *ChatUsecase) Update(ctx context.Context, id uint, chat domain.ChatNotification) (domain.ChatNotification, error) {
+	existing, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return domain.ChatNotification{}, err
+	}
+	if existing == nil {
+		return domain.ChatNotification{}, ErrChatNotificationNotFound
+	}
+	chat.Project = existing.Project
+	if err := validateChat(chat); err != nil {
+		return domain.ChatNotification{}, err
+	}
+
+	existing.Testsuite = chat.Testsuite
+	existing.Provider = chat.Provider
+	existing.WebhookURL = chat.WebhookURL
+	existing.Active = chat.Active
+	if err := uc.repo.Update(ctx, existing); err != nil {
+		return domain.ChatNotification{}, err
+	}
+	return *existing, nil
+}
+
+// Delete removes a chat notification.
+func (uc *ChatUsecase) Delete(ctx context.Context, id uint) error {
+	existing, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return ErrChatNotificationNotFound
+	}
+	return uc.repo.Delete(ctx, id)
+}
+
+// PostRunFinished is a RunListener that posts a chat message when a run fails or recovers.
+// Chat notifications of the run's test suite take precedence over the project-wide ones.
+func (uc *ChatUsecase) PostRunFinished(event domain.RunEvent) {
+	if event.Type != domain.RunEventFinished {
+		return
+	}
+	ctx := logging.With(context.Background(), "project", event.Project)
+	chats, err := uc.targets(ctx, event.Project, event.Testsuite)
+	if err != nil {
+		logging.FromContext(ctx).Error("Error loading chat notifications", "error", err)
+		return
+	}
+	if len(chats) == 0 {
+		return
+	}
+
+	recovered := false
+	if event.Status == domain.RunStatusPassed {
+		previous, err := uc.queueRepo.GetPreviousFinished(ctx, event.Project, event.Testsuite, event.ReferenceNumber, event.StartedAt, domain.FinalRunStatuses)
+		if err != nil {
+			logging.FromContext(ctx).Error("Error loading the previous run", "testsuite", event.Testsuite, "error", err)
+			return
+		}
+		if previous == nil || previous.Status != domain.RunStatusFailed {
+			return
+		}
+		recovered = true
+	}
+
+	summary, err := uc.notifications.Summarize(ctx, event)
+	if err != nil {
+		logging.FromContext(ctx).Error("Error summarizing run", "error", err)
+		return
+	}
+	summary.Recovered = recovered
+	for _, chat := range chats {
+		if err := uc.poster.Post(ctx, chat.Provider, chat.WebhookURL, summary); err != nil {
+			logging.FromContext(ctx).Error("Error posting chat notification", "provider", chat.Provider, "chat_id", chat.ID, "error", err)
+		}
+	}
+}
+
+// targets returns the active chat notifications of a test suite, falling back to the
+// project-wide ones when the suite has none.
+func (uc *ChatUsecase) targets(ctx context.Context, project, testsuite string) ([]domain.ChatNotification, error) {
+	rows, err := uc.repo.GetByProject(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+	var suite, projectWide []domain.ChatNotification
+	for _, row := range rows {
+		switch {
+		case !row.Active:
+		case row.Testsuite == testsuite:
+			suite = append(suite, row)
+		case row.Testsuite == "":
+			projectWide = append(projectWide, row)
+		}
+	}
+	if len(suite) > 0 {
+		return suite, nil
+	}
+	return projectWide, nil
+}
+
+func validateChat(chat domain.ChatNotification) error {
+	if chat.Project == "" {
+		return fmt.Errorf("%w: project is required", ErrInvalidChatNotification)
+	}
+	if chat.Provider != domain.ChatProviderSlack && chat.Provider != domain.ChatProviderTeams {
+		return fmt.Errorf("%w: provider must be %q or %q", ErrInvalidChatNotification, domain.ChatProviderSlack, domain.ChatProviderTeams)
+	}
+	u, err := url.Parse(chat.WebhookURL)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("%w: webhook_url must be an absolute https URL", ErrInvalidChatNotification)
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"service-test-runner/internal/config"
+	"service-test-runner/internal/domain"
+	"service-test-runner/internal/infrastructure/logging"
+	automationRepo "service-test-runner/internal/repository/automation"
+	"service-test-runner/internal/repository/selenium"
+)
+
+// ErrRunNotQueued is returned when a queued run was already taken out of the queue, by
+// the dispatcher or a consumer.
+var ErrRunNotQueued = errors.New("run is no longer queued")
+
+// requesterKey identifies a requester, by email, on the runner of a project.
+type requesterKey struct {
+	project string
+	email   string
+}
+
+// DispatchUsecase starts queued runs on their runner once it has room. Runs are
+// considered by priority; within a priority, the projects and then the requesters
+// with the fewest runs in progress go first, so a large batch from one requester
+// cannot starve the others.
+type DispatchUsecase struct {
+	repo       automationRepo.QueueAutomationRepository
+	queue      runStarter
+	automation runTrigger
+	cfg        config.DispatchConfig
+	wake       chan struct{}
+}
+
+// runStarter claims queued runs and records them as started or failed, see
+// QueueAutomationUseCase.
+type runStarter interface {
+	Claim(ctx context.Context, record *domain.Run) (bool, error)
+	Unclaim(ctx context.Context, record *domain.Run) error
+	Start(ctx context.Context, record *domain.Run, idTest, runnerURL string) (bool, error)
+	UpdateStatusByReferenceNumber(ctx context.Context, qa *domain.Run) error
+}
+
+// runTrigger triggers a run on the runner of its project, see AutomationUsecase.
+type runTrigger interface {
+	Run(ctx context.Context, project, testsuiteID, email, refnum string, browser domain.Browser) (domain.RunResponse, error)
+}
+
+// NewDispatchUsecase creates a new DispatchUsecase with its dependencies injected.
+func NewDispatchUsecase(repo automationRepo.QueueAutomationRepository, queue runStarter, automation runTrigger, cfg config.DispatchConfig) *DispatchUsecase {
+	return &DispatchUsecase{
+		repo:       repo,
+		queue:      queue,
+		automation: automation,
+		cfg:        cfg,
+		wake:       make(chan struct{}, 1),
+	}
+}
+
+// RunFinished wakes the dispatcher up when a run finishes, as its runner now has room
+// for a queued one. Subscribe it to the run events.
+func (uc *DispatchUsecase) RunFinished(event domain.RunEvent) {
+	if event.Type != domain.RunEventFinished {
+		return
+	}
+	select {
+	case uc.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Dispatch makes one pass over the queued runs, starting as many as the runners accept.
+// A project is skipped for the rest of the pass as soon as its runner refuses a run.
+func (uc *DispatchUsecase) Dispatch(ctx context.Context) error {
+	queued, err := uc.repo.GetByPriority(ctx, domain.RunStatusQueued, domain.RunPriorities, uc.cfg.BatchSize)
+	if err != nil || len(queued) == 0 {
+		return err
+	}
+	counts, err := uc.repo.CountByRequester(ctx, domain.RunStatusTriggered)
+	if err != nil {
+		return err
+	}
+	projectLoad := make(map[string]int64)
+	requesterLoad := make(map[requesterKey]int64)
+	for _, c := range counts {
+		projectLoad[c.Project] += c.Count
+		requesterLoad[requesterKey{c.Project, c.Email}] += c.Count
+	}
+
+	full := make(map[string]bool)
+	for len(queued) > 0 {
+		next := -1
+		for i := range queued {
+			qa := &queued[i]
+			key := requesterKey{qa.Project, qa.Email}
+			if full[qa.Project] || (uc.cfg.MaxActivePerRequester > 0 && requesterLoad[key] >= int64(uc.cfg.MaxActivePerRequester)) {
+				continue
+			}
+			if next < 0 || uc.before(qa, &queued[next], projectLoad, requesterLoad) {
+				next = i
+			}
+		}
+		if next < 0 {
+			return nil
+		}
+		qa := queued[next]
+		queued = append(queued[:next], queued[next+1:]...)
+
+		started, busy := uc.start(ctx, &qa)
+		if busy {
+			full[qa.Project] = true
+		}
+		if !started {
+			continue
+		}
+		projectLoad[qa.Project]++
+		requesterLoad[requesterKey{qa.Project, qa.Email}]++
+	}
+	return nil
+}
+
+// RunDispatcher starts queued runs every interval, and right after a run finishes,
+// until ctx is cancelled; a pass in progress is finished first, so no run is left
+// started on its runner but still queued here. As every run is claimed before it is
+// started, several instances of the service may run the dispatcher.
+func (uc *DispatchUsecase) RunDispatcher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-uc.wake:
+		case <-ctx.Done():
+			return
+		}
+		if err := uc.Dispatch(context.WithoutCancel(ctx)); err != nil {
+			logging.FromContext(ctx).Error("Run dispatcher failed", "error", err)
+		}
+	}
+}
+
+// before reports whether run a should be started before run b: a more urgent priority
+// first, then the less loaded project, then the less loaded requester. Runs that tie
+// keep their order, oldest first.
+func (uc *DispatchUsecase) before(a, b *domain.Run, projectLoad map[string]int64, requesterLoad map[requesterKey]int64) bool {
+	if ra, rb := priorityRank(a.Priority), priorityRank(b.Priority); ra != rb {
+		return ra < rb
+	}
+	if la, lb := projectLoad[a.Project], projectLoad[b.Project]; la != lb {
+		return la < lb
+	}
+	return requesterLoad[requesterKey{a.Project, a.Email}] < requesterLoad[requesterKey{b.Project, b.Email}]
+}
+
+// start starts a queued run and reports whether it was started, and whether the
+// project should be skipped for the rest of the pass.
+func (uc *DispatchUsecase) start(ctx context.Context, qa *domain.Run) (started, busy bool) {
+	ctx = logging.With(ctx, "reference_number", qa.ReferenceNumber, "project", qa.Project)
+	runResp, err := uc.StartQueued(ctx, qa)
+	switch {
+	case err == nil:
+		logging.FromContext(ctx).Info("Started queued run", "priority", qa.Priority, "runner_url", runResp.RunnerURL)
+		return true, false
+	case errors.Is(err, ErrRunNotQueued), errors.Is(err, ErrNoMatchingBrowser):
+		return false, false
+	case errors.Is(err, selenium.ErrRunnerBusy):
+		return false, true
+	default:
+		logging.FromContext(ctx).Warn("Error starting queued run", "error", err)
+		return false, true
+	}
+}
+
+// StartQueued claims a queued run and triggers it on its runner. Claiming moves the run
+// out of the queue only if it is still queued, so when the dispatcher and a consumer
+// both try to start it, the one that loses gets ErrRunNotQueued without calling the
+// runner. A run the runner did not take goes back to the queue, except one asking for a
+// browser that the project's grid does not offer: it can never start, so it fails.
+func (uc *DispatchUsecase) StartQueued(ctx context.Context, qa *domain.Run) (domain.RunResponse, error) {
+	claimed, err := uc.queue.Claim(ctx, qa)
+	if err != nil {
+		return domain.RunResponse{}, err
+	}
+	if !claimed {
+		return domain.RunResponse{}, ErrRunNotQueued
+	}
+
+	runResp, err := uc.automation.Run(ctx, qa.Project, qa.Testsuite, qa.Email, qa.ReferenceNumber, runBrowser(qa))
+	if errors.Is(err, ErrNoMatchingBrowser) {
+		logging.FromContext(ctx).Warn("Failing queued run", "error", err)
+		failed := &domain.Run{ReferenceNumber: qa.ReferenceNumber, IdTest: qa.IdTest, Status: domain.RunStatusFailed}
+		if err := uc.queue.UpdateStatusByReferenceNumber(context.WithoutCancel(ctx), failed); err != nil {
+			logging.FromContext(ctx).Error("Error marking queued run as failed", "error", err)
+		}
+		return runResp, err
+	}
+	if err != nil {
+		// Even when the client went away, as the run would otherwise never start.
+		if err := uc.queue.Unclaim(context.WithoutCancel(ctx), qa); err != nil {
+			logging.FromContext(ctx).Error("Error putting queued run back in the queue", "error", err)
+		}
+		return runResp, err
+	}
+
+	started, err := uc.queue.Start(context.WithoutCancel(ctx), qa, runResp.RunningID, runResp.RunnerURL)
+	if err != nil {
+		return runResp, err
+	}
+	if !started {
+		// The runner already reported a status of the run.
+		return runResp, ErrRunNotQueued
+	}
+	return runResp, nil
+}
+
+// runBrowser returns the browser a stored run asked for.
+func runBrowser(qa *domain.Run) domain.Browser {
+	return domain.Browser{
+		BrowserName:    qa.BrowserName,
+		BrowserVersion: qa.BrowserVersion,
+		PlatformName:   qa.PlatformName,
+	}
+}
+
+// priorityRank ranks unknown priorities, such as those of runs queued before priorities existed, as normal.
+func priorityRank(priority string) int {
+	if rank := domain.RunPriorityRank(priority); rank >= 0 {
+		return rank
+	}
+	return domain.RunPriorityRank(domain.RunPriorityNormal)
+}
@@ -0,0 +1,115 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"service-test-runner/internal/config"
+	"service-test-runner/internal/db/dbtest"
+	"service-test-runner/internal/domain"
+	automationRepo "service-test-runner/internal/repository/automation"
+)
+
+func TestDispatchSharesRunnersFairly(t *testing.T) {
+	ctx := context.Background()
+	runs := automationRepo.NewQueueAutomationRepository(dbtest.Open(t))
+	for _, run := range []domain.Run{
+		{ReferenceNumber: "w-1", Project: "web1", Email: "a@example.com"},
+		{ReferenceNumber: "w-2", Project: "web1", Email: "a@example.com"},
+		{ReferenceNumber: "w-3", Project: "web1", Email: "b@example.com"},
+		{ReferenceNumber: "m-1", Project: "mobile1", Email: "a@example.com", BrowserName: "opera"},
+		{ReferenceNumber: "m-2", Project: "mobile1", Email: "a@example.com"},
+	} {
+		run.Testsuite, run.Priority, run.Status = "suite1", domain.RunPriorityNormal, domain.RunStatusQueued
+		if err := runs.Create(ctx, &run); err != nil {
+			t.Fatal(err)
+		}
+	}
+	trigger := &fakeTrigger{
+		slots: map[string]int{"web1": 2, "mobile1": 2},
+		errs:  map[string]error{"m-1": ErrNoMatchingBrowser},
+	}
+	starter := &fakeStarter{}
+	uc := NewDispatchUsecase(runs, starter, trigger, config.DispatchConfig{BatchSize: 10})
+
+	if err := uc.Dispatch(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// The least loaded project goes next, then within it the least loaded requester;
+	// web1 is skipped once its runner is full.
+	if want := []string{"w-1", "m-1", "m-2", "w-3", "w-2"}; !reflect.DeepEqual(trigger.triggered, want) {
+		t.Errorf("triggered %v, want %v", trigger.triggered, want)
+	}
+	if want := []string{"w-1", "m-2", "w-3"}; !reflect.DeepEqual(starter.started, want) {
+		t.Errorf("started %v, want %v", starter.started, want)
+	}
+	if want := map[string]int{"m-1": domain.RunStatusFailed}; !reflect.DeepEqual(starter.updated, want) {
+		t.Errorf("updated %v, want %v", starter.updated, want)
+	}
+	// The run the full runner refused goes back to the queue.
+	if want := []string{"w-2"}; !reflect.DeepEqual(starter.unclaimed, want) {
+		t.Errorf("put back %v, want %v", starter.unclaimed, want)
+	}
+}
+
+func TestDispatchSkipsRunsTakenElsewhere(t *testing.T) {
+	ctx := context.Background()
+	runs := automationRepo.NewQueueAutomationRepository(dbtest.Open(t))
+	for _, ref := range []string{"taken", "free"} {
+		run := domain.Run{ReferenceNumber: ref, Project: "web1", Testsuite: "suite1", Priority: domain.RunPriorityNormal, Status: domain.RunStatusQueued}
+		if err := runs.Create(ctx, &run); err != nil {
+			t.Fatal(err)
+		}
+	}
+	trigger := &fakeTrigger{slots: map[string]int{"web1": 10}}
+	starter := &fakeStarter{taken: map[string]bool{"taken": true}}
+	uc := NewDispatchUsecase(runs, starter, trigger, config.DispatchConfig{BatchSize: 10})
+
+	if err := uc.Dispatch(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"free"}; !reflect.DeepEqual(trigger.triggered, want) {
+		t.Errorf("triggered %v, want %v", trigger.triggered, want)
+	}
+	if want := []string{"free"}; !reflect.DeepEqual(starter.started, want) {
+		t.Errorf("started %v, want %v", starter.started, want)
+	}
+	if _, err := uc.StartQueued(ctx, &domain.Run{ReferenceNumber: "taken", Project: "web1"}); !errors.Is(err, ErrRunNotQueued) {
+		t.Errorf("StartQueued of a taken run = %v, want ErrRunNotQueued", err)
+	}
+}
+
+func TestDispatchLimitsActiveRunsPerRequester(t *testing.T) {
+	ctx := context.Background()
+	runs := automationRepo.NewQueueAutomationRepository(dbtest.Open(t))
+	for _, run := range []domain.Run{
+		{ReferenceNumber: "active", Status: domain.RunStatusTriggered, Email: "a@example.com"},
+		{ReferenceNumber: "a-1", Status: domain.RunStatusQueued, Email: "a@example.com"},
+		{ReferenceNumber: "b-1", Status: domain.RunStatusQueued, Email: "b@example.com"},
+		{ReferenceNumber: "a-2", Status: domain.RunStatusQueued, Email: "a@example.com", Priority: domain.RunPriorityCritical},
+	} {
+		run.Project, run.Testsuite = "web1", "suite1"
+		if run.Priority == "" {
+			run.Priority = domain.RunPriorityNormal
+		}
+		if err := runs.Create(ctx, &run); err != nil {
+			t.Fatal(err)
+		}
+	}
+	trigger := &fakeTrigger{slots: map[string]int{"web1": 10}}
+	starter := &fakeStarter{}
+	uc := NewDispatchUsecase(runs, starter, trigger, config.DispatchConfig{BatchSize: 10, MaxActivePerRequester: 2})
+
+	if err := uc.Dispatch(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// The critical run goes first and takes the last slot of its requester.
+	if want := []string{"a-2", "b-1"}; !reflect.DeepEqual(starter.started, want) {
+		t.Errorf("started %v, want %v", starter.started, want)
+	}
+}
@@ -0,0 +1,206 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"service-test-runner/internal/domain"
+	"service-test-runner/internal/infrastructure/storage"
+	"service-test-runner/internal/repository/selenium"
+)
+
+// fakeStore is an in-memory ArtifactStore. Deleting an object listed in failDelete
+// fails, as does putting one whose name ends with failPut.
+type fakeStore struct {
+	mu         sync.Mutex
+	objects    map[string][]byte
+	failDelete map[string]bool
+	failPut    string
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{objects: map[string][]byte{}, failDelete: map[string]bool{}}
+}
+
+func (s *fakeStore) Put(ctx context.Context, objectName string, reader io.Reader, size int64, contentType string) error {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failPut != "" && strings.HasSuffix(objectName, s.failPut) {
+		return fmt.Errorf("put %s refused", objectName)
+	}
+	s.objects[objectName] = data
+	return nil
+}
+
+func (s *fakeStore) Get(ctx context.Context, objectName string) (io.ReadCloser, storage.ObjectInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.objects[objectName]
+	if !ok {
+		return nil, storage.ObjectInfo{}, storage.ErrObjectNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), storage.ObjectInfo{Name: objectName, Size: int64(len(data))}, nil
+}
+
+func (s *fakeStore) Stat(ctx context.Context, objectName string) (storage.ObjectInfo, error) {
+	_, info, err := s.Get(ctx, objectName)
+	return info, err
+}
+
+func (s *fakeStore) Delete(ctx context.Context, objectName string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failDelete[objectName] {
+		return fmt.Errorf("delete %s refused", objectName)
+	}
+	delete(s.objects, objectName)
+	return nil
+}
+
+func (s *fakeStore) List(ctx context.Context, prefix string) ([]storage.ObjectInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var infos []storage.ObjectInfo
+	for name, data := range s.objects {
+		if strings.HasPrefix(name, prefix) {
+			infos = append(infos, storage.ObjectInfo{Name: name, Size: int64(len(data))})
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos, nil
+}
+
+func (s *fakeStore) SignedURL(ctx context.Context, objectName string) (string, error) {
+	return "https://storage.example.com/" + objectName, nil
+}
+
+func (s *fakeStore) Ping(ctx context.Context) error { return nil }
+
+func (s *fakeStore) has(objectName string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.objects[objectName]
+	return ok
+}
+
+// fakePublisher records the messages it publishes; while err is set it refuses them.
+type fakePublisher struct {
+	mu        sync.Mutex
+	err       error
+	published []string
+}
+
+func (p *fakePublisher) Publish(ctx context.Context, project, priority string, message []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return p.err
+	}
+	p.published = append(p.published, string(message))
+	return nil
+}
+
+// fakeSelenium is a runner that records the runs triggered on it. Only RunAutomation
+// is implemented.
+type fakeSelenium struct {
+	selenium.SeleniumRepository
+	err       error
+	triggered []string
+}
+
+func (s *fakeSelenium) RunAutomation(ctx context.Context, project, testsuiteID, email, refnum string, browser domain.Browser) (domain.RunResponse, error) {
+	if s.err != nil {
+		return domain.RunResponse{}, s.err
+	}
+	s.triggered = append(s.triggered, refnum)
+	return domain.RunResponse{RunningID: "run-" + refnum, ReferenceNumber: refnum}, nil
+}
+
+// fakeCapacity counts the slots reserved and released; while err is set it refuses them.
+type fakeCapacity struct {
+	err      error
+	reserved int
+	released int
+}
+
+func (c *fakeCapacity) Reserve(ctx context.Context, projectName, referenceNumber string) (func(), error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	c.reserved++
+	return func() { c.released++ }, nil
+}
+
+// fakeGrid answers every check with err.
+type fakeGrid struct {
+	err error
+}
+
+func (g *fakeGrid) Check(ctx context.Context, project string, browser domain.Browser) error {
+	return g.err
+}
+
+// fakeTrigger starts runs on runners with room for slots[project] runs. A run listed
+// in errs fails with its error instead.
+type fakeTrigger struct {
+	slots     map[string]int
+	errs      map[string]error
+	triggered []string
+}
+
+func (t *fakeTrigger) Run(ctx context.Context, project, testsuiteID, email, refnum string, browser domain.Browser) (domain.RunResponse, error) {
+	t.triggered = append(t.triggered, refnum)
+	if err := t.errs[refnum]; err != nil {
+		return domain.RunResponse{}, err
+	}
+	if t.slots[project] == 0 {
+		return domain.RunResponse{}, selenium.ErrRunnerBusy
+	}
+	t.slots[project]--
+	return domain.RunResponse{RunningID: "run-" + refnum, ReferenceNumber: refnum, RunnerURL: "http://" + project}, nil
+}
+
+// fakeStarter records the runs claimed, put back and marked as started, and the
+// statuses set on the others. The runs listed in taken cannot be claimed.
+type fakeStarter struct {
+	taken     map[string]bool
+	claimed   []string
+	unclaimed []string
+	started   []string
+	updated   map[string]int
+}
+
+func (s *fakeStarter) Claim(ctx context.Context, record *domain.Run) (bool, error) {
+	if s.taken[record.ReferenceNumber] {
+		return false, nil
+	}
+	s.claimed = append(s.claimed, record.ReferenceNumber)
+	return true, nil
+}
+
+func (s *fakeStarter) Unclaim(ctx context.Context, record *domain.Run) error {
+	s.unclaimed = append(s.unclaimed, record.ReferenceNumber)
+	return nil
+}
+
+func (s *fakeStarter) Start(ctx context.Context, record *domain.Run, idTest, runnerURL string) (bool, error) {
+	s.started = append(s.started, record.ReferenceNumber)
+	return true, nil
+}
+
+func (s *fakeStarter) UpdateStatusByReferenceNumber(ctx context.Context, qa *domain.Run) error {
+	if s.updated == nil {
+		s.updated = map[string]int{}
+	}
+	s.updated[qa.ReferenceNumber] = qa.Status
+	return nil
+}
@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"service-test-runner/internal/domain"
+	"service-test-runner/internal/repository/grid"
+	"service-test-runner/internal/repository/selenium"
+)
+
+// ErrNoMatchingBrowser is returned when no registered node of a project's Selenium Grid
+// offers the requested browser.
+var ErrNoMatchingBrowser = errors.New("no grid node offers the requested browser")
+
+// GridUsecase reports the capacity of the Selenium Grids behind the project runners,
+// and checks a run's browser against it before the run is sent to the runner.
+type GridUsecase struct {
+	repo grid.GridRepository
+}
+
+// NewGridUsecase creates a new GridUsecase with its dependencies injected.
+func NewGridUsecase(repo grid.GridRepository) *GridUsecase {
+	return &GridUsecase{repo: repo}
+}
+
+// Status returns the nodes, slots and browsers of the grid of a project.
+func (uc *GridUsecase) Status(ctx context.Context, project string) (domain.GridStatus, error) {
+	return uc.repo.Status(ctx, project)
+}
+
+// Check requires a node of the project's grid to offer the browser. It returns
+// ErrNoMatchingBrowser only when no registered node offers it, whatever the node's
+// availability, and selenium.ErrRunnerBusy when the nodes offering it are not UP, none
+// is registered yet or every matching slot is taken, so the run is queued. Projects
+// without a grid always pass.
+func (uc *GridUsecase) Check(ctx context.Context, project string, browser domain.Browser) error {
+	if !uc.repo.HasGrid(project) {
+		return nil
+	}
+	status, err := uc.repo.Status(ctx, project)
+	if err != nil {
+		return fmt.Errorf("failed to get the grid status: %v", err)
+	}
+	if len(status.Nodes) == 0 {
+		return selenium.ErrRunnerBusy
+	}
+
+	offered := false
+	for _, node := range status.Nodes {
+		for _, b := range node.Browsers {
+			if matchesBrowser(b.Browser, browser) {
+				offered = true
+			}
+		}
+	}
+	if !offered {
+		return fmt.Errorf("%w: %s", ErrNoMatchingBrowser, describeBrowser(browser))
+	}
+
+	// Only nodes that are UP count towards the browsers of the status.
+	free := 0
+	for _, b := range status.Browsers {
+		if matchesBrowser(b.Browser, browser) {
+			free += b.FreeSlots
+		}
+	}
+	if !status.Ready || free == 0 {
+		return selenium.ErrRunnerBusy
+	}
+	return nil
+}
+
+// matchesBrowser reports whether a browser offered by the grid satisfies the requested one.
+func matchesBrowser(offered, requested domain.Browser) bool {
+	if requested.BrowserName != "" && !strings.EqualFold(offered.BrowserName, requested.BrowserName) {
+		return false
+	}
+	if requested.PlatformName != "" && !strings.EqualFold(offered.PlatformName, requested.PlatformName) {
+		return false
+	}
+	if requested.BrowserVersion == "" || offered.BrowserVersion == requested.BrowserVersion {
+		return true
+	}
+	return strings.HasPrefix(offered.BrowserVersion, requested.BrowserVersion+".")
+}
+
+func describeBrowser(browser domain.Browser) string {
+	parts := []string{}
+	for _, part := range []string{browser.BrowserName, browser.BrowserVersion, browser.PlatformName} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		return "any browser"
+	}
+	return strings.Join(parts, " ")
+}
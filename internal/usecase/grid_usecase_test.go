@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"service-test-runner/internal/domain"
+	"service-test-runner/internal/repository/selenium"
+)
+
+// fakeGridRepository answers Status with a fixed grid status.
+type fakeGridRepository struct {
+	status domain.GridStatus
+}
+
+func (f *fakeGridRepository) HasGrid(project string) bool { return true }
+
+func (f *fakeGridRepository) Status(ctx context.Context, project string) (domain.GridStatus, error) {
+	return f.status, nil
+}
+
+func gridNode(availability string, free int, browsers ...domain.Browser) domain.GridNode {
+	node := domain.GridNode{Availability: availability}
+	for _, b := range browsers {
+		node.Browsers = append(node.Browsers, domain.GridBrowser{Browser: b, Slots: 1, FreeSlots: free})
+	}
+	return node
+}
+
+func TestGridCheck(t *testing.T) {
+	chrome := domain.Browser{BrowserName: "chrome", BrowserVersion: "120.0.6099.109", PlatformName: "linux"}
+	firefox := domain.Browser{BrowserName: "firefox"}
+	up := domain.GridStatus{
+		Ready:    true,
+		Nodes:    []domain.GridNode{gridNode("UP", 1, chrome)},
+		Browsers: []domain.GridBrowser{{Browser: chrome, Slots: 1, FreeSlots: 1}},
+	}
+
+	tests := []struct {
+		name    string
+		status  domain.GridStatus
+		browser domain.Browser
+		want    error
+	}{
+		{"free slot", up, domain.Browser{BrowserName: "Chrome", BrowserVersion: "120"}, nil},
+		{"any browser", up, domain.Browser{}, nil},
+		{"browser on no node", up, firefox, ErrNoMatchingBrowser},
+		{"every slot taken", domain.GridStatus{
+			Ready:    true,
+			Nodes:    []domain.GridNode{gridNode("UP", 0, chrome)},
+			Browsers: []domain.GridBrowser{{Browser: chrome, Slots: 1}},
+		}, chrome, selenium.ErrRunnerBusy},
+		{"matching node down", domain.GridStatus{
+			Ready:    true,
+			Nodes:    []domain.GridNode{gridNode("UP", 1, chrome), gridNode("DOWN", 1, firefox)},
+			Browsers: []domain.GridBrowser{{Browser: chrome, Slots: 1, FreeSlots: 1}},
+		}, firefox, selenium.ErrRunnerBusy},
+		{"every node draining", domain.GridStatus{
+			Nodes: []domain.GridNode{gridNode("DRAINING", 1, chrome)},
+		}, domain.Browser{}, selenium.ErrRunnerBusy},
+		{"hub restarting", domain.GridStatus{}, chrome, selenium.ErrRunnerBusy},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := NewGridUsecase(&fakeGridRepository{status: tt.status})
+			err := uc.Check(context.Background(), "web1", tt.browser)
+			if tt.want == nil && err != nil || tt.want != nil && !errors.Is(err, tt.want) {
+				t.Fatalf("Check = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"service-test-runner/internal/domain"
+)
+
+// HealthCheck checks that one dependency of the service is reachable.
+type HealthCheck struct {
+	Name     string
+	Critical bool
+	Check    func(ctx context.Context) error
+}
+
+// HealthUsecase reports the liveness of the service and the readiness of its dependencies.
+type HealthUsecase struct {
+	checks  []HealthCheck
+	timeout time.Duration
+	started time.Time
+}
+
+// NewHealthUsecase creates a new HealthUsecase running the given checks, each bounded by timeout.
+func NewHealthUsecase(timeout time.Duration, checks ...HealthCheck) *HealthUsecase {
+	return &HealthUsecase{
+		checks:  checks,
+		timeout: timeout,
+		started: time.Now(),
+	}
+}
+
+// Live reports that the process is up, without touching any dependency.
+func (uc *HealthUsecase) Live() domain.HealthReport {
+	return domain.HealthReport{
+		Status:        domain.HealthStatusOK,
+		UptimeSeconds: int64(time.Since(uc.started).Seconds()),
+	}
+}
+
+// Ready runs every check concurrently. The report is down when a critical
+// dependency is down, and degraded when only non-critical ones are.
+func (uc *HealthUsecase) Ready(ctx context.Context) domain.HealthReport {
+	report := uc.Live()
+	report.Checks = make([]domain.HealthCheckResult, len(uc.checks))
+
+	var wg sync.WaitGroup
+	for i, check := range uc.checks {
+		wg.Add(1)
+		go func(i int, check HealthCheck) {
+			defer wg.Done()
+			report.Checks[i] = uc.run(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status == domain.HealthStatusOK {
+			continue
+		}
+		if result.Critical {
+			report.Status = domain.HealthStatusDown
+			break
+		}
+		report.Status = domain.HealthStatusDegraded
+	}
+	return report
+}
+
+// run runs a single check within the timeout, turning a panic into a failure.
+func (uc *HealthUsecase) run(ctx context.Context, check HealthCheck) (result domain.HealthCheckResult) {
+	ctx, cancel := context.WithTimeout(ctx, uc.timeout)
+	defer cancel()
+
+	result = domain.HealthCheckResult{Name: check.Name, Critical: check.Critical, Status: domain.HealthStatusOK}
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			result.Status, result.Error = domain.HealthStatusDown, fmt.Sprint(r)
+		}
+		result.DurationMs = float64(time.Since(start).Microseconds()) / 1000
+	}()
+
+	if err := check.Check(ctx); err != nil {
+		result.Status, result.Error = domain.HealthStatusDown, err.Error()
+	}
+	return result
+}
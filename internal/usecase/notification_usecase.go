@@ -0,0 +1,151 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+
+	"service-test-runner/internal/domain"
+	"service-test-runner/internal/infrastructure/logging"
+	"service-test-runner/internal/infrastructure/notification"
+	automationRepo "service-test-runner/internal/repository/automation"
+)
+
+// maxFailedSteps bounds the failed steps listed in a run summary.
+const maxFailedSteps = 20
+
+// NotificationUsecase reports finished runs to the people who requested them.
+type NotificationUsecase struct {
+	queueRepo automationRepo.QueueAutomationRepository
+	results   resultReader
+	artifacts reportLinker
+	mailer    notification.Mailer
+	templates *notification.Templates
+}
+
+// resultReader reads the stored results of a run.
+type resultReader interface {
+	GetResults(ctx context.Context, referenceNumber string) (domain.RunResults, error)
+}
+
+// reportLinker links to the report file of a run.
+type reportLinker interface {
+	ReportURL(ctx context.Context, reportFile string) (string, error)
+}
+
+// NewNotificationUsecase creates a new NotificationUsecase with its dependencies injected.
+// The mailer and templates may be nil when emails are disabled.
+func NewNotificationUsecase(
+	queueRepo automationRepo.QueueAutomationRepository,
+	results resultReader,
+	artifacts reportLinker,
+	mailer notification.Mailer,
+	templates *notification.Templates,
+) *NotificationUsecase {
+	return &NotificationUsecase{
+		queueRepo: queueRepo,
+		results:   results,
+		artifacts: artifacts,
+		mailer:    mailer,
+		templates: templates,
+	}
+}
+
+// EmailRunFinished is a RunListener that emails a run summary to the run's email
+// addresses, if any were given, once the run has finished.
+func (uc *NotificationUsecase) EmailRunFinished(event domain.RunEvent) {
+	recipients := splitRecipients(event.Email)
+	if event.Type != domain.RunEventFinished || len(recipients) == 0 {
+		return
+	}
+
+	ctx := logging.With(context.Background(), "project", event.Project)
+	summary, err := uc.Summarize(ctx, event)
+	if err != nil {
+		logging.FromContext(ctx).Error("Error summarizing run", "error", err)
+		return
+	}
+	subject, body, err := uc.templates.RunFinished(summary)
+	if err != nil {
+		logging.FromContext(ctx).Error("Error rendering run email", "error", err)
+		return
+	}
+	if err := uc.mailer.Send(recipients, subject, body); err != nil {
+		logging.FromContext(ctx).Error("Error emailing run summary", "error", err)
+		return
+	}
+	logging.FromContext(ctx).Info("Emailed run summary", "recipients", len(recipients))
+}
+
+// Summarize builds the summary of a finished run: verdict, duration, test counts,
+// failed steps and a signed link to its report.
+func (uc *NotificationUsecase) Summarize(ctx context.Context, event domain.RunEvent) (domain.RunSummary, error) {
+	summary := domain.RunSummary{
+		ReferenceNumber: event.ReferenceNumber,
+		Project:         event.Project,
+		Testsuite:       event.Testsuite,
+		Verdict:         "PASSED",
+		Duration:        event.OccurredAt.Sub(event.StartedAt),
+		FinishedAt:      event.OccurredAt,
+	}
+	if event.Status == domain.RunStatusFailed {
+		summary.Verdict = "FAILED"
+	}
+
+	results, err := uc.results.GetResults(ctx, event.ReferenceNumber)
+	if err != nil {
+		return domain.RunSummary{}, err
+	}
+	summary.Tests = results.Tests
+	summary.Passed = results.Passed
+	summary.Failed = results.Failures + results.Errors
+	summary.Skipped = results.Skipped
+	summary.FailedSteps = failedSteps(results)
+	if len(summary.FailedSteps) == 0 && event.Status == domain.RunStatusFailed && event.StepName != "" {
+		// Without structured results, the last reported step is the one that failed.
+		summary.FailedSteps = []domain.FailedStep{{Name: event.StepName}}
+	}
+
+	record, err := uc.queueRepo.GetByReferenceNumber(ctx, event.ReferenceNumber)
+	if err != nil {
+		return domain.RunSummary{}, err
+	}
+	if summary.ReportURL, err = uc.artifacts.ReportURL(ctx, record.ReportFile); err != nil {
+		return domain.RunSummary{}, err
+	}
+	return summary, nil
+}
+
+// failedSteps lists the failed test cases of a run, naming the failed step of BDD scenarios.
+func failedSteps(results domain.RunResults) []domain.FailedStep {
+	var steps []domain.FailedStep
+	for _, suite := range results.Suites {
+		for _, tc := range suite.Cases {
+			if tc.Status != domain.TestStatusFailed && tc.Status != domain.TestStatusError {
+				continue
+			}
+			if len(steps) == maxFailedSteps {
+				return steps
+			}
+			step := domain.FailedStep{Name: tc.Name, Message: tc.FailureMessage}
+			for _, s := range tc.Steps {
+				if s.Status != "passed" && s.Status != "skipped" {
+					step.Name = tc.Name + " > " + strings.TrimSpace(s.Keyword+" "+s.Name)
+					break
+				}
+			}
+			steps = append(steps, step)
+		}
+	}
+	return steps
+}
+
+// splitRecipients splits a comma or semicolon separated list of email addresses.
+func splitRecipients(emails string) []string {
+	var recipients []string
+	for _, email := range strings.FieldsFunc(emails, func(r rune) bool { return r == ',' || r == ';' }) {
+		if email = strings.TrimSpace(email); email != "" {
+			recipients = append(recipients, email)
+		}
+	}
+	return recipients
+}
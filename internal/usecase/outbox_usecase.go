@@ -0,0 +1,202 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"service-test-runner/internal/config"
+	"service-test-runner/internal/domain"
+	"service-test-runner/internal/infrastructure/logging"
+	"service-test-runner/internal/infrastructure/messaging"
+	"service-test-runner/internal/repository"
+	outboxRepo "service-test-runner/internal/repository/outbox"
+)
+
+// queuedRunMessage is the RabbitMQ message announcing a queued run to the consumers.
+type queuedRunMessage struct {
+	ReferenceNumber string `json:"reference_number"`
+	Project         string `json:"project"`
+	TestsuiteID     string `json:"testsuite_id"`
+	Email           string `json:"email"`
+	Priority        string `json:"priority"`
+	TotalSteps      int    `json:"total_steps"`
+	domain.Browser
+}
+
+// OutboxUsecase writes the RabbitMQ message of a queued run in the same transaction
+// as its queue row, and relays the stored messages to RabbitMQ until the broker
+// confirms them, so a queued run is never left without its message or vice versa.
+type OutboxUsecase struct {
+	repo      outboxRepo.OutboxRepository
+	uow       repository.UnitOfWork
+	publisher messaging.Publisher
+	cfg       config.OutboxConfig
+	wake      chan struct{}
+}
+
+// NewOutboxUsecase creates a new OutboxUsecase with its dependencies injected.
+func NewOutboxUsecase(repo outboxRepo.OutboxRepository, uow repository.UnitOfWork, publisher messaging.Publisher, cfg config.OutboxConfig) *OutboxUsecase {
+	return &OutboxUsecase{
+		repo:      repo,
+		uow:       uow,
+		publisher: publisher,
+		cfg:       cfg,
+		wake:      make(chan struct{}, 1),
+	}
+}
+
+// CreateQueued stores a new queued run together with its message.
+func (uc *OutboxUsecase) CreateQueued(ctx context.Context, qa *domain.Run) error {
+	message, err := newOutboxMessage(qa)
+	if err != nil {
+		return err
+	}
+	err = uc.uow.Do(ctx, func(tx repository.Transaction) error {
+		if err := tx.Runs().Create(ctx, qa); err != nil {
+			return err
+		}
+		return tx.Outbox().Create(ctx, message)
+	})
+	if err != nil {
+		return err
+	}
+	uc.notify()
+	return nil
+}
+
+// Requeue puts an existing run back in the queue together with a new message.
+func (uc *OutboxUsecase) Requeue(ctx context.Context, record *domain.Run) error {
+	message, err := newOutboxMessage(record)
+	if err != nil {
+		return err
+	}
+	err = uc.uow.Do(ctx, func(tx repository.Transaction) error {
+		if err := tx.Runs().UpdateStatusByReferenceNumber(ctx, record.IdTest, record.ReferenceNumber, domain.RunStatusQueued); err != nil {
+			return err
+		}
+		return tx.Outbox().Create(ctx, message)
+	})
+	if err != nil {
+		logging.FromContext(ctx).Error("Error requeueing QueueAutomation record with outbox message", "reference_number", record.ReferenceNumber, "error", err)
+		return err
+	}
+	uc.notify()
+	return nil
+}
+
+// Pending returns the number of messages not yet confirmed by RabbitMQ.
+func (uc *OutboxUsecase) Pending(ctx context.Context) (int64, error) {
+	return uc.repo.CountByStatus(ctx, domain.OutboxMessagePending)
+}
+
+// Relay publishes the pending messages in the order they were written. It stops at
+// the first failure, as the broker is most likely unreachable, and schedules that
+// message for a later attempt; until then the messages after it wait too, so none
+// overtakes an earlier one.
+func (uc *OutboxUsecase) Relay(ctx context.Context) error {
+	messages, err := uc.repo.GetOldest(ctx, domain.OutboxMessagePending, uc.cfg.BatchSize)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for i := range messages {
+		if messages[i].NextAttemptAt.After(now) {
+			return nil
+		}
+		if !uc.publish(ctx, &messages[i]) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// RunRelay publishes due messages every interval, and right after a run is queued,
+// until ctx is cancelled; a pass in progress is finished first. Only one instance of
+// the service should run the relay.
+func (uc *OutboxUsecase) RunRelay(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-uc.wake:
+		case <-ctx.Done():
+			return
+		}
+		if err := uc.Relay(context.WithoutCancel(ctx)); err != nil {
+			logging.FromContext(ctx).Error("Outbox relay failed", "error", err)
+		}
+	}
+}
+
+// publish makes one attempt at a message and reports whether it was confirmed.
+func (uc *OutboxUsecase) publish(ctx context.Context, message *domain.OutboxMessage) bool {
+	ctx = logging.With(ctx, "reference_number", message.ReferenceNumber)
+	err := uc.publisher.Publish(ctx, message.Project, message.Priority, []byte(message.Payload))
+
+	now := time.Now()
+	message.Attempts++
+	if err == nil {
+		message.Status = domain.OutboxMessageSent
+		message.Error = ""
+		message.SentAt = &now
+	} else {
+		message.Error = err.Error()
+		message.NextAttemptAt = now.Add(uc.backoff(message.Attempts))
+		logging.FromContext(ctx).Warn("Error publishing outbox message", "attempts", message.Attempts, "error", err)
+	}
+	if err := uc.repo.UpdateAttempt(ctx, message); err != nil {
+		logging.FromContext(ctx).Error("Error saving outbox message", "error", err)
+	}
+	return err == nil
+}
+
+// notify wakes the relay up without waiting for the next tick.
+func (uc *OutboxUsecase) notify() {
+	select {
+	case uc.wake <- struct{}{}:
+	default:
+	}
+}
+
+// backoff returns the delay before the attempt following the given one: the base
+// delay doubled for every earlier failed attempt, up to the maximum.
+func (uc *OutboxUsecase) backoff(attempts int) time.Duration {
+	delay := uc.cfg.BackoffBase
+	for i := 1; i < attempts && delay < uc.cfg.BackoffMax; i++ {
+		delay *= 2
+	}
+	if delay > uc.cfg.BackoffMax {
+		delay = uc.cfg.BackoffMax
+	}
+	return delay
+}
+
+// newOutboxMessage builds the pending message announcing a queued run.
+func newOutboxMessage(qa *domain.Run) (*domain.OutboxMessage, error) {
+	priority := qa.Priority
+	if priority == "" {
+		priority = domain.RunPriorityNormal
+	}
+	payload, err := json.Marshal(queuedRunMessage{
+		ReferenceNumber: qa.ReferenceNumber,
+		Project:         qa.Project,
+		TestsuiteID:     qa.Testsuite,
+		Email:           qa.Email,
+		Priority:        priority,
+		TotalSteps:      qa.TotalSteps,
+		Browser:         runBrowser(qa),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &domain.OutboxMessage{
+		ReferenceNumber: qa.ReferenceNumber,
+		Project:         qa.Project,
+		Priority:        priority,
+		Payload:         string(payload),
+		Status:          domain.OutboxMessagePending,
+		NextAttemptAt:   time.Now().Truncate(time.Second),
+	}, nil
+}
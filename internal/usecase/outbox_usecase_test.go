@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"service-test-runner/internal/config"
+	"service-test-runner/internal/db/dbtest"
+	"service-test-runner/internal/domain"
+	"service-test-runner/internal/repository"
+	outboxRepo "service-test-runner/internal/repository/outbox"
+)
+
+func TestRelayKeepsOrderAcrossRetries(t *testing.T) {
+	ctx := context.Background()
+	gdb := dbtest.Open(t)
+	outbox := outboxRepo.NewOutboxRepository(gdb)
+	publisher := &fakePublisher{}
+	uc := NewOutboxUsecase(outbox, repository.NewUnitOfWork(gdb), publisher,
+		config.OutboxConfig{BatchSize: 10, BackoffBase: time.Hour, BackoffMax: time.Hour})
+
+	for _, ref := range []string{"ref-1", "ref-2", "ref-3"} {
+		run := &domain.Run{ReferenceNumber: ref, Testsuite: "suite1", Project: "web1", Status: domain.RunStatusQueued}
+		if err := uc.CreateQueued(ctx, run); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	publisher.err = errors.New("broker unreachable")
+	if err := uc.Relay(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// ref-1 now waits for its retry, an hour away; the others must not overtake it.
+	publisher.err = nil
+	if err := uc.Relay(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(publisher.published) != 0 {
+		t.Fatalf("published %d messages while the first waits for its retry", len(publisher.published))
+	}
+
+	if err := gdb.Exec("UPDATE tbl_outbox_messages SET next_attempt_at = ?", time.Now().Add(-time.Second)).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := uc.Relay(ctx); err != nil {
+		t.Fatal(err)
+	}
+	var order []string
+	for _, payload := range publisher.published {
+		var message queuedRunMessage
+		if err := json.Unmarshal([]byte(payload), &message); err != nil {
+			t.Fatal(err)
+		}
+		order = append(order, message.ReferenceNumber)
+	}
+	if want := []string{"ref-1", "ref-2", "ref-3"}; !reflect.DeepEqual(order, want) {
+		t.Fatalf("published %v, want %v", order, want)
+	}
+	if pending, err := uc.Pending(ctx); err != nil || pending != 0 {
+		t.Fatalf("Pending = %d, %v; want 0", pending, err)
+	}
+}
@@ -6,10 +6,10 @@ import (
 )
 
 type ProjectUsecase struct {
-	repo *project.ProjectRepository
+	repo project.ProjectRepository
 }
 
-func NewProjectUsecase(repo *project.ProjectRepository) *ProjectUsecase {
+func NewProjectUsecase(repo project.ProjectRepository) *ProjectUsecase {
 	return &ProjectUsecase{repo: repo}
 }
 
@@ -1,35 +1,112 @@
 package usecase
 
 import (
+	"context"
 	"errors"
-	"service-test-runner/internal/db"
+
+	"service-test-runner/internal/domain"
 	automationRepo "service-test-runner/internal/repository/automation"
 )
 
 // QueueAutomationUseCase handles business logic for QueueAutomation operations.
 type QueueAutomationUseCase struct {
-	repo automationRepo.QueueAutomationRepository
+	repo   automationRepo.QueueAutomationRepository
+	outbox runOutbox
+	events runEmitter
+}
+
+// runOutbox stores runs together with their RabbitMQ message, see OutboxUsecase.
+type runOutbox interface {
+	CreateQueued(ctx context.Context, qa *domain.Run) error
+	Requeue(ctx context.Context, record *domain.Run) error
 }
 
 // NewQueueAutomationUseCase creates a new instance of QueueAutomationUseCase.
-func NewQueueAutomationUseCase(repo automationRepo.QueueAutomationRepository) *QueueAutomationUseCase {
-	return &QueueAutomationUseCase{repo: repo}
+func NewQueueAutomationUseCase(repo automationRepo.QueueAutomationRepository, outbox runOutbox, events runEmitter) *QueueAutomationUseCase {
+	return &QueueAutomationUseCase{repo: repo, outbox: outbox, events: events}
+}
+
+// Create stores a new run and emits its queued or started event.
+func (uc *QueueAutomationUseCase) Create(ctx context.Context, qa *domain.Run) error {
+	if err := uc.repo.Create(ctx, qa); err != nil {
+		return err
+	}
+	uc.events.created(qa)
+	return nil
+}
+
+// CreateQueued stores a new queued run together with its RabbitMQ message and emits its queued event.
+func (uc *QueueAutomationUseCase) CreateQueued(ctx context.Context, qa *domain.Run) error {
+	if err := uc.outbox.CreateQueued(ctx, qa); err != nil {
+		return err
+	}
+	uc.events.created(qa)
+	return nil
+}
+
+// Requeue puts a run back in the queue together with a new RabbitMQ message, so its
+// next terminal status is reported again.
+func (uc *QueueAutomationUseCase) Requeue(ctx context.Context, referenceNumber string) error {
+	record, err := uc.repo.GetByReferenceNumber(ctx, referenceNumber)
+	if err != nil {
+		return err
+	}
+	if record == nil {
+		return errors.New("record not found")
+	}
+	if err := uc.outbox.Requeue(ctx, record); err != nil {
+		return err
+	}
+	uc.events.statusChanged(record, domain.RunStatusQueued, record.StepName, record.Checkpoint)
+	return nil
+}
+
+// Claim takes a queued run out of the queue before it is sent to its runner, so that
+// only one of the dispatcher and the consumers starts it. It reports false if the run
+// had already left the queue. No event is emitted until the run is started.
+func (uc *QueueAutomationUseCase) Claim(ctx context.Context, record *domain.Run) (bool, error) {
+	return uc.repo.MarkStarted(ctx, record.ReferenceNumber, record.IdTest, record.RunnerURL, domain.RunStatusQueued, domain.RunStatusTriggered)
+}
+
+// Unclaim puts a claimed run that its runner did not take back in the queue.
+func (uc *QueueAutomationUseCase) Unclaim(ctx context.Context, record *domain.Run) error {
+	_, err := uc.repo.MarkStarted(ctx, record.ReferenceNumber, record.IdTest, record.RunnerURL, domain.RunStatusTriggered, domain.RunStatusQueued)
+	return err
+}
+
+// Start records the runner ID and node of a claimed run and emits its started event. It
+// reports false if the run is no longer triggered, as when its runner already reported
+// it finished.
+func (uc *QueueAutomationUseCase) Start(ctx context.Context, record *domain.Run, idTest, runnerURL string) (bool, error) {
+	started, err := uc.repo.MarkStarted(ctx, record.ReferenceNumber, idTest, runnerURL, domain.RunStatusTriggered, domain.RunStatusTriggered)
+	if err != nil || !started {
+		return started, err
+	}
+	record.IdTest = idTest
+	record.RunnerURL = runnerURL
+	uc.events.statusChanged(record, domain.RunStatusTriggered, record.StepName, record.Checkpoint)
+	return true, nil
 }
 
 // GetByIdTest retrieves automation details by ID
-func (uc *QueueAutomationUseCase) GetByIdTest(idTest string) (*db.TblQueueAutomation, error) {
-	return uc.repo.GetByIdTest(idTest)
+func (uc *QueueAutomationUseCase) GetByIdTest(ctx context.Context, idTest string) (*domain.Run, error) {
+	return uc.repo.GetByIdTest(ctx, idTest)
 }
 
 // GetByReferenceNumber retrieves automation details by reference number.
-func (uc *QueueAutomationUseCase) GetByReferenceNumber(referenceNumber string) (*db.TblQueueAutomation, error) {
-	return uc.repo.GetByReferenceNumber(referenceNumber)
+func (uc *QueueAutomationUseCase) GetByReferenceNumber(ctx context.Context, referenceNumber string) (*domain.Run, error) {
+	return uc.repo.GetByReferenceNumber(ctx, referenceNumber)
+}
+
+// QueueDepth returns the number of runs waiting for a free runner.
+func (uc *QueueAutomationUseCase) QueueDepth(ctx context.Context) (int64, error) {
+	return uc.repo.CountByStatus(ctx, domain.RunStatusQueued)
 }
 
 // UpdateStatusByReferenceNumber updates the status of a record by its reference number.
-func (uc *QueueAutomationUseCase) UpdateStatusByReferenceNumber(qa *db.TblQueueAutomation) error {
+func (uc *QueueAutomationUseCase) UpdateStatusByReferenceNumber(ctx context.Context, qa *domain.Run) error {
 	// Check if the record exists.
-	record, err := uc.repo.GetByReferenceNumber(qa.ReferenceNumber)
+	record, err := uc.repo.GetByReferenceNumber(ctx, qa.ReferenceNumber)
 	if err != nil {
 		return err
 	}
@@ -37,13 +114,29 @@ func (uc *QueueAutomationUseCase) UpdateStatusByReferenceNumber(qa *db.TblQueueA
 		return errors.New("record not found")
 	}
 	// If it exists, update the status.
-	return uc.repo.UpdateStatusByReferenceNumber(qa.IdTest, qa.ReferenceNumber, qa.Status)
+	finished, err := finishRun(ctx, uc.repo, qa.ReferenceNumber, qa.Status)
+	if err != nil {
+		return err
+	}
+	if err := uc.repo.UpdateStatusByReferenceNumber(ctx, qa.IdTest, qa.ReferenceNumber, qa.Status); err != nil {
+		return err
+	}
+	if qa.RunnerURL != "" {
+		if err := uc.repo.SetRunner(ctx, qa.ReferenceNumber, qa.RunnerURL); err != nil {
+			return err
+		}
+	}
+	if finished {
+		uc.events.finished(record, qa.Status, record.StepName, record.Checkpoint)
+	}
+	uc.events.statusChanged(record, qa.Status, record.StepName, record.Checkpoint)
+	return nil
 }
 
 // UpdateStatus checks for record existence before updating status.
-func (uc *QueueAutomationUseCase) UpdateStatus(idTest string, stepName string, status int, referenceNumber string) error {
+func (uc *QueueAutomationUseCase) UpdateStatus(ctx context.Context, idTest string, stepName string, status int, referenceNumber string) error {
 	// Check if the record exists.
-	record, err := uc.repo.GetByReferenceNumber(referenceNumber)
+	record, err := uc.repo.GetByReferenceNumber(ctx, referenceNumber)
 	if err != nil {
 		return err
 	}
@@ -56,10 +149,31 @@ func (uc *QueueAutomationUseCase) UpdateStatus(idTest string, stepName string, s
 	}
 
 	// If it exists, update the status.
-	return uc.repo.UpdateStatus(idTest, stepName, newCheckpoint, status, referenceNumber)
+	finished, err := finishRun(ctx, uc.repo, referenceNumber, status)
+	if err != nil {
+		return err
+	}
+	if err := uc.repo.UpdateStatus(ctx, idTest, stepName, newCheckpoint, status, referenceNumber); err != nil {
+		return err
+	}
+	if finished {
+		uc.events.finished(record, status, stepName, newCheckpoint)
+	}
+	uc.events.statusChanged(record, status, stepName, newCheckpoint)
+	return nil
+}
+
+// finishRun moves a run into a final status unless it already has one, and reports
+// whether it did. The move is a conditional update, so of concurrent updates finishing
+// the same run only one is reported. Other statuses are left to the caller.
+func finishRun(ctx context.Context, repo automationRepo.QueueAutomationRepository, referenceNumber string, status int) (bool, error) {
+	if !domain.IsFinalStatus(status) {
+		return false, nil
+	}
+	return repo.MarkFinished(ctx, referenceNumber, status, domain.FinalRunStatuses)
 }
 
-// UpdateReportFile updates the report file URL for a given test ID.
-func (uc *QueueAutomationUseCase) UpdateReportFile(idTest string, reportFileURL string) error {
-	return db.UpdateQueueAutomationReportFile(idTest, reportFileURL)
+// UpdateReportFile updates the report file object name for a given test ID.
+func (uc *QueueAutomationUseCase) UpdateReportFile(ctx context.Context, idTest string, reportFile string) error {
+	return uc.repo.UpdateReportFile(ctx, idTest, reportFile)
 }
@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"service-test-runner/internal/db/dbtest"
+	"service-test-runner/internal/domain"
+	automationRepo "service-test-runner/internal/repository/automation"
+)
+
+func TestConcurrentFinalUpdatesFinishOnce(t *testing.T) {
+	ctx := context.Background()
+	runs := automationRepo.NewQueueAutomationRepository(dbtest.Open(t))
+	events := NewRunEvents()
+	var mu sync.Mutex
+	var finished []domain.RunEvent
+	events.Subscribe(func(event domain.RunEvent) {
+		if event.Type == domain.RunEventFinished {
+			mu.Lock()
+			finished = append(finished, event)
+			mu.Unlock()
+		}
+	})
+	uc := NewQueueAutomationUseCase(runs, nil, events)
+
+	run := domain.Run{ReferenceNumber: "ref-1", IdTest: "run-1", Testsuite: "suite1", Project: "web1", Priority: domain.RunPriorityNormal, Status: domain.RunStatusTriggered, TotalSteps: 2}
+	if err := runs.Create(ctx, &run); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for _, status := range []int{domain.RunStatusPassed, domain.RunStatusFailed, domain.RunStatusFailed} {
+		wg.Add(1)
+		go func(status int) {
+			defer wg.Done()
+			if err := uc.UpdateStatus(ctx, "run-1", "checkout", status, "ref-1"); err != nil {
+				t.Error(err)
+			}
+		}(status)
+	}
+	wg.Wait()
+	if err := events.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(finished) != 1 {
+		t.Fatalf("finished events = %+v, want one", finished)
+	}
+
+	// A requeued run finishes again.
+	if err := runs.UpdateStatusByReferenceNumber(ctx, "run-1", "ref-1", domain.RunStatusTriggered); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := finishRun(ctx, runs, "ref-1", domain.RunStatusPassed); err != nil || !ok {
+		t.Fatalf("finishRun after requeue = %v, %v; want true", ok, err)
+	}
+}
+
+func TestClaimStartsAQueuedRunOnce(t *testing.T) {
+	ctx := context.Background()
+	runs := automationRepo.NewQueueAutomationRepository(dbtest.Open(t))
+	events := NewRunEvents()
+	var mu sync.Mutex
+	var types []string
+	events.Subscribe(func(event domain.RunEvent) {
+		mu.Lock()
+		types = append(types, event.Type)
+		mu.Unlock()
+	})
+	uc := NewQueueAutomationUseCase(runs, nil, events)
+
+	run := domain.Run{ReferenceNumber: "ref-1", Testsuite: "suite1", Project: "web1", Priority: domain.RunPriorityNormal, Status: domain.RunStatusQueued}
+	if err := runs.Create(ctx, &run); err != nil {
+		t.Fatal(err)
+	}
+
+	if claimed, err := uc.Claim(ctx, &run); err != nil || !claimed {
+		t.Fatalf("Claim = %v, %v; want true", claimed, err)
+	}
+	if claimed, err := uc.Claim(ctx, &run); err != nil || claimed {
+		t.Fatalf("second Claim = %v, %v; want false", claimed, err)
+	}
+	if err := uc.Unclaim(ctx, &run); err != nil {
+		t.Fatal(err)
+	}
+	if claimed, err := uc.Claim(ctx, &run); err != nil || !claimed {
+		t.Fatalf("Claim after Unclaim = %v, %v; want true", claimed, err)
+	}
+	if started, err := uc.Start(ctx, &run, "run-1", "http://node-1"); err != nil || !started {
+		t.Fatalf("Start = %v, %v; want true", started, err)
+	}
+	if err := events.Close(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := runs.GetByReferenceNumber(ctx, "ref-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != domain.RunStatusTriggered || stored.IdTest != "run-1" || stored.RunnerURL != "http://node-1" {
+		t.Fatalf("started run = %+v", stored)
+	}
+	if len(types) != 1 || types[0] != domain.RunEventStarted {
+		t.Fatalf("events = %v, want one run.started", types)
+	}
+}
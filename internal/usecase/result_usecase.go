@@ -0,0 +1,237 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"service-test-runner/internal/domain"
+	"service-test-runner/internal/infrastructure/report"
+	automationRepo "service-test-runner/internal/repository/automation"
+	resultRepo "service-test-runner/internal/repository/result"
+)
+
+// ResultUsecase handles ingestion and retrieval of structured test results.
+type ResultUsecase struct {
+	repo      resultRepo.ResultRepository
+	queueRepo automationRepo.QueueAutomationRepository
+	artifacts *ArtifactUsecase
+	events    runEmitter
+}
+
+// NewResultUsecase creates a new ResultUsecase with its dependencies injected.
+func NewResultUsecase(repo resultRepo.ResultRepository, queueRepo automationRepo.QueueAutomationRepository, artifacts *ArtifactUsecase, events runEmitter) *ResultUsecase {
+	return &ResultUsecase{
+		repo:      repo,
+		queueRepo: queueRepo,
+		artifacts: artifacts,
+		events:    events,
+	}
+}
+
+// IngestJUnit parses one or more JUnit/xUnit XML reports, replaces the stored results
+// of the run with them and derives the run's final status from the outcome.
+func (uc *ResultUsecase) IngestJUnit(ctx context.Context, referenceNumber string, reports ...io.Reader) (domain.RunResults, error) {
+	record, err := uc.queueRepo.GetByReferenceNumber(ctx, referenceNumber)
+	if err != nil {
+		return domain.RunResults{}, err
+	}
+	if record == nil {
+		return domain.RunResults{}, errors.New("record not found")
+	}
+
+	var suites []domain.TestSuiteResult
+	for _, r := range reports {
+		parsed, err := report.ParseJUnit(r)
+		if err != nil {
+			return domain.RunResults{}, err
+		}
+		suites = append(suites, parsed...)
+	}
+
+	// A run with a final report has finished all of its steps.
+	return uc.saveResults(ctx, record, suites, record.StepName, record.TotalSteps)
+}
+
+// embeddedScreenshotPrefix names the screenshots taken from the embeddings of a
+// Cucumber report, which tells them apart from the ones uploaded by the runner.
+const embeddedScreenshotPrefix = "embedded-screenshot-"
+
+// IngestCucumber parses a Cucumber JSON report into per-feature, per-scenario and
+// per-step results, stores embedded screenshots as artifacts and updates the run's
+// progress and final status from the executed steps. Ingesting a report again
+// replaces the results and embedded screenshots of the previous one; when it fails
+// part way, the earlier ones are kept.
+func (uc *ResultUsecase) IngestCucumber(ctx context.Context, referenceNumber string, r io.Reader) (domain.RunResults, error) {
+	record, err := uc.queueRepo.GetByReferenceNumber(ctx, referenceNumber)
+	if err != nil {
+		return domain.RunResults{}, err
+	}
+	if record == nil {
+		return domain.RunResults{}, errors.New("record not found")
+	}
+
+	suites, embeddings, err := report.ParseCucumber(r)
+	if err != nil {
+		return domain.RunResults{}, err
+	}
+
+	// The executed steps stand in for the update-status calls of a step-by-step runner.
+	stepName, checkpoint := record.StepName, 0
+	for _, suite := range suites {
+		for _, tc := range suite.Cases {
+			for _, step := range tc.Steps {
+				if step.Status == "skipped" {
+					continue
+				}
+				stepName = step.Name
+				checkpoint++
+			}
+		}
+	}
+	if checkpoint > record.TotalSteps {
+		checkpoint = record.TotalSteps
+	}
+
+	// The screenshots of the earlier report are only deleted once the new ones are
+	// stored, and the results replaced last, so a failure leaves the earlier ones whole.
+	previous, err := uc.embeddedScreenshots(ctx, referenceNumber)
+	if err != nil {
+		return domain.RunResults{}, err
+	}
+	if err := uc.storeScreenshots(ctx, record, embeddings); err != nil {
+		return domain.RunResults{}, err
+	}
+	for _, artifact := range previous {
+		if err := uc.artifacts.Delete(ctx, artifact); err != nil {
+			return domain.RunResults{}, err
+		}
+	}
+	return uc.saveResults(ctx, record, suites, stepName, checkpoint)
+}
+
+// embeddedScreenshots returns the screenshots stored from the embeddings of the
+// Cucumber reports of a run.
+func (uc *ResultUsecase) embeddedScreenshots(ctx context.Context, referenceNumber string) ([]domain.Artifact, error) {
+	artifacts, err := uc.artifacts.ListByReferenceNumber(ctx, referenceNumber)
+	if err != nil {
+		return nil, err
+	}
+	screenshots := make([]domain.Artifact, 0, len(artifacts))
+	for _, artifact := range artifacts {
+		if artifact.Type == domain.ArtifactTypeScreenshot && strings.HasPrefix(artifact.Name, embeddedScreenshotPrefix) {
+			screenshots = append(screenshots, artifact)
+		}
+	}
+	return screenshots, nil
+}
+
+// storeScreenshots stores the image embeddings of a report. When one fails, the ones
+// stored before it are deleted again.
+func (uc *ResultUsecase) storeScreenshots(ctx context.Context, record *domain.Run, embeddings []report.Embedding) error {
+	var stored []domain.Artifact
+	for i, embedding := range embeddings {
+		if !strings.HasPrefix(embedding.MimeType, "image/") {
+			continue
+		}
+		ext := "." + strings.TrimPrefix(embedding.MimeType, "image/")
+		if ext == ".jpeg" {
+			ext = ".jpg"
+		}
+		artifact, err := uc.artifacts.Store(ctx, ArtifactUpload{
+			ReferenceNumber: record.ReferenceNumber,
+			IdTest:          record.IdTest,
+			StepName:        embedding.Step,
+			Filename:        fmt.Sprintf("%s%d%s", embeddedScreenshotPrefix, i+1, ext),
+			Reader:          bytes.NewReader(embedding.Data),
+			Size:            int64(len(embedding.Data)),
+		})
+		if err != nil {
+			for _, artifact := range stored {
+				// Even when the client went away, as the report is not ingested.
+				uc.artifacts.Delete(context.WithoutCancel(ctx), artifact)
+			}
+			return err
+		}
+		stored = append(stored, artifact)
+	}
+	return nil
+}
+
+// saveResults replaces the stored results of a run and finishes it.
+func (uc *ResultUsecase) saveResults(ctx context.Context, record *domain.Run, suites []domain.TestSuiteResult, stepName string, checkpoint int) (domain.RunResults, error) {
+	if err := uc.repo.Replace(ctx, record.ReferenceNumber, suites); err != nil {
+		return domain.RunResults{}, err
+	}
+	return uc.finish(ctx, record, suites, stepName, checkpoint)
+}
+
+// finish summarizes the results of a run and, when the report contains any test,
+// updates the run with its final status, last step and checkpoint.
+func (uc *ResultUsecase) finish(ctx context.Context, record *domain.Run, suites []domain.TestSuiteResult, stepName string, checkpoint int) (domain.RunResults, error) {
+	results := summarizeResults(record.ReferenceNumber, suites)
+	results.Status = record.Status
+	if results.Tests > 0 {
+		results.Status = domain.RunStatusPassed
+		if results.Failures+results.Errors > 0 {
+			results.Status = domain.RunStatusFailed
+		}
+		// Results ingested after the runner reported the run finished only correct it.
+		finished, err := finishRun(ctx, uc.queueRepo, record.ReferenceNumber, results.Status)
+		if err != nil {
+			return domain.RunResults{}, err
+		}
+		if err := uc.queueRepo.UpdateStatus(ctx, record.IdTest, stepName, checkpoint, results.Status, record.ReferenceNumber); err != nil {
+			return domain.RunResults{}, err
+		}
+		if finished {
+			uc.events.finished(record, results.Status, stepName, checkpoint)
+		}
+	}
+	return results, nil
+}
+
+// GetResults returns the stored results of a run grouped by suite.
+func (uc *ResultUsecase) GetResults(ctx context.Context, referenceNumber string) (domain.RunResults, error) {
+	record, err := uc.queueRepo.GetByReferenceNumber(ctx, referenceNumber)
+	if err != nil {
+		return domain.RunResults{}, err
+	}
+	if record == nil {
+		return domain.RunResults{}, errors.New("record not found")
+	}
+	suites, err := uc.repo.GetByReferenceNumber(ctx, referenceNumber)
+	if err != nil {
+		return domain.RunResults{}, err
+	}
+	for i := range suites {
+		suites[i] = report.CountSuite(suites[i])
+	}
+
+	results := summarizeResults(referenceNumber, suites)
+	results.Status = record.Status
+	return results, nil
+}
+
+// summarizeResults aggregates the counts and durations of all suites of a run.
+func summarizeResults(referenceNumber string, suites []domain.TestSuiteResult) domain.RunResults {
+	results := domain.RunResults{
+		ReferenceNumber: referenceNumber,
+		Suites:          suites,
+	}
+	if results.Suites == nil {
+		results.Suites = []domain.TestSuiteResult{}
+	}
+	for _, suite := range suites {
+		results.Tests += suite.Tests
+		results.Failures += suite.Failures
+		results.Errors += suite.Errors
+		results.Skipped += suite.Skipped
+		results.Duration += suite.Duration
+	}
+	results.Passed = results.Tests - results.Failures - results.Errors - results.Skipped
+	return results
+}
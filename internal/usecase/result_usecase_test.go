@@ -0,0 +1,101 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"service-test-runner/internal/db/dbtest"
+	"service-test-runner/internal/domain"
+	artifactRepo "service-test-runner/internal/repository/artifact"
+	automationRepo "service-test-runner/internal/repository/automation"
+	resultRepo "service-test-runner/internal/repository/result"
+)
+
+// cucumberJSON is a report of one passed step with two PNG screenshots embedded.
+const cucumberJSON = `[{"name": "Login", "elements": [{"name": "valid user", "type": "scenario", "steps": [
+	{"keyword": "Given ", "name": "the login page", "result": {"status": "passed", "duration": 1000},
+	 "embeddings": [{"mime_type": "image/png", "data": "iVBORw0KGgo="}, {"mime_type": "image/png", "data": "iVBORw0KGgoA"}]}
+]}]}]`
+
+func TestIngestCucumberReplacesEmbeddedScreenshots(t *testing.T) {
+	ctx := context.Background()
+	gdb := dbtest.Open(t)
+	runs := automationRepo.NewQueueAutomationRepository(gdb)
+	store := newFakeStore()
+	artifacts := NewArtifactUsecase(artifactRepo.NewArtifactRepository(gdb), store, nil)
+	uc := NewResultUsecase(resultRepo.NewResultRepository(gdb), runs, artifacts, NewRunEvents())
+
+	run := domain.Run{ReferenceNumber: "ref-1", Testsuite: "suite1", Project: "web1", Priority: domain.RunPriorityNormal, Status: domain.RunStatusTriggered, TotalSteps: 1}
+	if err := runs.Create(ctx, &run); err != nil {
+		t.Fatal(err)
+	}
+	uploaded, err := artifacts.Store(ctx, ArtifactUpload{
+		ReferenceNumber: "ref-1",
+		StepName:        "the login page",
+		Filename:        "login.png",
+		Reader:          strings.NewReader("\x89PNG\r\n\x1a\n"),
+		Size:            -1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := uc.IngestCucumber(ctx, "ref-1", strings.NewReader(`[{"name": `)); err == nil {
+		t.Fatal("IngestCucumber accepted a malformed report")
+	}
+	if stored, _ := artifacts.ListByReferenceNumber(ctx, "ref-1"); len(stored) != 1 {
+		t.Fatalf("artifacts after a malformed report = %+v", stored)
+	}
+
+	for i := 0; i < 2; i++ {
+		results, err := uc.IngestCucumber(ctx, "ref-1", strings.NewReader(cucumberJSON))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if results.Status != domain.RunStatusPassed {
+			t.Fatalf("status = %d, want passed", results.Status)
+		}
+	}
+
+	stored, err := artifacts.ListByReferenceNumber(ctx, "ref-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != 3 || stored[0].ID != uploaded.ID {
+		t.Fatalf("artifacts after two ingestions = %+v", stored)
+	}
+	for _, artifact := range stored[1:] {
+		if !strings.HasPrefix(artifact.Name, embeddedScreenshotPrefix) || artifact.StepName != "the login page" {
+			t.Errorf("embedded screenshot = %+v", artifact)
+		}
+	}
+	if objects, _ := store.List(ctx, "reports/ref-1/"); len(objects) != 3 {
+		t.Errorf("objects after two ingestions = %v", objects)
+	}
+
+	// A report whose screenshots cannot all be stored leaves the earlier ones and the
+	// results alone.
+	store.failPut = embeddedScreenshotPrefix + "2.png"
+	failing := strings.Replace(cucumberJSON, `"status": "passed"`, `"status": "failed"`, 1)
+	if _, err := uc.IngestCucumber(ctx, "ref-1", strings.NewReader(failing)); err == nil {
+		t.Fatal("IngestCucumber succeeded with a screenshot refused by the storage")
+	}
+	after, err := artifacts.ListByReferenceNumber(ctx, "ref-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(after) != 3 || after[1].ID != stored[1].ID || after[2].ID != stored[2].ID {
+		t.Fatalf("artifacts after a failed ingestion = %+v", after)
+	}
+	if objects, _ := store.List(ctx, "reports/ref-1/"); len(objects) != 3 {
+		t.Errorf("objects after a failed ingestion = %v", objects)
+	}
+	results, err := uc.GetResults(ctx, "ref-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results.Failures != 0 || results.Passed != 1 {
+		t.Fatalf("results after a failed ingestion = %+v", results)
+	}
+}
@@ -0,0 +1,259 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"service-test-runner/internal/config"
+	"service-test-runner/internal/domain"
+	"service-test-runner/internal/infrastructure/logging"
+	"service-test-runner/internal/infrastructure/storage"
+	artifactRepo "service-test-runner/internal/repository/artifact"
+	automationRepo "service-test-runner/internal/repository/automation"
+	"service-test-runner/internal/repository/project"
+	retentionRepo "service-test-runner/internal/repository/retention"
+)
+
+// RetentionUsecase applies per-project retention policies to runs and their stored artifacts.
+type RetentionUsecase struct {
+	repo         retentionRepo.RetentionRepository
+	queueRepo    automationRepo.QueueAutomationRepository
+	artifactRepo artifactRepo.ArtifactRepository
+	projectRepo  project.ProjectRepository
+	store        storage.ArtifactStore
+	defaults     config.RetentionConfig
+}
+
+// NewRetentionUsecase creates a new RetentionUsecase with its dependencies injected.
+func NewRetentionUsecase(
+	repo retentionRepo.RetentionRepository,
+	queueRepo automationRepo.QueueAutomationRepository,
+	artifactRepo artifactRepo.ArtifactRepository,
+	projectRepo project.ProjectRepository,
+	store storage.ArtifactStore,
+	defaults config.RetentionConfig,
+) *RetentionUsecase {
+	return &RetentionUsecase{
+		repo:         repo,
+		queueRepo:    queueRepo,
+		artifactRepo: artifactRepo,
+		projectRepo:  projectRepo,
+		store:        store,
+		defaults:     defaults,
+	}
+}
+
+// GetPolicy returns the policy of a project, falling back to the configured default.
+func (uc *RetentionUsecase) GetPolicy(ctx context.Context, projectName string) (domain.RetentionPolicy, error) {
+	policy, err := uc.repo.GetByProject(ctx, projectName)
+	if err != nil {
+		return domain.RetentionPolicy{}, err
+	}
+	if policy == nil {
+		return domain.RetentionPolicy{
+			Project:          projectName,
+			KeepLastRuns:     uc.defaults.KeepLastRuns,
+			MaxAgeDays:       uc.defaults.MaxAgeDays,
+			FailedMaxAgeDays: uc.defaults.FailedMaxAgeDays,
+		}, nil
+	}
+	return *policy, nil
+}
+
+// SavePolicy stores the policy of a project.
+func (uc *RetentionUsecase) SavePolicy(ctx context.Context, policy domain.RetentionPolicy) error {
+	if policy.KeepLastRuns < 0 || policy.MaxAgeDays < 0 || policy.FailedMaxAgeDays < 0 {
+		return errors.New("retention limits must not be negative")
+	}
+	return uc.repo.Save(ctx, &policy)
+}
+
+// SetPinned pins a run so retention never removes it, or unpins it.
+func (uc *RetentionUsecase) SetPinned(ctx context.Context, referenceNumber string, pinned bool) error {
+	record, err := uc.queueRepo.GetByReferenceNumber(ctx, referenceNumber)
+	if err != nil {
+		return err
+	}
+	if record == nil {
+		return errors.New("record not found")
+	}
+	return uc.queueRepo.SetPinned(ctx, referenceNumber, pinned)
+}
+
+// Plan returns the runs the retention policies would archive, for one project or,
+// when projectName is empty, for every project.
+func (uc *RetentionUsecase) Plan(ctx context.Context, projectName string) ([]domain.RetentionCandidate, error) {
+	projects := []string{projectName}
+	if projectName == "" {
+		all, err := uc.projectRepo.ShowProject()
+		if err != nil {
+			return nil, err
+		}
+		projects = projects[:0]
+		for _, p := range all {
+			projects = append(projects, p.Name)
+		}
+	}
+
+	candidates := []domain.RetentionCandidate{}
+	now := time.Now()
+	for _, name := range projects {
+		policy, err := uc.GetPolicy(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		runs, err := uc.queueRepo.GetRetainable(ctx, name, domain.FinalRunStatuses)
+		if err != nil {
+			return nil, err
+		}
+
+		// Runs arrive grouped by suite, newest first, so position counts runs kept so far.
+		position := 0
+		for i, run := range runs {
+			if i > 0 && runs[i-1].Testsuite != run.Testsuite {
+				position = 0
+			}
+			position++
+
+			reason := retentionReason(policy, run, position, now)
+			if reason == "" {
+				continue
+			}
+			objects, err := uc.runObjects(ctx, run)
+			if err != nil {
+				return nil, err
+			}
+			candidates = append(candidates, domain.RetentionCandidate{
+				ReferenceNumber: run.ReferenceNumber,
+				Project:         run.Project,
+				Testsuite:       run.Testsuite,
+				Status:          run.Status,
+				CreatedAt:       run.CreatedAt,
+				Reason:          reason,
+				Objects:         objects,
+			})
+		}
+	}
+	return candidates, nil
+}
+
+// Apply archives every run selected by Plan: their storage objects and artifact
+// rows are deleted and the runs are marked as archived. A run that fails to archive
+// is logged and skipped; the runs archived are returned.
+func (uc *RetentionUsecase) Apply(ctx context.Context) ([]domain.RetentionCandidate, error) {
+	candidates, err := uc.Plan(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	archived := make([]domain.RetentionCandidate, 0, len(candidates))
+	for _, candidate := range candidates {
+		ctx := logging.With(ctx, "reference_number", candidate.ReferenceNumber, "project", candidate.Project)
+		if err := uc.archive(ctx, candidate); err != nil {
+			logging.FromContext(ctx).Error("Error archiving run", "error", err)
+			continue
+		}
+		archived = append(archived, candidate)
+	}
+	return archived, nil
+}
+
+// archive deletes the storage objects and artifact rows of a run and marks it as
+// archived. A run whose objects could not all be deleted is left as it is, so the
+// next pass tries again.
+func (uc *RetentionUsecase) archive(ctx context.Context, candidate domain.RetentionCandidate) error {
+	for _, object := range candidate.Objects {
+		if err := uc.store.Delete(ctx, object); err != nil {
+			return fmt.Errorf("failed to delete %s: %v", object, err)
+		}
+	}
+	if err := uc.artifactRepo.DeleteByReferenceNumber(ctx, candidate.ReferenceNumber); err != nil {
+		return err
+	}
+	return uc.queueRepo.Archive(ctx, candidate.ReferenceNumber)
+}
+
+// RunJanitor applies the retention policies every interval until ctx is cancelled.
+// A pass in progress is cut short, as the next one picks up where it stopped.
+func (uc *RetentionUsecase) RunJanitor(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+		archived, err := uc.Apply(ctx)
+		if err != nil {
+			logging.FromContext(ctx).Error("Retention janitor failed", "error", err)
+			continue
+		}
+		if len(archived) > 0 {
+			logging.FromContext(ctx).Info("Retention janitor archived runs", "runs", len(archived))
+		}
+	}
+}
+
+// retentionReason returns why the policy removes a run, or an empty string to keep it.
+// The run is the position-th newest of its suite.
+func retentionReason(policy domain.RetentionPolicy, run domain.Run, position int, now time.Time) string {
+	age := now.Sub(run.CreatedAt)
+	failed := run.Status == domain.RunStatusFailed
+
+	maxAgeDays := policy.MaxAgeDays
+	if failed && policy.FailedMaxAgeDays > 0 {
+		maxAgeDays = policy.FailedMaxAgeDays
+	}
+	if maxAgeDays > 0 && age > time.Duration(maxAgeDays)*24*time.Hour {
+		return fmt.Sprintf("older than %d days", maxAgeDays)
+	}
+
+	// Failures younger than their own limit survive the keep-last rule.
+	if policy.KeepLastRuns > 0 && position > policy.KeepLastRuns {
+		if failed && policy.FailedMaxAgeDays > 0 {
+			return ""
+		}
+		return fmt.Sprintf("beyond the last %d runs of the suite", policy.KeepLastRuns)
+	}
+	return ""
+}
+
+// runObjects lists the storage objects belonging to a run: its indexed artifacts,
+// its report file and anything else stored under its reports/ prefix.
+func (uc *RetentionUsecase) runObjects(ctx context.Context, run domain.Run) ([]string, error) {
+	seen := make(map[string]bool)
+	objects := []string{}
+	add := func(name string) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			objects = append(objects, name)
+		}
+	}
+
+	artifacts, err := uc.artifactRepo.GetByReferenceNumber(ctx, run.ReferenceNumber)
+	if err != nil {
+		return nil, err
+	}
+	for _, artifact := range artifacts {
+		add(artifact.ObjectName)
+	}
+	if name, ok := reportObjectName(run.ReportFile); ok {
+		add(name)
+	}
+	prefixes := []string{fmt.Sprintf("reports/%s/", run.ReferenceNumber)}
+	if run.IdTest != "" {
+		prefixes = append(prefixes, fmt.Sprintf("reports/%s/", run.IdTest))
+	}
+	for _, prefix := range prefixes {
+		stored, err := uc.store.List(ctx, prefix)
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range stored {
+			add(object.Name)
+		}
+	}
+	return objects, nil
+}
@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"service-test-runner/internal/config"
+	"service-test-runner/internal/db/dbtest"
+	"service-test-runner/internal/domain"
+	artifactRepo "service-test-runner/internal/repository/artifact"
+	automationRepo "service-test-runner/internal/repository/automation"
+	"service-test-runner/internal/repository/project"
+	retentionRepo "service-test-runner/internal/repository/retention"
+)
+
+func TestRetentionArchivesOnlyFinishedRuns(t *testing.T) {
+	ctx := context.Background()
+	gdb := dbtest.Open(t)
+	runs := automationRepo.NewQueueAutomationRepository(gdb)
+	projects := project.NewProjectRepository(gdb)
+	if _, err := projects.LoadProjects(ctx); err != nil {
+		t.Fatal(err)
+	}
+	store := newFakeStore()
+	uc := NewRetentionUsecase(
+		retentionRepo.NewRetentionRepository(gdb),
+		runs,
+		artifactRepo.NewArtifactRepository(gdb),
+		projects,
+		store,
+		config.RetentionConfig{KeepLastRuns: 1},
+	)
+
+	// Every run but the newest of the suite is beyond the last run kept.
+	for _, run := range []domain.Run{
+		{ReferenceNumber: "passed", Status: domain.RunStatusPassed},
+		{ReferenceNumber: "failed", Status: domain.RunStatusFailed},
+		{ReferenceNumber: "undeletable", Status: domain.RunStatusPassed},
+		{ReferenceNumber: "in-progress", Status: 5},
+		{ReferenceNumber: "unknown", Status: 0},
+		{ReferenceNumber: "triggered", Status: domain.RunStatusTriggered},
+		{ReferenceNumber: "newest", Status: domain.RunStatusPassed},
+	} {
+		run.Testsuite, run.Project, run.Priority = "suite1", "web1", domain.RunPriorityNormal
+		if err := runs.Create(ctx, &run); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Put(ctx, "reports/"+run.ReferenceNumber+"/report.pdf", strings.NewReader("%PDF"), 4, "application/pdf"); err != nil {
+			t.Fatal(err)
+		}
+		// Rows created in the same instant would tie on created_at.
+		createdAt := time.Now().Add(time.Duration(run.ID) * time.Minute)
+		if err := gdb.Exec("UPDATE tbl_queue_automations SET created_at = ? WHERE reference_number = ?", createdAt, run.ReferenceNumber).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	store.failDelete["reports/undeletable/report.pdf"] = true
+
+	archived, err := uc.Apply(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]bool{}
+	for _, c := range archived {
+		got[c.ReferenceNumber] = true
+	}
+	if len(got) != 2 || !got["passed"] || !got["failed"] {
+		t.Fatalf("archived %v, want passed and failed", got)
+	}
+	for _, ref := range []string{"undeletable", "in-progress", "unknown", "triggered", "newest"} {
+		if !store.has("reports/" + ref + "/report.pdf") {
+			t.Errorf("the report of %s was deleted", ref)
+		}
+		run, err := runs.GetByReferenceNumber(ctx, ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if run.ArchivedAt != nil {
+			t.Errorf("%s was archived", ref)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"service-test-runner/internal/domain"
+)
+
+// runEventBuffer is the number of events a listener may fall behind before emitting blocks.
+const runEventBuffer = 256
+
+// RunListener is notified of run lifecycle events. As the request that emitted an event
+// may be over by then, listeners do their work under context.Background().
+type RunListener func(event domain.RunEvent)
+
+// RunEvents dispatches run events to the registered listeners. Each listener receives
+// the events in order on its own goroutine, so a slow notification channel never
+// delays a status update.
+type RunEvents struct {
+	mu        sync.RWMutex
+	listeners []chan domain.RunEvent
+	closed    bool
+	running   sync.WaitGroup
+}
+
+// runEmitter emits the events of the runs a usecase changes, see RunEvents.
+type runEmitter interface {
+	created(record *domain.Run)
+	statusChanged(record *domain.Run, status int, stepName string, checkpoint int)
+	finished(record *domain.Run, status int, stepName string, checkpoint int)
+}
+
+// NewRunEvents creates a new RunEvents dispatcher without listeners.
+func NewRunEvents() *RunEvents {
+	return &RunEvents{}
+}
+
+// Subscribe registers a listener for run events.
+func (e *RunEvents) Subscribe(listener RunListener) {
+	events := make(chan domain.RunEvent, runEventBuffer)
+	e.running.Add(1)
+	go func() {
+		defer e.running.Done()
+		for event := range events {
+			callListener(listener, event)
+		}
+	}()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.listeners = append(e.listeners, events)
+}
+
+// created emits the event of a newly created run.
+func (e *RunEvents) created(record *domain.Run) {
+	eventType := domain.RunEventStarted
+	if record.Status == domain.RunStatusQueued {
+		eventType = domain.RunEventQueued
+	}
+	e.emit(eventType, record, record.Status, record.StepName, record.Checkpoint)
+}
+
+// statusChanged emits the event matching a status update of a run. record holds the
+// run as it was before the update. A final status emits nothing here, see finished.
+func (e *RunEvents) statusChanged(record *domain.Run, status int, stepName string, checkpoint int) {
+	var eventType string
+	switch {
+	case domain.IsFinalStatus(status):
+		return
+	case status == domain.RunStatusQueued:
+		if record.Status == domain.RunStatusQueued {
+			return
+		}
+		eventType = domain.RunEventQueued
+	case status == domain.RunStatusTriggered && record.Status != domain.RunStatusTriggered:
+		eventType = domain.RunEventStarted
+	default:
+		eventType = domain.RunEventProgress
+	}
+	e.emit(eventType, record, status, stepName, checkpoint)
+}
+
+// finished emits the finished event of a run. It is only called by the update that
+// moved the run into its final status, so a run finishes once however many updates
+// race to finish it.
+func (e *RunEvents) finished(record *domain.Run, status int, stepName string, checkpoint int) {
+	e.emit(domain.RunEventFinished, record, status, stepName, checkpoint)
+}
+
+func (e *RunEvents) emit(eventType string, record *domain.Run, status int, stepName string, checkpoint int) {
+	if e == nil {
+		return
+	}
+	event := domain.RunEvent{
+		Type:            eventType,
+		ReferenceNumber: record.ReferenceNumber,
+		Project:         record.Project,
+		Testsuite:       record.Testsuite,
+		IdTest:          record.IdTest,
+		Status:          status,
+		StepName:        stepName,
+		Checkpoint:      checkpoint,
+		TotalSteps:      record.TotalSteps,
+		Email:           record.Email,
+		StartedAt:       record.CreatedAt,
+		OccurredAt:      time.Now(),
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closed {
+		slog.Warn("Run event emitted after shutdown", "event", eventType, "reference_number", record.ReferenceNumber)
+		return
+	}
+	for _, events := range e.listeners {
+		events <- event
+	}
+}
+
+// Close stops accepting events and waits until the listeners have handled the ones
+// already emitted, or until ctx is done.
+func (e *RunEvents) Close(ctx context.Context) error {
+	e.mu.Lock()
+	if !e.closed {
+		e.closed = true
+		for _, events := range e.listeners {
+			close(events)
+		}
+	}
+	e.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		e.running.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// callListener runs a listener, keeping a panicking listener from stopping its goroutine.
+func callListener(listener RunListener, event domain.RunEvent) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("Run listener panicked", "event", event.Type, "reference_number", event.ReferenceNumber, "panic", r)
+		}
+	}()
+	listener(event)
+}
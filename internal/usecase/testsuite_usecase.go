@@ -1,22 +1,24 @@
 package usecase
 
 import (
+	"context"
+
 	"service-test-runner/internal/domain"
 	"service-test-runner/internal/repository/selenium"
 )
 
 type TestSuiteUsecase struct {
-	repo *selenium.SeleniumRepository
+	repo selenium.SeleniumRepository
 }
 
-func NewTestSuiteUsecase(repo *selenium.SeleniumRepository) *TestSuiteUsecase {
+func NewTestSuiteUsecase(repo selenium.SeleniumRepository) *TestSuiteUsecase {
 	return &TestSuiteUsecase{repo: repo}
 }
 
-func (t *TestSuiteUsecase) GetAll(project string) ([]string, error) {
-	return t.repo.GetTestSuites(project)
+func (t *TestSuiteUsecase) GetAll(ctx context.Context, project string) ([]string, error) {
+	return t.repo.GetTestSuites(ctx, project)
 }
 
-func (t *TestSuiteUsecase) GetDetail(project, testsuiteName string) (domain.TestSuiteDetail, error) {
-	return t.repo.GetTestSuiteDetail(project, testsuiteName)
+func (t *TestSuiteUsecase) GetDetail(ctx context.Context, project, testsuiteName string) (domain.TestSuiteDetail, error) {
+	return t.repo.GetTestSuiteDetail(ctx, project, testsuiteName)
 }
@@ -0,0 +1,309 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"service-test-runner/internal/config"
+	"service-test-runner/internal/domain"
+	"service-test-runner/internal/infrastructure/logging"
+	automationRepo "service-test-runner/internal/repository/automation"
+)
+
+var (
+	// ErrUploadNotFound is returned for unknown, completed or expired upload sessions.
+	ErrUploadNotFound = errors.New("upload not found")
+	// ErrInvalidUpload is returned when a chunked upload request is malformed or incomplete.
+	ErrInvalidUpload = errors.New("invalid upload")
+
+	uploadIDPattern = regexp.MustCompile(`^[0-9a-f]{32}$`)
+)
+
+const (
+	uploadSessionFile = "session.json"
+	uploadChunkPrefix = "chunk-"
+)
+
+// UploadUsecase handles chunked, resumable artifact uploads. Chunks are staged on
+// local disk and streamed into artifact storage in order once the upload is completed.
+type UploadUsecase struct {
+	artifacts    *ArtifactUsecase
+	queueRepo    automationRepo.QueueAutomationRepository
+	dir          string
+	maxSizes     map[string]int64
+	maxChunkSize int64
+	expiry       time.Duration
+
+	// mu serializes the chunks being added, so that the ones of an upload received at
+	// the same time are all counted against its size.
+	mu sync.Mutex
+}
+
+// NewUploadUsecase creates a new UploadUsecase with its dependencies injected.
+func NewUploadUsecase(artifacts *ArtifactUsecase, queueRepo automationRepo.QueueAutomationRepository, cfg config.ArtifactConfig) (*UploadUsecase, error) {
+	if err := os.MkdirAll(cfg.UploadDir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create upload directory: %v", err)
+	}
+	return &UploadUsecase{
+		artifacts:    artifacts,
+		queueRepo:    queueRepo,
+		dir:          cfg.UploadDir,
+		maxSizes:     cfg.MaxSizes,
+		maxChunkSize: cfg.MaxChunkSize,
+		expiry:       cfg.UploadExpiry,
+	}, nil
+}
+
+// Create starts a new upload session for a run. A declared size above the size limit
+// of the upload is rejected right away.
+func (uc *UploadUsecase) Create(ctx context.Context, session domain.UploadSession) (domain.UploadSession, error) {
+	if session.ReferenceNumber == "" || session.Filename == "" {
+		return domain.UploadSession{}, fmt.Errorf("%w: reference_number and filename are required", ErrInvalidUpload)
+	}
+	record, err := uc.queueRepo.GetByReferenceNumber(ctx, session.ReferenceNumber)
+	if err != nil || record == nil {
+		return domain.UploadSession{}, errors.New("record not found")
+	}
+	if limit := uc.sizeLimit(session.Filename); limit >= 0 && session.Size > limit {
+		return domain.UploadSession{}, fmt.Errorf("%w: upload exceeds the %d bytes limit", ErrArtifactTooLarge, limit)
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return domain.UploadSession{}, err
+	}
+	session.UploadID = hex.EncodeToString(id)
+	session.Filename = filepath.Base(session.Filename)
+	session.ReceivedChunks = []int{}
+	session.ReceivedBytes = 0
+	session.CreatedAt = time.Now()
+	if session.Size == 0 {
+		session.Size = -1
+	}
+
+	if err := os.Mkdir(uc.sessionDir(session.UploadID), 0o755); err != nil {
+		return domain.UploadSession{}, err
+	}
+	data, err := json.Marshal(session)
+	if err != nil {
+		return domain.UploadSession{}, err
+	}
+	if err := os.WriteFile(filepath.Join(uc.sessionDir(session.UploadID), uploadSessionFile), data, 0o644); err != nil {
+		return domain.UploadSession{}, err
+	}
+	return session, nil
+}
+
+// Get returns an upload session with the chunks received so far.
+func (uc *UploadUsecase) Get(uploadID string) (domain.UploadSession, error) {
+	if !uploadIDPattern.MatchString(uploadID) {
+		return domain.UploadSession{}, ErrUploadNotFound
+	}
+	data, err := os.ReadFile(filepath.Join(uc.sessionDir(uploadID), uploadSessionFile))
+	if err != nil {
+		return domain.UploadSession{}, ErrUploadNotFound
+	}
+	var session domain.UploadSession
+	if err := json.Unmarshal(data, &session); err != nil {
+		return domain.UploadSession{}, err
+	}
+
+	entries, err := os.ReadDir(uc.sessionDir(uploadID))
+	if err != nil {
+		return domain.UploadSession{}, err
+	}
+	session.ReceivedChunks = []int{}
+	for _, entry := range entries {
+		index, err := strconv.Atoi(strings.TrimPrefix(entry.Name(), uploadChunkPrefix))
+		if !strings.HasPrefix(entry.Name(), uploadChunkPrefix) || err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return domain.UploadSession{}, err
+		}
+		session.ReceivedChunks = append(session.ReceivedChunks, index)
+		session.ReceivedBytes += info.Size()
+	}
+	sort.Ints(session.ReceivedChunks)
+	return session, nil
+}
+
+// PutChunk stores chunk number index of an upload, replacing any earlier copy of it.
+// A chunk that takes the upload past its declared size or its size limit is rejected.
+func (uc *UploadUsecase) PutChunk(uploadID string, index int, r io.Reader) (domain.UploadSession, error) {
+	if _, err := uc.Get(uploadID); err != nil {
+		return domain.UploadSession{}, err
+	}
+	if index < 0 {
+		return domain.UploadSession{}, fmt.Errorf("%w: chunk index must not be negative", ErrInvalidUpload)
+	}
+
+	tmp, err := os.CreateTemp(uc.sessionDir(uploadID), ".partial-*")
+	if err != nil {
+		return domain.UploadSession{}, err
+	}
+	defer os.Remove(tmp.Name())
+	written, err := io.Copy(tmp, io.LimitReader(r, uc.maxChunkSize+1))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return domain.UploadSession{}, err
+	}
+	if written > uc.maxChunkSize {
+		return domain.UploadSession{}, fmt.Errorf("%w: chunk exceeds the %d bytes limit", ErrArtifactTooLarge, uc.maxChunkSize)
+	}
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	session, err := uc.Get(uploadID)
+	if err != nil {
+		return domain.UploadSession{}, err
+	}
+	total := session.ReceivedBytes + written
+	if info, err := os.Stat(uc.chunkPath(uploadID, index)); err == nil {
+		total -= info.Size()
+	}
+	if session.Size >= 0 && total > session.Size {
+		return domain.UploadSession{}, fmt.Errorf("%w: the chunks add up to more than the declared %d bytes", ErrInvalidUpload, session.Size)
+	}
+	if limit := uc.sizeLimit(session.Filename); limit >= 0 && total > limit {
+		return domain.UploadSession{}, fmt.Errorf("%w: upload exceeds the %d bytes limit", ErrArtifactTooLarge, limit)
+	}
+	if err := os.Rename(tmp.Name(), uc.chunkPath(uploadID, index)); err != nil {
+		return domain.UploadSession{}, err
+	}
+	return uc.Get(uploadID)
+}
+
+// Complete streams the received chunks, in order, into artifact storage and ends the session.
+// Chunks must be numbered 0..n-1 without gaps and add up to the declared size, if any.
+func (uc *UploadUsecase) Complete(ctx context.Context, uploadID string) (domain.Artifact, error) {
+	session, err := uc.Get(uploadID)
+	if err != nil {
+		return domain.Artifact{}, err
+	}
+	logging.Annotate(ctx, "reference_number", session.ReferenceNumber)
+	if len(session.ReceivedChunks) == 0 {
+		return domain.Artifact{}, fmt.Errorf("%w: no chunks received", ErrInvalidUpload)
+	}
+	for i, index := range session.ReceivedChunks {
+		if i != index {
+			return domain.Artifact{}, fmt.Errorf("%w: chunk %d is missing", ErrInvalidUpload, i)
+		}
+	}
+	if session.Size >= 0 && session.ReceivedBytes != session.Size {
+		return domain.Artifact{}, fmt.Errorf("%w: received %d of %d bytes", ErrInvalidUpload, session.ReceivedBytes, session.Size)
+	}
+
+	readers := make([]io.Reader, 0, len(session.ReceivedChunks))
+	for _, index := range session.ReceivedChunks {
+		chunk, err := os.Open(uc.chunkPath(uploadID, index))
+		if err != nil {
+			return domain.Artifact{}, err
+		}
+		defer chunk.Close()
+		readers = append(readers, chunk)
+	}
+
+	artifact, err := uc.artifacts.Store(ctx, ArtifactUpload{
+		ReferenceNumber: session.ReferenceNumber,
+		IdTest:          session.IdTest,
+		StepName:        session.StepName,
+		Filename:        session.Filename,
+		Reader:          io.MultiReader(readers...),
+		Size:            session.ReceivedBytes,
+	})
+	if err != nil {
+		return domain.Artifact{}, err
+	}
+	if err := os.RemoveAll(uc.sessionDir(uploadID)); err != nil {
+		logging.FromContext(ctx).Warn("Error removing completed upload", "upload_id", uploadID, "error", err)
+	}
+	return artifact, nil
+}
+
+// Abort discards an upload session and its chunks.
+func (uc *UploadUsecase) Abort(uploadID string) error {
+	if _, err := uc.Get(uploadID); err != nil {
+		return err
+	}
+	return os.RemoveAll(uc.sessionDir(uploadID))
+}
+
+// RunSweeper discards the sessions that have expired every interval until ctx is
+// cancelled.
+func (uc *UploadUsecase) RunSweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+		uc.removeExpired(ctx)
+	}
+}
+
+// removeExpired discards sessions that have not received a chunk within the expiry.
+func (uc *UploadUsecase) removeExpired(ctx context.Context) {
+	entries, err := os.ReadDir(uc.dir)
+	if err != nil {
+		logging.FromContext(ctx).Warn("Error listing uploads", "error", err)
+		return
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() || !uploadIDPattern.MatchString(entry.Name()) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || time.Since(info.ModTime()) < uc.expiry {
+			continue
+		}
+		if err := os.RemoveAll(uc.sessionDir(entry.Name())); err != nil {
+			logging.FromContext(ctx).Warn("Error removing expired upload", "upload_id", entry.Name(), "error", err)
+		}
+	}
+}
+
+// sizeLimit returns the size limit of an upload, or -1 when it has none. The content of
+// an upload is only sniffed once it is complete, so until then the artifact type is
+// taken from the extension of its filename, or the largest limit of any type applies.
+func (uc *UploadUsecase) sizeLimit(filename string) int64 {
+	if artifactType, ok := extensionTypes[strings.ToLower(filepath.Ext(filename))]; ok {
+		if limit, ok := uc.maxSizes[artifactType]; ok {
+			return limit
+		}
+		return -1
+	}
+	limit := int64(-1)
+	for _, typeLimit := range uc.maxSizes {
+		if typeLimit > limit {
+			limit = typeLimit
+		}
+	}
+	return limit
+}
+
+func (uc *UploadUsecase) sessionDir(uploadID string) string {
+	return filepath.Join(uc.dir, uploadID)
+}
+
+func (uc *UploadUsecase) chunkPath(uploadID string, index int) string {
+	return filepath.Join(uc.sessionDir(uploadID), fmt.Sprintf("%s%06d", uploadChunkPrefix, index))
+}
@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"service-test-runner/internal/config"
+	"service-test-runner/internal/db/dbtest"
+	"service-test-runner/internal/domain"
+	artifactRepo "service-test-runner/internal/repository/artifact"
+	automationRepo "service-test-runner/internal/repository/automation"
+)
+
+func newTestUploadUsecase(t *testing.T) (*UploadUsecase, *fakeStore) {
+	t.Helper()
+	gdb := dbtest.Open(t)
+	runs := automationRepo.NewQueueAutomationRepository(gdb)
+	run := domain.Run{ReferenceNumber: "ref-1", IdTest: "run-1", Testsuite: "suite1", Project: "web1", Status: domain.RunStatusTriggered}
+	if err := runs.Create(context.Background(), &run); err != nil {
+		t.Fatal(err)
+	}
+	store := newFakeStore()
+	maxSizes := map[string]int64{domain.ArtifactTypeLog: 20, domain.ArtifactTypeVideo: 30}
+	artifacts := NewArtifactUsecase(artifactRepo.NewArtifactRepository(gdb), store, maxSizes)
+	uc, err := NewUploadUsecase(artifacts, runs, config.ArtifactConfig{
+		MaxSizes:     maxSizes,
+		UploadDir:    t.TempDir(),
+		MaxChunkSize: 8,
+		UploadExpiry: time.Hour,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return uc, store
+}
+
+func TestChunkedUploadCompletes(t *testing.T) {
+	ctx := context.Background()
+	uc, store := newTestUploadUsecase(t)
+
+	session, err := uc.Create(ctx, domain.UploadSession{ReferenceNumber: "ref-1", Filename: "run.log", Size: 12})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Chunks may arrive out of order, and a chunk sent again replaces the earlier copy.
+	for _, chunk := range []struct {
+		index int
+		data  string
+	}{{1, "line 2\n"}, {0, "line "}, {0, "line "}} {
+		if _, err := uc.PutChunk(session.UploadID, chunk.index, strings.NewReader(chunk.data)); err != nil {
+			t.Fatalf("PutChunk(%d) = %v", chunk.index, err)
+		}
+	}
+
+	artifact, err := uc.Complete(ctx, session.UploadID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if artifact.Size != 12 || artifact.Type != domain.ArtifactTypeLog || !store.has(artifact.ObjectName) {
+		t.Fatalf("stored %+v", artifact)
+	}
+	if _, err := uc.Get(session.UploadID); !errors.Is(err, ErrUploadNotFound) {
+		t.Fatalf("Get after Complete = %v, want ErrUploadNotFound", err)
+	}
+}
+
+func TestPutChunkEnforcesUploadSize(t *testing.T) {
+	ctx := context.Background()
+	uc, _ := newTestUploadUsecase(t)
+
+	if _, err := uc.Create(ctx, domain.UploadSession{ReferenceNumber: "ref-1", Filename: "run.log", Size: 21}); !errors.Is(err, ErrArtifactTooLarge) {
+		t.Fatalf("Create above the log limit = %v, want ErrArtifactTooLarge", err)
+	}
+
+	tests := []struct {
+		name     string
+		filename string
+		size     int64
+		chunks   []string
+		want     error
+	}{
+		{"declared size", "run.log", 10, []string{"12345678", "123"}, ErrInvalidUpload},
+		{"type limit", "run.log", 0, []string{"12345678", "12345678", "12345"}, ErrArtifactTooLarge},
+		{"largest limit for an unknown extension", "run.bin", 0, []string{"12345678", "12345678", "12345678", "1234567"}, ErrArtifactTooLarge},
+		{"chunk limit", "run.log", 0, []string{"123456789"}, ErrArtifactTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session, err := uc.Create(ctx, domain.UploadSession{ReferenceNumber: "ref-1", Filename: tt.filename, Size: tt.size})
+			if err != nil {
+				t.Fatal(err)
+			}
+			last := len(tt.chunks) - 1
+			for i, chunk := range tt.chunks[:last] {
+				if _, err := uc.PutChunk(session.UploadID, i, strings.NewReader(chunk)); err != nil {
+					t.Fatalf("PutChunk(%d) = %v", i, err)
+				}
+			}
+			if _, err := uc.PutChunk(session.UploadID, last, strings.NewReader(tt.chunks[last])); !errors.Is(err, tt.want) {
+				t.Fatalf("PutChunk(%d) = %v, want %v", last, err, tt.want)
+			}
+			got, err := uc.Get(session.UploadID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got.ReceivedChunks) != last {
+				t.Fatalf("received chunks %v, want the rejected one left out", got.ReceivedChunks)
+			}
+		})
+	}
+}
+
+func TestRemoveExpiredKeepsActiveUploads(t *testing.T) {
+	ctx := context.Background()
+	uc, _ := newTestUploadUsecase(t)
+
+	expired, err := uc.Create(ctx, domain.UploadSession{ReferenceNumber: "ref-1", Filename: "run.log"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	active, err := uc.Create(ctx, domain.UploadSession{ReferenceNumber: "ref-1", Filename: "run.log"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	past := time.Now().Add(-2 * time.Hour)
+	if err := os.Chtimes(uc.sessionDir(expired.UploadID), past, past); err != nil {
+		t.Fatal(err)
+	}
+
+	uc.removeExpired(ctx)
+	if _, err := uc.Get(expired.UploadID); !errors.Is(err, ErrUploadNotFound) {
+		t.Fatalf("Get of the expired upload = %v, want ErrUploadNotFound", err)
+	}
+	if _, err := uc.Get(active.UploadID); err != nil {
+		t.Fatalf("Get of the active upload = %v", err)
+	}
+}
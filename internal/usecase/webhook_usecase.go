@@ -0,0 +1,352 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"service-test-runner/internal/config"
+	"service-test-runner/internal/domain"
+	"service-test-runner/internal/infrastructure/logging"
+	"service-test-runner/internal/infrastructure/notification"
+	webhookRepo "service-test-runner/internal/repository/webhook"
+)
+
+var (
+	// ErrWebhookNotFound is returned for unknown webhooks and deliveries.
+	ErrWebhookNotFound = errors.New("webhook not found")
+	// ErrInvalidWebhook is returned when a webhook subscription is malformed.
+	ErrInvalidWebhook = errors.New("invalid webhook")
+)
+
+// webhookDispatchBatch bounds the deliveries attempted per dispatcher pass.
+const webhookDispatchBatch = 50
+
+// webhookPayload is the JSON body posted to webhooks.
+type webhookPayload struct {
+	Event string          `json:"event"`
+	Run   domain.RunEvent `json:"run"`
+}
+
+// webhookTargets checks that a webhook URL does not point at an internal address.
+type webhookTargets interface {
+	Check(ctx context.Context, rawURL string) error
+}
+
+// WebhookUsecase manages per-project webhook subscriptions and delivers run events to
+// them. Deliveries are queued in tbl_webhook_deliveries and sent by RunDispatcher.
+type WebhookUsecase struct {
+	repo    webhookRepo.WebhookRepository
+	sender  notification.WebhookDeliverer
+	targets webhookTargets
+	cfg     config.WebhookConfig
+}
+
+// NewWebhookUsecase creates a new WebhookUsecase with its dependencies injected.
+func NewWebhookUsecase(repo webhookRepo.WebhookRepository, sender notification.WebhookDeliverer, targets webhookTargets, cfg config.WebhookConfig) *WebhookUsecase {
+	return &WebhookUsecase{
+		repo:    repo,
+		sender:  sender,
+		targets: targets,
+		cfg:     cfg,
+	}
+}
+
+// List returns the webhooks of a project, without their secrets.
+func (uc *WebhookUsecase) List(ctx context.Context, project string) ([]domain.Webhook, error) {
+	webhooks, err := uc.repo.GetByProject(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+	return webhooks, nil
+}
+
+// Get returns a webhook, without its secret.
+func (uc *WebhookUsecase) Get(ctx context.Context, id uint) (domain.Webhook, error) {
+	webhook, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+	if webhook == nil {
+		return domain.Webhook{}, ErrWebhookNotFound
+	}
+	webhook.Secret = ""
+	return *webhook, nil
+}
+
+// Create subscribes a URL to the run events of a project. A secret is generated when
+// none is given; it is only returned here.
+func (uc *WebhookUsecase) Create(ctx context.Context, webhook domain.Webhook) (domain.Webhook, error) {
+	if err := uc.validateWebhook(ctx, webhook); err != nil {
+		return domain.Webhook{}, err
+	}
+	if webhook.Secret == "" {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return domain.Webhook{}, err
+		}
+		webhook.Secret = hex.EncodeToString(secret)
+	}
+
+	created := &domain.Webhook{
+		Project: webhook.Project,
+		URL:     webhook.URL,
+		Events:  webhook.Events,
+		Secret:  webhook.Secret,
+		Active:  webhook.Active,
+	}
+	if created.Events == nil {
+		created.Events = []string{}
+	}
+	if err := uc.repo.Create(ctx, created); err != nil {
+		return domain.Webhook{}, err
+	}
+	return *created, nil
+}
+
+// Update replaces the URL, events and active flag of a webhook, and its secret when one is given.
+func (uc *WebhookUsecase) Update(ctx context.Context, id uint, webhook domain.Webhook) (domain.Webhook, error) {
+	existing, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return domain.Webhook{}, err
+	}
+	if existing == nil {
+		return domain.Webhook{}, ErrWebhookNotFound
+	}
+	webhook.Project = existing.Project
+	if err := uc.validateWebhook(ctx, webhook); err != nil {
+		return domain.Webhook{}, err
+	}
+
+	existing.URL = webhook.URL
+	existing.Events = webhook.Events
+	if existing.Events == nil {
+		existing.Events = []string{}
+	}
+	existing.Active = webhook.Active
+	if webhook.Secret != "" {
+		existing.Secret = webhook.Secret
+	}
+	if err := uc.repo.Update(ctx, existing); err != nil {
+		return domain.Webhook{}, err
+	}
+	existing.Secret = ""
+	return *existing, nil
+}
+
+// Delete removes a webhook and its delivery log.
+func (uc *WebhookUsecase) Delete(ctx context.Context, id uint) error {
+	existing, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return ErrWebhookNotFound
+	}
+	return uc.repo.Delete(ctx, id)
+}
+
+// Deliveries returns the most recent deliveries of a webhook, newest first.
+func (uc *WebhookUsecase) Deliveries(ctx context.Context, id uint, limit int) ([]domain.WebhookDelivery, error) {
+	if _, err := uc.Get(ctx, id); err != nil {
+		return nil, err
+	}
+	return uc.repo.GetDeliveries(ctx, id, limit)
+}
+
+// Redeliver queues a new delivery of the payload of an earlier one. The earlier
+// delivery is kept in the log unchanged.
+func (uc *WebhookUsecase) Redeliver(ctx context.Context, deliveryID uint) (domain.WebhookDelivery, error) {
+	previous, err := uc.repo.GetDelivery(ctx, deliveryID)
+	if err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+	if previous == nil {
+		return domain.WebhookDelivery{}, ErrWebhookNotFound
+	}
+	now := time.Now()
+	delivery := &domain.WebhookDelivery{
+		WebhookID:       previous.WebhookID,
+		Event:           previous.Event,
+		ReferenceNumber: previous.ReferenceNumber,
+		Payload:         previous.Payload,
+		Status:          domain.WebhookDeliveryPending,
+		NextAttemptAt:   &now,
+	}
+	if err := uc.repo.CreateDelivery(ctx, delivery); err != nil {
+		return domain.WebhookDelivery{}, err
+	}
+	return *delivery, nil
+}
+
+// Enqueue is a RunListener that queues a delivery of the event to every active webhook
+// of the run's project subscribed to it.
+func (uc *WebhookUsecase) Enqueue(event domain.RunEvent) {
+	ctx := logging.With(context.Background(), "project", event.Project)
+	webhooks, err := uc.repo.GetByProject(ctx, event.Project)
+	if err != nil {
+		logging.FromContext(ctx).Error("Error loading webhooks", "error", err)
+		return
+	}
+	payload, err := json.Marshal(webhookPayload{Event: event.Type, Run: event})
+	if err != nil {
+		logging.FromContext(ctx).Error("Error encoding run event", "event", event.Type, "error", err)
+		return
+	}
+
+	now := time.Now()
+	for _, webhook := range webhooks {
+		if !webhook.Active || !subscribed(webhook.Events, event.Type) {
+			continue
+		}
+		delivery := &domain.WebhookDelivery{
+			WebhookID:       webhook.ID,
+			Event:           event.Type,
+			ReferenceNumber: event.ReferenceNumber,
+			Payload:         string(payload),
+			Status:          domain.WebhookDeliveryPending,
+			NextAttemptAt:   &now,
+		}
+		if err := uc.repo.CreateDelivery(ctx, delivery); err != nil {
+			logging.FromContext(ctx).Error("Error queueing webhook delivery", "event", event.Type, "webhook_id", webhook.ID, "error", err)
+		}
+	}
+}
+
+// Dispatch attempts every due delivery once.
+func (uc *WebhookUsecase) Dispatch(ctx context.Context) error {
+	deliveries, err := uc.repo.GetDueDeliveries(ctx, domain.WebhookDeliveryPending, time.Now(), webhookDispatchBatch)
+	if err != nil {
+		return err
+	}
+	for i := range deliveries {
+		uc.deliver(ctx, &deliveries[i])
+	}
+	return nil
+}
+
+// RunDispatcher sends due webhook deliveries every interval until ctx is cancelled;
+// a pass in progress is finished first. Only one instance of the service should run
+// the dispatcher.
+func (uc *WebhookUsecase) RunDispatcher(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+		if err := uc.Dispatch(context.WithoutCancel(ctx)); err != nil {
+			logging.FromContext(ctx).Error("Webhook dispatcher failed", "error", err)
+		}
+	}
+}
+
+// deliver makes one attempt at a delivery and schedules the next one on failure.
+func (uc *WebhookUsecase) deliver(ctx context.Context, delivery *domain.WebhookDelivery) {
+	ctx = logging.With(ctx, "reference_number", delivery.ReferenceNumber, "delivery_id", delivery.ID)
+	webhook, err := uc.repo.GetByID(ctx, delivery.WebhookID)
+	if err != nil {
+		logging.FromContext(ctx).Error("Error loading webhook", "webhook_id", delivery.WebhookID, "error", err)
+		return
+	}
+
+	delivery.Attempts++
+	if webhook == nil || !webhook.Active {
+		delivery.Status = domain.WebhookDeliveryFailed
+		delivery.Error = "webhook deleted or disabled"
+		delivery.NextAttemptAt = nil
+	} else {
+		code, body, err := uc.sender.Send(ctx, webhook.URL, webhook.Secret, delivery.Event, delivery.ID, []byte(delivery.Payload))
+		delivery.ResponseCode, delivery.ResponseBody, delivery.Error = code, body, ""
+		if err == nil && (code < 200 || code > 299) {
+			err = fmt.Errorf("unexpected response status %d", code)
+		}
+
+		now := time.Now()
+		switch {
+		case err == nil:
+			delivery.Status = domain.WebhookDeliverySucceeded
+			delivery.DeliveredAt = &now
+			delivery.NextAttemptAt = nil
+		case delivery.Attempts >= uc.cfg.MaxAttempts:
+			delivery.Status = domain.WebhookDeliveryFailed
+			delivery.Error = err.Error()
+			delivery.NextAttemptAt = nil
+		default:
+			next := now.Add(uc.backoff(delivery.Attempts))
+			delivery.Error = err.Error()
+			delivery.NextAttemptAt = &next
+		}
+	}
+
+	if err := uc.repo.UpdateDeliveryAttempt(ctx, delivery); err != nil {
+		logging.FromContext(ctx).Error("Error saving webhook delivery", "error", err)
+	}
+}
+
+// backoff returns the delay before the attempt following the given one: the base
+// delay doubled for every earlier failed attempt, up to the maximum.
+func (uc *WebhookUsecase) backoff(attempts int) time.Duration {
+	delay := uc.cfg.BackoffBase
+	for i := 1; i < attempts && delay < uc.cfg.BackoffMax; i++ {
+		delay *= 2
+	}
+	if delay > uc.cfg.BackoffMax {
+		delay = uc.cfg.BackoffMax
+	}
+	return delay
+}
+
+// validateWebhook checks a webhook subscription, refusing URLs that point at a loopback,
+// private or link-local address outside of notifications.webhooks.allowed_networks.
+func (uc *WebhookUsecase) validateWebhook(ctx context.Context, webhook domain.Webhook) error {
+	if webhook.Project == "" {
+		return fmt.Errorf("%w: project is required", ErrInvalidWebhook)
+	}
+	u, err := url.Parse(webhook.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidWebhook)
+	}
+	if err := uc.targets.Check(ctx, webhook.URL); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidWebhook, err)
+	}
+	for _, event := range webhook.Events {
+		if !isRunEventType(event) {
+			return fmt.Errorf("%w: unknown event %q, expected one of %s", ErrInvalidWebhook, event, strings.Join(domain.RunEventTypes, ", "))
+		}
+	}
+	return nil
+}
+
+// subscribed reports whether an event list, where empty means all events, contains an event.
+func subscribed(events []string, event string) bool {
+	if len(events) == 0 {
+		return true
+	}
+	for _, e := range events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+func isRunEventType(event string) bool {
+	for _, eventType := range domain.RunEventTypes {
+		if eventType == event {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"service-test-runner/internal/config"
+	"service-test-runner/internal/db/dbtest"
+	"service-test-runner/internal/domain"
+	"service-test-runner/internal/infrastructure/notification"
+	webhookRepo "service-test-runner/internal/repository/webhook"
+)
+
+func TestWebhooksStayOffInternalTargetsAndEmails(t *testing.T) {
+	ctx := context.Background()
+	repo := webhookRepo.NewWebhookRepository(dbtest.Open(t))
+	targets, err := notification.NewWebhookTargets([]string{"10.20.0.0/16"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	uc := NewWebhookUsecase(repo, nil, targets, config.WebhookConfig{})
+
+	for _, url := range []string{"http://127.0.0.1/hook", "http://169.254.169.254/latest", "http://192.168.0.10/hook"} {
+		if _, err := uc.Create(ctx, domain.Webhook{Project: "web1", URL: url, Active: true}); !errors.Is(err, ErrInvalidWebhook) {
+			t.Errorf("Create(%s) = %v, want ErrInvalidWebhook", url, err)
+		}
+	}
+	webhook, err := uc.Create(ctx, domain.Webhook{Project: "web1", URL: "http://10.20.0.5/hook", Active: true})
+	if err != nil {
+		t.Fatalf("Create on an allowed network = %v", err)
+	}
+	if _, err := uc.Update(ctx, webhook.ID, domain.Webhook{URL: "http://[::1]/hook", Active: true}); !errors.Is(err, ErrInvalidWebhook) {
+		t.Errorf("Update to a loopback address = %v, want ErrInvalidWebhook", err)
+	}
+
+	uc.Enqueue(domain.RunEvent{Type: domain.RunEventFinished, ReferenceNumber: "ref-1", Project: "web1", Email: "qa@example.com"})
+	deliveries, err := repo.GetDeliveries(ctx, webhook.ID, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deliveries) != 1 {
+		t.Fatalf("deliveries = %+v, want one", deliveries)
+	}
+	if strings.Contains(deliveries[0].Payload, "qa@example.com") {
+		t.Fatalf("payload carries the requester's email: %s", deliveries[0].Payload)
+	}
+}
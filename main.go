@@ -1,20 +1,38 @@
 package main
 
 import (
-	"fmt"
+	"context"
+	"errors"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"sort"
+	"sync"
+	"syscall"
 
 	"service-test-runner/internal/config"
 	"service-test-runner/internal/db"
 	httpDelivery "service-test-runner/internal/delivery/http"
 	handler "service-test-runner/internal/delivery/http/handler"
+	"service-test-runner/internal/infrastructure/logging"
 	"service-test-runner/internal/infrastructure/messaging"
+	"service-test-runner/internal/infrastructure/metrics"
+	"service-test-runner/internal/infrastructure/notification"
 	"service-test-runner/internal/infrastructure/storage"
+	"service-test-runner/internal/infrastructure/tracing"
+	"service-test-runner/internal/repository"
+	artifactRepo "service-test-runner/internal/repository/artifact"
 	automationRepo "service-test-runner/internal/repository/automation"
+	chatRepo "service-test-runner/internal/repository/chat"
+	"service-test-runner/internal/repository/grid"
+	outboxRepo "service-test-runner/internal/repository/outbox"
 	"service-test-runner/internal/repository/project"
+	resultRepo "service-test-runner/internal/repository/result"
+	retentionRepo "service-test-runner/internal/repository/retention"
 	"service-test-runner/internal/repository/selenium"
+	webhookRepo "service-test-runner/internal/repository/webhook"
 	"service-test-runner/internal/usecase"
 
 	"github.com/gorilla/mux"
@@ -26,42 +44,188 @@ func main() {
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
+	logging.Setup(cfg.Log)
+
+	// ctx is cancelled on SIGTERM or SIGINT, which starts the graceful shutdown.
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	// Export traces (OTLP, stdout or none, see tracing.exporter).
+	shutdownTracing, err := tracing.Setup(cfg.Tracing)
+	if err != nil {
+		log.Fatalf("Failed to initialize tracing: %v", err)
+	}
+	defer shutdownTracing(context.Background())
 
 	// Connect to RabbitMQ using the dynamically built URL.
-	conn, channel, err := messaging.ConnectToRabbitMQ(cfg.RabbitMQ.AMQPURL())
+	publisher, err := messaging.NewRabbitMQPublisher(cfg.RabbitMQ)
 	if err != nil {
 		log.Fatalf("Failed to connect to RabbitMQ: %v", err)
 	}
-	defer conn.Close()
-	defer channel.Close()
 
-	// Initialize MinIO Service
-	minioService, err := storage.NewMinioService(&cfg.MinIO)
+	// Initialize the artifact store (MinIO or local filesystem, see storage.driver)
+	artifactStore, err := storage.NewArtifactStore(cfg)
 	if err != nil {
-		log.Fatalf("Failed to initialize MinIO service: %v", err)
+		log.Fatalf("Failed to initialize artifact storage: %v", err)
 	}
 
-	// Initialize the database connection using GORM (see internal/db/db.go)
-	if err := db.InitDB(cfg); err != nil {
+	// Initialize the database connection using GORM (see internal/db/db.go); the
+	// repositories are given the handle.
+	gdb, err := db.Open(cfg)
+	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
+	if cfg.Database.Migrate {
+		if err := db.Migrate(ctx, gdb, cfg.Database.Driver); err != nil {
+			log.Fatalf("Failed to migrate database: %v", err)
+		}
+	}
 
 	// Load project mappings from the database using GORM.
-	projects, err := db.LoadProjects()
+	projectRepo := project.NewProjectRepository(gdb)
+	projects, err := projectRepo.LoadProjects(ctx)
 	if err != nil {
 		log.Fatalf("Failed to load projects from database: %v", err)
 	}
-	fmt.Println("rabbit", cfg.RabbitMQ)
+	projectNames := make([]string, 0, len(projects))
+	for project := range projects {
+		projectNames = append(projectNames, project)
+	}
+	sort.Strings(projectNames)
+	// Declare the RabbitMQ queue of every project; new ones are declared on their first run.
+	if err := publisher.DeclareProjectQueues(projectNames...); err != nil {
+		log.Fatalf("Failed to declare project queues: %v", err)
+	}
+	// Load the runner nodes of each project; a project without nodes uses its own URL.
+	runnerNodes, err := projectRepo.LoadRunnerNodes(ctx)
+	if err != nil {
+		log.Fatalf("Failed to load runner nodes from database: %v", err)
+	}
+	queueAutomationRepository := automationRepo.NewQueueAutomationRepository(gdb)
 	// Initialize repository with the project mappings.
-	seleniumRepo := selenium.NewSeleniumRepository(projects)
-	projectRepo := project.NewProjectRepository(projects)
-	queueAutomationRepository := automationRepo.NewQueueAutomationRepository()
-	messaging := messaging.NewRabbitMQPublisher(channel, cfg.RabbitMQ.ExchangeName)
+	seleniumRepo := selenium.NewSeleniumRepository(projects, runnerNodes, queueAutomationRepository, cfg.Runners)
+	// Projects whose runner drives a Selenium Grid 4 hub.
+	gridHubs, err := projectRepo.LoadGridHubs(ctx)
+	if err != nil {
+		log.Fatalf("Failed to load Selenium Grid hubs from database: %v", err)
+	}
+	gridRepo := grid.NewGridRepository(gridHubs, cfg.Runners.RequestTimeout)
+	artifactRepository := artifactRepo.NewArtifactRepository(gdb)
+	resultRepository := resultRepo.NewResultRepository(gdb)
+	retentionRepository := retentionRepo.NewRetentionRepository(gdb)
+	webhookRepository := webhookRepo.NewWebhookRepository(gdb)
+	chatRepository := chatRepo.NewChatRepository(gdb)
+	outboxRepository := outboxRepo.NewOutboxRepository(gdb)
+	unitOfWork := repository.NewUnitOfWork(gdb)
 	// Initialize use cases.
-	automationUsecase := usecase.NewAutomationUsecase(seleniumRepo, messaging)
-	queueAutomationUsecase := usecase.NewQueueAutomationUseCase(queueAutomationRepository)
+	runEvents := usecase.NewRunEvents()
+	outboxUsecase := usecase.NewOutboxUsecase(outboxRepository, unitOfWork, publisher, cfg.Outbox)
+	capacityUsecase := usecase.NewCapacityUsecase(projectRepo, queueAutomationRepository)
+	gridUsecase := usecase.NewGridUsecase(gridRepo)
+	automationUsecase := usecase.NewAutomationUsecase(seleniumRepo, capacityUsecase, gridUsecase)
+	queueAutomationUsecase := usecase.NewQueueAutomationUseCase(queueAutomationRepository, outboxUsecase, runEvents)
 	testsuiteUsecase := usecase.NewTestSuiteUsecase(seleniumRepo)
 	projectUsecase := usecase.NewProjectUsecase(projectRepo)
+	artifactUsecase := usecase.NewArtifactUsecase(artifactRepository, artifactStore, cfg.Artifacts.MaxSizes)
+	uploadUsecase, err := usecase.NewUploadUsecase(artifactUsecase, queueAutomationRepository, cfg.Artifacts)
+	if err != nil {
+		log.Fatalf("Failed to initialize uploads: %v", err)
+	}
+	resultUsecase := usecase.NewResultUsecase(resultRepository, queueAutomationRepository, artifactUsecase, runEvents)
+	retentionUsecase := usecase.NewRetentionUsecase(
+		retentionRepository,
+		queueAutomationRepository,
+		artifactRepository,
+		projectRepo,
+		artifactStore,
+		cfg.Retention)
+
+	// Expose run and queue metrics.
+	runEvents.Subscribe(metrics.ObserveRunEvent)
+	metrics.RegisterQueueDepth(func() (int64, error) { return queueAutomationUsecase.QueueDepth(context.Background()) })
+	metrics.RegisterOutboxDepth(func() (int64, error) { return outboxUsecase.Pending(context.Background()) })
+
+	// Email a summary when a run finishes.
+	var mailer notification.Mailer
+	var templates *notification.Templates
+	if cfg.Notifications.Email.Enabled {
+		templates, err = notification.NewTemplates(cfg.Notifications.Email.TemplateDir)
+		if err != nil {
+			log.Fatalf("Failed to load notification templates: %v", err)
+		}
+		mailer = notification.NewSMTPMailer(cfg.Notifications.Email)
+	}
+	notificationUsecase := usecase.NewNotificationUsecase(
+		queueAutomationRepository,
+		resultUsecase,
+		artifactUsecase,
+		mailer,
+		templates)
+	if cfg.Notifications.Email.Enabled {
+		runEvents.Subscribe(notificationUsecase.EmailRunFinished)
+	}
+
+	// Post to Slack and Teams when a run fails or recovers.
+	chatUsecase := usecase.NewChatUsecase(
+		chatRepository,
+		queueAutomationRepository,
+		notificationUsecase,
+		notification.NewChatPoster(cfg.Notifications.Chat.Timeout))
+	if cfg.Notifications.Chat.Enabled {
+		runEvents.Subscribe(chatUsecase.PostRunFinished)
+	}
+
+	// Background workers stop once ctx is cancelled; the shutdown waits for them.
+	var workers sync.WaitGroup
+
+	// Deliver run events to the project webhooks.
+	webhookTargets, err := notification.NewWebhookTargets(cfg.Notifications.Webhooks.AllowedNetworks)
+	if err != nil {
+		log.Fatalf("Failed to initialize webhooks: %v", err)
+	}
+	webhookUsecase := usecase.NewWebhookUsecase(
+		webhookRepository,
+		notification.NewWebhookSender(cfg.Notifications.Webhooks.Timeout, webhookTargets),
+		webhookTargets,
+		cfg.Notifications.Webhooks)
+	if cfg.Notifications.Webhooks.Enabled {
+		runEvents.Subscribe(webhookUsecase.Enqueue)
+		goWorker(&workers, func() { webhookUsecase.RunDispatcher(ctx, cfg.Notifications.Webhooks.PollInterval) })
+	}
+
+	// Report liveness and the readiness of every dependency.
+	healthChecks := []usecase.HealthCheck{
+		{Name: "database", Critical: true, Check: db.Ping(gdb)},
+		{Name: "rabbitmq", Critical: true, Check: publisher.Ping},
+		{Name: "storage", Critical: true, Check: artifactStore.Ping},
+	}
+	if cfg.Health.CheckRunners {
+		for _, project := range projectNames {
+			healthChecks = append(healthChecks, usecase.HealthCheck{
+				Name:  "runner:" + project,
+				Check: func(ctx context.Context) error { return seleniumRepo.Ping(ctx, project) },
+			})
+		}
+	}
+	healthUsecase := usecase.NewHealthUsecase(cfg.Health.Timeout, healthChecks...)
+
+	// Relay queued-run messages from the outbox to RabbitMQ.
+	goWorker(&workers, func() { outboxUsecase.RunRelay(ctx, cfg.Outbox.PollInterval) })
+
+	// Start queued runs once their runner has room, most urgent first.
+	dispatchUsecase := usecase.NewDispatchUsecase(queueAutomationRepository, queueAutomationUsecase, automationUsecase, cfg.Dispatch)
+	if cfg.Dispatch.Enabled {
+		runEvents.Subscribe(dispatchUsecase.RunFinished)
+		goWorker(&workers, func() { dispatchUsecase.RunDispatcher(ctx, cfg.Dispatch.PollInterval) })
+	}
+
+	// Discard the chunked uploads that were abandoned.
+	goWorker(&workers, func() { uploadUsecase.RunSweeper(ctx, cfg.Artifacts.UploadSweepInterval) })
+
+	// Start the retention janitor.
+	if cfg.Retention.Enabled {
+		goWorker(&workers, func() { retentionUsecase.RunJanitor(ctx, cfg.Retention.Interval) })
+	}
 
 	// Setup HTTP router.
 	router := mux.NewRouter()
@@ -70,7 +234,17 @@ func main() {
 		queueAutomationUsecase,
 		testsuiteUsecase,
 		projectUsecase,
-		minioService)
+		artifactUsecase,
+		resultUsecase,
+		retentionUsecase,
+		uploadUsecase,
+		webhookUsecase,
+		chatUsecase,
+		healthUsecase,
+		capacityUsecase,
+		gridUsecase,
+		dispatchUsecase,
+		artifactStore)
 	httpDelivery.RegisterRoutes(router, handler)
 
 	// Start the server.
@@ -78,6 +252,60 @@ func main() {
 	if port == "" {
 		port = "6000"
 	}
-	log.Printf("Server running on port %s", port)
-	log.Fatal(http.ListenAndServe(":"+port, router))
+	server := &http.Server{Addr: ":" + port, Handler: router}
+	go func() {
+		slog.Info("Server running", "port", port)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Server failed: %v", err)
+		}
+	}()
+
+	// Shut down gracefully: stop accepting requests and let those in progress finish,
+	// then let the background workers and the run event listeners finish, and only
+	// then close the connections.
+	<-ctx.Done()
+	stop()
+	slog.Info("Shutting down", "timeout", cfg.Server.ShutdownTimeout.String())
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Error draining HTTP requests", "error", err)
+	}
+	if err := waitWorkers(shutdownCtx, &workers); err != nil {
+		slog.Error("Error waiting for background workers", "error", err)
+	}
+	if err := runEvents.Close(shutdownCtx); err != nil {
+		slog.Error("Error waiting for run event listeners", "error", err)
+	}
+	if err := publisher.Close(); err != nil {
+		slog.Error("Error closing RabbitMQ connection", "error", err)
+	}
+	if err := db.Close(gdb); err != nil {
+		slog.Error("Error closing database connection", "error", err)
+	}
+	slog.Info("Server stopped")
+}
+
+// goWorker runs a background worker, counted in workers until it returns.
+func goWorker(workers *sync.WaitGroup, worker func()) {
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		worker()
+	}()
+}
+
+// waitWorkers waits until every background worker has returned, or until ctx is done.
+func waitWorkers(ctx context.Context, workers *sync.WaitGroup) error {
+	done := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
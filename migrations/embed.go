@@ -0,0 +1,9 @@
+// Package migrations holds the SQL migrations of each supported database driver.
+package migrations
+
+import "embed"
+
+// FS holds one directory of migrations per driver: mysql, postgres and sqlite.
+//
+//go:embed mysql postgres sqlite
+var FS embed.FS
@@ -0,0 +1,14 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS tbl_artifacts (
+  id INT AUTO_INCREMENT PRIMARY KEY,
+  reference_number VARCHAR(255) NOT NULL,
+  id_test VARCHAR(255) NULL,
+  step_name VARCHAR(255) NULL,
+  name VARCHAR(255) NOT NULL,
+  type VARCHAR(32) NOT NULL,
+  content_type VARCHAR(255) NOT NULL,
+  size BIGINT NOT NULL,
+  object_name VARCHAR(1024) NOT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  INDEX idx_tbl_artifacts_reference_number (reference_number)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
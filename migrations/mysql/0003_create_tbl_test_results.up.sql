@@ -0,0 +1,14 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS tbl_test_results (
+  id INT AUTO_INCREMENT PRIMARY KEY,
+  reference_number VARCHAR(255) NOT NULL,
+  suite VARCHAR(255) NOT NULL,
+  name VARCHAR(512) NOT NULL,
+  class_name VARCHAR(512) NULL,
+  status VARCHAR(16) NOT NULL,
+  duration DOUBLE NOT NULL DEFAULT 0,
+  failure_message TEXT NULL,
+  stack_trace TEXT NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  INDEX idx_tbl_test_results_reference_number (reference_number)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
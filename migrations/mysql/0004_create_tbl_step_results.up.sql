@@ -0,0 +1,13 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS tbl_step_results (
+  id INT AUTO_INCREMENT PRIMARY KEY,
+  test_result_id INT NOT NULL,
+  reference_number VARCHAR(255) NOT NULL,
+  keyword VARCHAR(64) NULL,
+  name VARCHAR(1024) NOT NULL,
+  status VARCHAR(16) NOT NULL,
+  duration DOUBLE NOT NULL DEFAULT 0,
+  error_message TEXT NULL,
+  INDEX idx_tbl_step_results_test_result_id (test_result_id),
+  INDEX idx_tbl_step_results_reference_number (reference_number)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,13 @@
+-- +migrate Up
+ALTER TABLE tbl_queue_automations
+  ADD COLUMN pinned TINYINT(1) NOT NULL DEFAULT 0,
+  ADD COLUMN archived_at DATETIME NULL;
+
+CREATE TABLE IF NOT EXISTS tbl_retention_policies (
+  id INT AUTO_INCREMENT PRIMARY KEY,
+  project VARCHAR(255) NOT NULL UNIQUE,
+  keep_last_runs INT NOT NULL DEFAULT 0,
+  max_age_days INT NOT NULL DEFAULT 0,
+  failed_max_age_days INT NOT NULL DEFAULT 0,
+  updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,3 @@
+-- +migrate Down
+ALTER TABLE tbl_artifacts
+  DROP COLUMN checksum;
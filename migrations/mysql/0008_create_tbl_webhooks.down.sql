@@ -0,0 +1,3 @@
+-- +migrate Down
+DROP TABLE IF EXISTS tbl_webhook_deliveries;
+DROP TABLE IF EXISTS tbl_webhooks;
@@ -0,0 +1,30 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS tbl_webhooks (
+  id INT AUTO_INCREMENT PRIMARY KEY,
+  project VARCHAR(255) NOT NULL,
+  url VARCHAR(2048) NOT NULL,
+  events VARCHAR(255) NULL,
+  secret VARCHAR(255) NOT NULL,
+  active TINYINT(1) NOT NULL DEFAULT 1,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  INDEX idx_tbl_webhooks_project (project)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+CREATE TABLE IF NOT EXISTS tbl_webhook_deliveries (
+  id INT AUTO_INCREMENT PRIMARY KEY,
+  webhook_id INT NOT NULL,
+  event VARCHAR(64) NOT NULL,
+  reference_number VARCHAR(255) NOT NULL,
+  payload TEXT NOT NULL,
+  status VARCHAR(16) NOT NULL,
+  attempts INT NOT NULL DEFAULT 0,
+  response_code INT NOT NULL DEFAULT 0,
+  response_body TEXT NULL,
+  error TEXT NULL,
+  next_attempt_at DATETIME NULL,
+  delivered_at DATETIME NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  INDEX idx_tbl_webhook_deliveries_webhook_id (webhook_id),
+  INDEX idx_tbl_webhook_deliveries_due (status, next_attempt_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,12 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS tbl_chat_notifications (
+  id INT AUTO_INCREMENT PRIMARY KEY,
+  project VARCHAR(255) NOT NULL,
+  testsuite VARCHAR(255) NOT NULL DEFAULT '',
+  provider VARCHAR(16) NOT NULL,
+  webhook_url VARCHAR(2048) NOT NULL,
+  active TINYINT(1) NOT NULL DEFAULT 1,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  INDEX idx_tbl_chat_notifications_project (project)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
@@ -0,0 +1,9 @@
+-- +migrate Up
+ALTER TABLE tbl_outbox_messages
+  ADD COLUMN project VARCHAR(255) NOT NULL DEFAULT '' AFTER reference_number,
+  ADD COLUMN priority VARCHAR(16) NOT NULL DEFAULT 'normal' AFTER project;
+
+UPDATE tbl_outbox_messages o
+  JOIN tbl_queue_automations q ON q.reference_number = o.reference_number
+  SET o.project = q.project
+  WHERE o.project = '';
@@ -0,0 +1,4 @@
+-- +migrate Down
+ALTER TABLE tbl_queue_automations
+  DROP INDEX idx_tbl_queue_automations_status_priority,
+  DROP COLUMN priority;
@@ -0,0 +1,4 @@
+-- +migrate Up
+ALTER TABLE tbl_queue_automations
+  ADD COLUMN priority VARCHAR(16) NOT NULL DEFAULT 'normal' AFTER status,
+  ADD INDEX idx_tbl_queue_automations_status_priority (status, priority, created_at);
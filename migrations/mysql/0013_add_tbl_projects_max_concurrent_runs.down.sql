@@ -0,0 +1,3 @@
+-- +migrate Down
+ALTER TABLE tbl_projects
+  DROP COLUMN max_concurrent_runs;
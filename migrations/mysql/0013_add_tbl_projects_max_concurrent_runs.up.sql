@@ -0,0 +1,3 @@
+-- +migrate Up
+ALTER TABLE tbl_projects
+  ADD COLUMN max_concurrent_runs INT NOT NULL DEFAULT 0 AFTER url;
@@ -0,0 +1,16 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS tbl_runner_nodes (
+  id INT AUTO_INCREMENT PRIMARY KEY,
+  project VARCHAR(255) NOT NULL,
+  url VARCHAR(255) NOT NULL,
+  weight INT NOT NULL DEFAULT 1,
+  enabled TINYINT(1) NOT NULL DEFAULT 1,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  UNIQUE INDEX idx_tbl_runner_nodes_project_url (project, url)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;
+
+INSERT INTO tbl_runner_nodes (project, url)
+  SELECT name, url FROM tbl_projects;
+
+ALTER TABLE tbl_queue_automations
+  ADD COLUMN runner_url VARCHAR(255) NULL AFTER project;
@@ -0,0 +1,8 @@
+-- +migrate Up
+ALTER TABLE tbl_projects
+  ADD COLUMN grid_url VARCHAR(255) NULL AFTER url;
+
+ALTER TABLE tbl_queue_automations
+  ADD COLUMN browser_name VARCHAR(64) NULL AFTER runner_url,
+  ADD COLUMN browser_version VARCHAR(64) NULL AFTER browser_name,
+  ADD COLUMN platform_name VARCHAR(64) NULL AFTER browser_version;
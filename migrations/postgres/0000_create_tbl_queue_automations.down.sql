@@ -0,0 +1,3 @@
+-- +migrate Down
+-- Nothing to undo: tbl_queue_automations may hold runs from before the migrations, and
+-- the up migration only creates it when it is missing, so it is kept.
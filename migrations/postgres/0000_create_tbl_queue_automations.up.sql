@@ -0,0 +1,17 @@
+-- +migrate Up
+-- tbl_queue_automations predates the migrations, so this migration comes before them
+-- all. It creates the table with its original columns if missing; the later migrations
+-- add the others.
+CREATE TABLE IF NOT EXISTS tbl_queue_automations (
+  id SERIAL PRIMARY KEY,
+  reference_number VARCHAR(255) NOT NULL UNIQUE,
+  testsuite VARCHAR(255) NOT NULL,
+  step_name VARCHAR(255) NOT NULL DEFAULT '',
+  checkpoint INT NOT NULL DEFAULT 0,
+  total_steps INT NOT NULL DEFAULT 0,
+  status INT NOT NULL,
+  id_test VARCHAR(255) NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  project VARCHAR(255) NOT NULL,
+  report_file VARCHAR(1024) NULL
+);
@@ -0,0 +1,15 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS tbl_test_results (
+  id SERIAL PRIMARY KEY,
+  reference_number VARCHAR(255) NOT NULL,
+  suite VARCHAR(255) NOT NULL,
+  name VARCHAR(512) NOT NULL,
+  class_name VARCHAR(512) NULL,
+  status VARCHAR(16) NOT NULL,
+  duration DOUBLE PRECISION NOT NULL DEFAULT 0,
+  failure_message TEXT NULL,
+  stack_trace TEXT NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_tbl_test_results_reference_number ON tbl_test_results (reference_number);
@@ -0,0 +1,14 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS tbl_step_results (
+  id SERIAL PRIMARY KEY,
+  test_result_id INT NOT NULL,
+  reference_number VARCHAR(255) NOT NULL,
+  keyword VARCHAR(64) NULL,
+  name VARCHAR(1024) NOT NULL,
+  status VARCHAR(16) NOT NULL,
+  duration DOUBLE PRECISION NOT NULL DEFAULT 0,
+  error_message TEXT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_tbl_step_results_test_result_id ON tbl_step_results (test_result_id);
+CREATE INDEX IF NOT EXISTS idx_tbl_step_results_reference_number ON tbl_step_results (reference_number);
@@ -0,0 +1,6 @@
+-- +migrate Down
+DROP TABLE IF EXISTS tbl_retention_policies;
+
+ALTER TABLE tbl_queue_automations
+  DROP COLUMN archived_at,
+  DROP COLUMN pinned;
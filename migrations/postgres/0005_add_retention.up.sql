@@ -0,0 +1,13 @@
+-- +migrate Up
+ALTER TABLE tbl_queue_automations
+  ADD COLUMN pinned BOOLEAN NOT NULL DEFAULT FALSE,
+  ADD COLUMN archived_at TIMESTAMPTZ NULL;
+
+CREATE TABLE IF NOT EXISTS tbl_retention_policies (
+  id SERIAL PRIMARY KEY,
+  project VARCHAR(255) NOT NULL UNIQUE,
+  keep_last_runs INT NOT NULL DEFAULT 0,
+  max_age_days INT NOT NULL DEFAULT 0,
+  failed_max_age_days INT NOT NULL DEFAULT 0,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
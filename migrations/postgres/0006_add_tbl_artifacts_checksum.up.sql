@@ -0,0 +1,3 @@
+-- +migrate Up
+ALTER TABLE tbl_artifacts
+  ADD COLUMN checksum CHAR(64) NULL;
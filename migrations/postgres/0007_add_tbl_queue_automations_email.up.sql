@@ -0,0 +1,3 @@
+-- +migrate Up
+ALTER TABLE tbl_queue_automations
+  ADD COLUMN email VARCHAR(1024) NULL;
@@ -0,0 +1,13 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS tbl_chat_notifications (
+  id SERIAL PRIMARY KEY,
+  project VARCHAR(255) NOT NULL,
+  testsuite VARCHAR(255) NOT NULL DEFAULT '',
+  provider VARCHAR(16) NOT NULL,
+  webhook_url VARCHAR(2048) NOT NULL,
+  active BOOLEAN NOT NULL DEFAULT TRUE,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_tbl_chat_notifications_project ON tbl_chat_notifications (project);
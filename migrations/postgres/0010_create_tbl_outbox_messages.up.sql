@@ -0,0 +1,14 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS tbl_outbox_messages (
+  id BIGSERIAL PRIMARY KEY,
+  reference_number VARCHAR(255) NOT NULL,
+  payload TEXT NOT NULL,
+  status VARCHAR(16) NOT NULL,
+  attempts INT NOT NULL DEFAULT 0,
+  error TEXT NULL,
+  next_attempt_at TIMESTAMPTZ NOT NULL,
+  sent_at TIMESTAMPTZ NULL,
+  created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_tbl_outbox_messages_due ON tbl_outbox_messages (status, next_attempt_at);
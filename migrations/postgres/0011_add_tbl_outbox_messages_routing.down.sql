@@ -0,0 +1,4 @@
+-- +migrate Down
+ALTER TABLE tbl_outbox_messages
+  DROP COLUMN priority,
+  DROP COLUMN project;
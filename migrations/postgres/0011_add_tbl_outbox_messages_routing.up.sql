@@ -0,0 +1,9 @@
+-- +migrate Up
+ALTER TABLE tbl_outbox_messages
+  ADD COLUMN project VARCHAR(255) NOT NULL DEFAULT '',
+  ADD COLUMN priority VARCHAR(16) NOT NULL DEFAULT 'normal';
+
+UPDATE tbl_outbox_messages
+  SET project = (SELECT q.project FROM tbl_queue_automations q WHERE q.reference_number = tbl_outbox_messages.reference_number)
+  WHERE project = ''
+    AND EXISTS (SELECT 1 FROM tbl_queue_automations q WHERE q.reference_number = tbl_outbox_messages.reference_number);
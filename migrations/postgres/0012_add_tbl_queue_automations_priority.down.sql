@@ -0,0 +1,5 @@
+-- +migrate Down
+DROP INDEX IF EXISTS idx_tbl_queue_automations_status_priority;
+
+ALTER TABLE tbl_queue_automations
+  DROP COLUMN priority;
@@ -0,0 +1,5 @@
+-- +migrate Down
+ALTER TABLE tbl_queue_automations
+  DROP COLUMN runner_url;
+
+DROP TABLE IF EXISTS tbl_runner_nodes;
@@ -0,0 +1,8 @@
+-- +migrate Down
+ALTER TABLE tbl_queue_automations
+  DROP COLUMN platform_name,
+  DROP COLUMN browser_version,
+  DROP COLUMN browser_name;
+
+ALTER TABLE tbl_projects
+  DROP COLUMN grid_url;
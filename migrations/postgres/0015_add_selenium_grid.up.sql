@@ -0,0 +1,8 @@
+-- +migrate Up
+ALTER TABLE tbl_projects
+  ADD COLUMN grid_url VARCHAR(255) NULL;
+
+ALTER TABLE tbl_queue_automations
+  ADD COLUMN browser_name VARCHAR(64) NULL,
+  ADD COLUMN browser_version VARCHAR(64) NULL,
+  ADD COLUMN platform_name VARCHAR(64) NULL;
@@ -0,0 +1,10 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS tbl_projects (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  name VARCHAR(255) NOT NULL,
+  url VARCHAR(255) NOT NULL
+);
+
+INSERT INTO tbl_projects (name, url) VALUES
+  ('web1', 'http://localhost:5000'),
+  ('mobile1', 'http://localhost:5001');
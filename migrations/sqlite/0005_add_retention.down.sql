@@ -0,0 +1,8 @@
+-- +migrate Down
+DROP TABLE IF EXISTS tbl_retention_policies;
+
+ALTER TABLE tbl_queue_automations
+  DROP COLUMN archived_at;
+
+ALTER TABLE tbl_queue_automations
+  DROP COLUMN pinned;
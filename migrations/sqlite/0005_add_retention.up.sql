@@ -0,0 +1,15 @@
+-- +migrate Up
+ALTER TABLE tbl_queue_automations
+  ADD COLUMN pinned INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE tbl_queue_automations
+  ADD COLUMN archived_at DATETIME NULL;
+
+CREATE TABLE IF NOT EXISTS tbl_retention_policies (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  project VARCHAR(255) NOT NULL UNIQUE,
+  keep_last_runs INT NOT NULL DEFAULT 0,
+  max_age_days INT NOT NULL DEFAULT 0,
+  failed_max_age_days INT NOT NULL DEFAULT 0,
+  updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,3 @@
+-- +migrate Down
+ALTER TABLE tbl_queue_automations
+  DROP COLUMN email;
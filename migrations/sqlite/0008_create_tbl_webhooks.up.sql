@@ -0,0 +1,32 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS tbl_webhooks (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  project VARCHAR(255) NOT NULL,
+  url VARCHAR(2048) NOT NULL,
+  events VARCHAR(255) NULL,
+  secret VARCHAR(255) NOT NULL,
+  active INTEGER NOT NULL DEFAULT 1,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_tbl_webhooks_project ON tbl_webhooks (project);
+
+CREATE TABLE IF NOT EXISTS tbl_webhook_deliveries (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  webhook_id INT NOT NULL,
+  event VARCHAR(64) NOT NULL,
+  reference_number VARCHAR(255) NOT NULL,
+  payload TEXT NOT NULL,
+  status VARCHAR(16) NOT NULL,
+  attempts INT NOT NULL DEFAULT 0,
+  response_code INT NOT NULL DEFAULT 0,
+  response_body TEXT NULL,
+  error TEXT NULL,
+  next_attempt_at DATETIME NULL,
+  delivered_at DATETIME NULL,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_tbl_webhook_deliveries_webhook_id ON tbl_webhook_deliveries (webhook_id);
+CREATE INDEX IF NOT EXISTS idx_tbl_webhook_deliveries_due ON tbl_webhook_deliveries (status, next_attempt_at);
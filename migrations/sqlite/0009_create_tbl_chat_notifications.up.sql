@@ -0,0 +1,13 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS tbl_chat_notifications (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  project VARCHAR(255) NOT NULL,
+  testsuite VARCHAR(255) NOT NULL DEFAULT '',
+  provider VARCHAR(16) NOT NULL,
+  webhook_url VARCHAR(2048) NOT NULL,
+  active INTEGER NOT NULL DEFAULT 1,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_tbl_chat_notifications_project ON tbl_chat_notifications (project);
@@ -0,0 +1,6 @@
+-- +migrate Down
+ALTER TABLE tbl_outbox_messages
+  DROP COLUMN priority;
+
+ALTER TABLE tbl_outbox_messages
+  DROP COLUMN project;
@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE tbl_queue_automations
+  ADD COLUMN priority VARCHAR(16) NOT NULL DEFAULT 'normal';
+
+CREATE INDEX IF NOT EXISTS idx_tbl_queue_automations_status_priority ON tbl_queue_automations (status, priority, created_at);
@@ -0,0 +1,17 @@
+-- +migrate Up
+CREATE TABLE IF NOT EXISTS tbl_runner_nodes (
+  id INTEGER PRIMARY KEY AUTOINCREMENT,
+  project VARCHAR(255) NOT NULL,
+  url VARCHAR(255) NOT NULL,
+  weight INT NOT NULL DEFAULT 1,
+  enabled INTEGER NOT NULL DEFAULT 1,
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tbl_runner_nodes_project_url ON tbl_runner_nodes (project, url);
+
+INSERT INTO tbl_runner_nodes (project, url)
+  SELECT name, url FROM tbl_projects;
+
+ALTER TABLE tbl_queue_automations
+  ADD COLUMN runner_url VARCHAR(255) NULL;
@@ -0,0 +1,12 @@
+-- +migrate Down
+ALTER TABLE tbl_queue_automations
+  DROP COLUMN platform_name;
+
+ALTER TABLE tbl_queue_automations
+  DROP COLUMN browser_version;
+
+ALTER TABLE tbl_queue_automations
+  DROP COLUMN browser_name;
+
+ALTER TABLE tbl_projects
+  DROP COLUMN grid_url;
@@ -0,0 +1,12 @@
+-- +migrate Up
+ALTER TABLE tbl_projects
+  ADD COLUMN grid_url VARCHAR(255) NULL;
+
+ALTER TABLE tbl_queue_automations
+  ADD COLUMN browser_name VARCHAR(64) NULL;
+
+ALTER TABLE tbl_queue_automations
+  ADD COLUMN browser_version VARCHAR(64) NULL;
+
+ALTER TABLE tbl_queue_automations
+  ADD COLUMN platform_name VARCHAR(64) NULL;